@@ -1,8 +1,20 @@
 package main
 
-import "cc/internal/app"
+import (
+	"cc/internal/app"
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+)
 
 func main() {
-	app.New().
-		Run()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err := app.New().
+		Run(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
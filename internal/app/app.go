@@ -6,15 +6,21 @@ import (
 	"cc/internal/storage"
 	"cc/internal/transport"
 	"cc/internal/transport/handler"
+	"cc/pkg/geo"
+	"cc/pkg/healthcheck"
+	"cc/pkg/jwks"
+	"cc/pkg/oidc"
 	"cc/pkg/postgres"
+	"cc/pkg/preview"
+	"cc/pkg/probe"
+	"cc/pkg/ratelimit"
+	"cc/pkg/screening"
 	"context"
-	"errors"
 	"github.com/go-redis/redis/v9"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"log"
 	"net/http"
-	"os/signal"
-	"syscall"
+	"time"
 )
 
 type App struct {
@@ -29,54 +35,251 @@ func New() *App {
 	return app
 }
 
-func (app *App) Run() {
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+// Run serves until ctx is done, then shuts the servers down gracefully and
+// closes the database and Redis clients.
+func (app *App) Run(ctx context.Context) error {
+	lifecycle := newLifecycle(app.config.Server.ShutdownTimeout)
 
-	pgClient, err := postgres.NewClient(ctx, postgres.Config{
-		Host: app.config.Postgres.Host, Port: app.config.Postgres.Port, DB: app.config.Postgres.DB,
-		User: app.config.Postgres.User, Password: app.config.Postgres.Password,
+	var pgClient postgres.Client
+	err := retry(ctx, app.config.Startup, "postgres", func(ctx context.Context) (err error) {
+		pgClient, err = postgres.NewClient(ctx, postgres.Config{
+			Host: app.config.Postgres.Host, Port: app.config.Postgres.Port, DB: app.config.Postgres.DB,
+			User: app.config.Postgres.User, Password: app.config.Postgres.Password,
+		})
+
+		return
 	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	lifecycle.onClose("postgres", func() error {
+		pgClient.Close()
+		return nil
+	})
 
+	// Redirects work without Redis, so the service starts degraded rather
+	// than not at all; the client keeps reconnecting in the background.
 	cache := redis.NewClient(&redis.Options{
 		Addr: app.config.Redis.Addr,
 	})
-	ping := cache.Ping(ctx)
-	if err = ping.Err(); err != nil {
-		log.Fatal(err)
+	err = retry(ctx, app.config.Startup, "redis", func(ctx context.Context) error {
+		return cache.Ping(ctx).Err()
+	})
+	if err != nil {
+		log.Printf("starting without redis: %v", err)
 	}
+	lifecycle.onClose("redis", cache.Close)
 
 	tagStorage := storage.NewTagStorage(pgClient)
 	tagService := service.NewTagService(tagStorage)
 
+	keyring, err := jwks.Load(
+		app.config.Auth.SigningMethod,
+		app.config.Auth.SigningKey,
+		app.config.Auth.PrivateKeyFile,
+		app.config.Auth.PublicKeyFiles,
+	)
+	if err != nil {
+		return err
+	}
+
+	auditStorage := storage.NewAuditStorage(pgClient)
+	auditService := service.NewAuditService(auditStorage)
+
 	authStorage := storage.NewAuthStorage(pgClient)
 	authService := service.NewAuthService(
 		authStorage,
 		app.config.Auth,
+		keyring,
+		auditService,
+	)
+
+	workspaceStorage := storage.NewWorkspaceStorage(pgClient)
+	workspaceService := service.NewWorkspaceService(
+		workspaceStorage,
+		auditService,
+		app.config.Auth.RequireTwoFactor,
 	)
 
 	statsStorage := storage.NewStatsStorage(pgClient)
-	statsService := service.NewStatsService(statsStorage)
+	revisionStorage := storage.NewRevisionStorage(pgClient)
+	statsService := service.NewStatsService(
+		statsStorage,
+		revisionStorage,
+		workspaceService,
+	)
+
+	notFoundService := service.NewNotFoundService(
+		statsStorage,
+		app.config.NotFound.Retention,
+	)
+	go notFoundService.Run(ctx, app.config.NotFound.FlushInterval)
+
+	var screeners []screening.Screener
+	if app.config.Screening.BlocklistFile != "" {
+		blocklist, err := screening.LoadBlocklist(app.config.Screening.BlocklistFile)
+		if err != nil {
+			return err
+		}
+
+		screeners = append(screeners, blocklist)
+	}
+	if app.config.Screening.SafeBrowsingKey != "" {
+		screeners = append(screeners, screening.NewSafeBrowsing(
+			app.config.Screening.SafeBrowsingURL,
+			app.config.Screening.SafeBrowsingKey,
+			"cc",
+			&http.Client{Timeout: 10 * time.Second},
+		))
+	}
+	screener := screening.Chain(screeners...)
 
 	shortenStorage := storage.NewShortenStorage(pgClient)
+
+	previewService := service.NewPreviewService(
+		shortenStorage,
+		workspaceService,
+		preview.New(healthcheck.NewClient(app.config.Preview.Timeout), preview.Config{
+			MaxBytes:  app.config.Preview.MaxBytes,
+			UserAgent: "cc-preview/1.0",
+		}),
+		app.config.Preview.Timeout,
+		app.config.Shorten.DomainURL,
+	)
+	previewService.Run(ctx, app.config.Preview.Workers)
+
+	redirectCache := service.NewRedirectCache(
+		shortenStorage,
+		cache,
+		app.config.Cache,
+	)
+	go redirectCache.Run(ctx)
+
 	shortenService := service.NewShortenService(
 		shortenStorage,
+		revisionStorage,
+		pgClient,
+		auditService,
+		workspaceService,
+		screener,
+		previewService,
+		redirectCache,
+		app.config.Shorten.DomainURL,
+	)
+
+	bioStorage := storage.NewBioStorage(pgClient)
+	bioService := service.NewBioService(
+		bioStorage,
+		shortenStorage,
+		workspaceService,
+		auditService,
 		app.config.Shorten.DomainURL,
 	)
 
+	screeningService := service.NewScreeningService(
+		shortenStorage,
+		revisionStorage,
+		pgClient,
+		screener,
+		redirectCache,
+	)
+	if len(screeners) > 0 {
+		go screeningService.Run(ctx, app.config.Screening.Interval)
+	}
+
+	healthService := service.NewHealthService(
+		shortenStorage,
+		healthcheck.New(healthcheck.NewClient(app.config.Health.Timeout), healthcheck.Config{
+			Concurrency: app.config.Health.Concurrency,
+			HostDelay:   app.config.Health.HostDelay,
+			UserAgent:   "cc-healthcheck/1.0",
+		}),
+		app.config.Health.FailureThreshold,
+	)
+	if app.config.Health.Interval > 0 {
+		go healthService.Run(ctx, app.config.Health.Interval)
+	}
+
+	purgeService := service.NewPurgeService(
+		shortenStorage,
+		app.config.Trash.PurgeAfter,
+	)
+	if app.config.Trash.PurgeAfter > 0 {
+		go purgeService.Run(ctx, app.config.Trash.PurgeInterval)
+	}
+
 	userStorage := storage.NewUserStorage(pgClient)
-	userService := service.NewUserService(userStorage)
+	userService := service.NewUserService(userStorage, authStorage, pgClient, auditService)
+
+	adminService := service.NewAdminService(
+		userStorage,
+		shortenStorage,
+		statsStorage,
+		revisionStorage,
+		pgClient,
+		auditService,
+		app.config.Shorten.DomainURL,
+	)
+	if err = adminService.PromoteAdmins(ctx, app.config.Auth.Admins); err != nil {
+		log.Println(err)
+	}
+
+	guardService := service.NewGuardService(
+		ratelimit.New(cache, "ratelimit:auth:"),
+		app.config.RateLimit,
+	)
+
+	var provider *oidc.Provider
+	if app.config.Auth.OIDC.Issuer != "" {
+		provider = oidc.NewProvider(oidc.Config{
+			Issuer:       app.config.Auth.OIDC.Issuer,
+			ClientID:     app.config.Auth.OIDC.ClientID,
+			ClientSecret: app.config.Auth.OIDC.ClientSecret,
+			RedirectURL:  app.config.Auth.OIDC.RedirectURL,
+			Scopes:       app.config.Auth.OIDC.Scopes,
+		}, &http.Client{Timeout: 10 * time.Second})
+	}
+
+	ssoService := service.NewSSOService(
+		userStorage,
+		provider,
+		cache,
+		app.config.Auth.OIDC,
+	)
+
+	twoFactorStorage := storage.NewTwoFactorStorage(pgClient)
+	twoFactorService := service.NewTwoFactorService(
+		twoFactorStorage,
+		userStorage,
+		cache,
+		app.config.Auth,
+	)
 
 	authHandler := handler.NewAuthHandler(
 		authService,
 		userService,
+		guardService,
+		ssoService,
+		twoFactorService,
+	)
+
+	twoFactorHandler := handler.NewTwoFactorHandler(
+		twoFactorService,
+	)
+
+	sessionHandler := handler.NewSessionHandler(
+		authService,
+	)
+
+	qrService := service.NewQRService(
+		shortenService,
+		healthcheck.NewClient(10*time.Second),
 	)
 
 	shortenHandler := handler.NewShortenHandler(
 		shortenService,
+		previewService,
+		qrService,
 		authService,
 		tagService,
 		statsService,
@@ -84,41 +287,104 @@ func (app *App) Run() {
 
 	userHandler := handler.NewUserHandler(
 		userService,
-		authService,
 		shortenService,
 		tagService,
+		workspaceService,
+		redirectCache,
+	)
+
+	workspaceHandler := handler.NewWorkspaceHandler(
+		workspaceService,
+		shortenService,
+		redirectCache,
+	)
+
+	adminHandler := handler.NewAdminHandler(
+		adminService,
+		authService,
+		auditService,
+		redirectCache,
+	)
+
+	bioHandler := handler.NewBioHandler(
+		bioService,
+	)
+
+	wellKnownHandler := handler.NewWellKnownHandler(
+		authService,
+	)
+
+	resolver := geo.Resolver{Header: app.config.Geo.CountryHeader}
+	if app.config.Geo.Database != "" {
+		maxMind, err := geo.OpenMaxMind(app.config.Geo.Database)
+		if err != nil {
+			return err
+		}
+		lifecycle.onClose("geo", maxMind.Close)
+
+		resolver.Locator = maxMind
+	}
+
+	probeHandler := handler.NewProbeHandler(
+		2*time.Second,
+		probe.Check{Name: "postgres", Required: true, Ping: pgClient.Ping, Fallback: redirectCache.Warm},
+		probe.Check{Name: "redis", Ping: func(ctx context.Context) error {
+			return cache.Ping(ctx).Err()
+		}},
 	)
 
 	redirectHandler := handler.NewRedirectHandler(
 		shortenService,
 		statsService,
-		cache,
+		notFoundService,
+		bioService,
+		resolver,
+		app.config.DeepLink,
+		app.config.Shorten.DomainURL,
 		app.config.Shorten.DefaultURL,
+		app.config.Shorten.RedirectNotFound,
 	)
 
-	go func() {
-		http.Handle("/metrics", promhttp.Handler())
+	metrics := http.NewServeMux()
+	metrics.Handle("/metrics", promhttp.Handler())
 
-		err = http.ListenAndServe(app.config.Prometheus.Addr, nil)
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatal(err)
-		}
-	}()
-
-	go func() {
-		err = transport.New().
-			Handle(
-				shortenHandler,
-				userHandler,
-				authHandler,
-				redirectHandler,
-				authService,
-			).
-			Run(app.config.Server.Addr)
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatal(err)
+	router := transport.New().
+		Handle(
+			shortenHandler,
+			userHandler,
+			authHandler,
+			sessionHandler,
+			workspaceHandler,
+			twoFactorHandler,
+			adminHandler,
+			bioHandler,
+			redirectHandler,
+			wellKnownHandler,
+			probeHandler,
+			authService,
+			adminService,
+		)
+
+	for _, server := range []*http.Server{
+		{
+			Addr:         app.config.Prometheus.Addr,
+			Handler:      metrics,
+			ReadTimeout:  app.config.Server.ReadTimeout,
+			WriteTimeout: app.config.Server.WriteTimeout,
+			IdleTimeout:  app.config.Server.IdleTimeout,
+		},
+		{
+			Addr:         app.config.Server.Addr,
+			Handler:      router.Handler(),
+			ReadTimeout:  app.config.Server.ReadTimeout,
+			WriteTimeout: app.config.Server.WriteTimeout,
+			IdleTimeout:  app.config.Server.IdleTimeout,
+		},
+	} {
+		if _, err = lifecycle.listen(server); err != nil {
+			return err
 		}
-	}()
+	}
 
-	<-ctx.Done()
+	return lifecycle.run(ctx)
 }
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// lifecycle serves HTTP until its context is done or a server fails, then
+// drains the servers and closes what they depend on.
+type lifecycle struct {
+	shutdownTimeout time.Duration
+	servers         []*http.Server
+	listeners       []net.Listener
+	closers         []closer
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+func newLifecycle(shutdownTimeout time.Duration) *lifecycle {
+	return &lifecycle{shutdownTimeout: shutdownTimeout}
+}
+
+// listen binds server.Addr right away, so a taken port fails startup instead
+// of surfacing later from a goroutine.
+func (lifecycle *lifecycle) listen(server *http.Server) (net.Addr, error) {
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycle.servers = append(lifecycle.servers, server)
+	lifecycle.listeners = append(lifecycle.listeners, listener)
+
+	return listener.Addr(), nil
+}
+
+// onClose registers a dependency to close after the servers have stopped.
+// Dependencies are closed in reverse order of registration, like defers.
+func (lifecycle *lifecycle) onClose(name string, close func() error) {
+	lifecycle.closers = append(lifecycle.closers, closer{name: name, close: close})
+}
+
+func (lifecycle *lifecycle) run(ctx context.Context) (err error) {
+	failed := make(chan error, len(lifecycle.servers))
+	for i, server := range lifecycle.servers {
+		go func(server *http.Server, listener net.Listener) {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				failed <- err
+			}
+		}(server, lifecycle.listeners[i])
+	}
+
+	select {
+	case <-ctx.Done():
+	case err = <-failed:
+		log.Println(err)
+	}
+
+	// The deadline must not derive from ctx, which is already done.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), lifecycle.shutdownTimeout)
+	defer cancel()
+
+	for _, server := range lifecycle.servers {
+		if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+			log.Printf("shutdown %s: %v", server.Addr, shutdownErr)
+			_ = server.Close()
+
+			if err == nil {
+				err = shutdownErr
+			}
+		}
+	}
+
+	for i := len(lifecycle.closers) - 1; i >= 0; i-- {
+		if closeErr := lifecycle.closers[i].close(); closeErr != nil {
+			log.Printf("close %s: %v", lifecycle.closers[i].name, closeErr)
+		}
+	}
+
+	return err
+}
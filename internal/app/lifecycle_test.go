@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLifecycle_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	server := &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			_, _ = w.Write([]byte("done"))
+		}),
+	}
+
+	var closed []string
+
+	lifecycle := newLifecycle(time.Second)
+	addr, err := lifecycle.listen(server)
+	assert.NoError(t, err)
+	lifecycle.onClose("postgres", func() error {
+		closed = append(closed, "postgres")
+		return nil
+	})
+	lifecycle.onClose("redis", func() error {
+		closed = append(closed, "redis")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- lifecycle.run(ctx)
+	}()
+
+	response := make(chan string)
+	go func() {
+		res, err := http.Get("http://" + addr.String())
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+
+		body, _ := io.ReadAll(res.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-stopped:
+		t.Fatal("stopped before the in-flight request finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(t, "done", <-response)
+	assert.NoError(t, <-stopped)
+	assert.Equal(t, []string{"redis", "postgres"}, closed)
+}
+
+func TestLifecycle_ShutdownDeadline(t *testing.T) {
+	started := make(chan struct{})
+
+	server := &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+		}),
+	}
+
+	lifecycle := newLifecycle(20 * time.Millisecond)
+	addr, err := lifecycle.listen(server)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- lifecycle.run(ctx)
+	}()
+
+	go func() {
+		res, err := http.Get("http://" + addr.String())
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err = <-stopped:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("shutdown ignored its deadline")
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"cc/internal/config"
+	"context"
+	"log"
+	"time"
+)
+
+// retry calls fn until it succeeds, doubling the wait between attempts up to
+// MaxBackoff. It gives up after Attempts tries or once ctx is done and returns
+// the last error.
+func retry(ctx context.Context, startup config.Startup, name string, fn func(ctx context.Context) error) (err error) {
+	backoff := startup.Backoff
+
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		if attempt >= startup.Attempts {
+			return err
+		}
+
+		log.Printf("%s is unavailable, retrying in %s: %v", name, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > startup.MaxBackoff {
+			backoff = startup.MaxBackoff
+		}
+	}
+}
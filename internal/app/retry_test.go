@@ -0,0 +1,48 @@
+package app
+
+import (
+	"cc/internal/config"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	startup := config.Startup{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	unavailable := errors.New("unavailable")
+
+	var calls int
+	err := retry(context.Background(), startup, "test", func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return unavailable
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = retry(context.Background(), startup, "test", func(context.Context) error {
+		calls++
+		return unavailable
+	})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int
+	err := retry(ctx, config.Startup{Attempts: 10, Backoff: time.Hour, MaxBackoff: time.Hour}, "test", func(context.Context) error {
+		calls++
+		return errors.New("unavailable")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -12,11 +12,25 @@ type Config struct {
 	Postgres   Postgres
 	Auth       Auth
 	Redis      Redis
+	RateLimit  RateLimit
 	Shorten    Shorten
+	Screening  Screening
+	Health     Health
+	Preview    Preview
+	Geo        Geo
+	DeepLink   DeepLink
+	Trash      Trash
+	NotFound   NotFound
+	Cache      Cache
+	Startup    Startup
 }
 
 type Server struct {
-	Addr string `env:"SERVER_ADDR"`
+	Addr            string        `env:"SERVER_ADDR"`
+	ReadTimeout     time.Duration `env:"SERVER_READ_TIMEOUT" env-default:"10s"`
+	WriteTimeout    time.Duration `env:"SERVER_WRITE_TIMEOUT" env-default:"30s"`
+	IdleTimeout     time.Duration `env:"SERVER_IDLE_TIMEOUT" env-default:"120s"`
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"15s"`
 }
 
 type Prometheus struct {
@@ -32,17 +46,112 @@ type Postgres struct {
 }
 
 type Auth struct {
-	ExpirationAt time.Duration `env:"AUTH_EXPIRATION_AT"`
-	SigningKey   string        `env:"AUTH_SIGNING_KEY" env-required:"true"`
+	ExpirationAt        time.Duration `env:"AUTH_EXPIRATION_AT"`
+	RefreshExpirationAt time.Duration `env:"AUTH_REFRESH_EXPIRATION_AT" env-default:"720h"`
+	SigningMethod       string        `env:"AUTH_SIGNING_METHOD" env-default:"HS256"`
+	SigningKey          string        `env:"AUTH_SIGNING_KEY"`
+	PrivateKeyFile      string        `env:"AUTH_PRIVATE_KEY_FILE"`
+	PublicKeyFiles      []string      `env:"AUTH_PUBLIC_KEY_FILES" env-separator:","`
+
+	// SessionCacheTTL is how long a session found active is trusted before
+	// it is looked up again, and so how late revocations and bans can take
+	// effect for access tokens.
+	SessionCacheTTL time.Duration `env:"AUTH_SESSION_CACHE_TTL" env-default:"10s"`
+
+	TwoFactorIssuer       string        `env:"AUTH_TWO_FACTOR_ISSUER" env-default:"cc"`
+	RequireTwoFactor      bool          `env:"AUTH_REQUIRE_TWO_FACTOR" env-default:"false"`
+	ChallengeExpirationAt time.Duration `env:"AUTH_CHALLENGE_EXPIRATION_AT" env-default:"5m"`
+
+	Admins []string `env:"AUTH_ADMINS" env-separator:","`
+
+	OIDC OIDC
+}
+
+type OIDC struct {
+	Issuer        string   `env:"AUTH_OIDC_ISSUER"`
+	ClientID      string   `env:"AUTH_OIDC_CLIENT_ID"`
+	ClientSecret  string   `env:"AUTH_OIDC_CLIENT_SECRET"`
+	RedirectURL   string   `env:"AUTH_OIDC_REDIRECT_URL"`
+	Scopes        []string `env:"AUTH_OIDC_SCOPES" env-separator:"," env-default:"openid,profile,email"`
+	AutoProvision bool     `env:"AUTH_OIDC_AUTO_PROVISION" env-default:"true"`
 }
 
 type Redis struct {
 	Addr string `env:"REDIS_ADDR"`
 }
 
+type RateLimit struct {
+	IPAttempts      int64         `env:"RATE_LIMIT_IP_ATTEMPTS" env-default:"20"`
+	IPWindow        time.Duration `env:"RATE_LIMIT_IP_WINDOW" env-default:"1m"`
+	AccountAttempts int64         `env:"RATE_LIMIT_ACCOUNT_ATTEMPTS" env-default:"5"`
+	AccountWindow   time.Duration `env:"RATE_LIMIT_ACCOUNT_WINDOW" env-default:"15m"`
+	Lockout         time.Duration `env:"RATE_LIMIT_LOCKOUT" env-default:"1m"`
+	MaxLockout      time.Duration `env:"RATE_LIMIT_MAX_LOCKOUT" env-default:"1h"`
+}
+
 type Shorten struct {
 	DomainURL  string `env:"SHORTEN_DOMAIN_URL"`
 	DefaultURL string `env:"SHORTEN_DEFAULT_URL"`
+	// RedirectNotFound sends unknown keys to DefaultURL instead of showing
+	// the not found page.
+	RedirectNotFound bool `env:"SHORTEN_REDIRECT_NOT_FOUND" env-default:"false"`
+}
+
+type Screening struct {
+	BlocklistFile   string        `env:"SCREENING_BLOCKLIST_FILE"`
+	SafeBrowsingKey string        `env:"SCREENING_SAFE_BROWSING_KEY"`
+	SafeBrowsingURL string        `env:"SCREENING_SAFE_BROWSING_URL" env-default:"https://safebrowsing.googleapis.com"`
+	Interval        time.Duration `env:"SCREENING_INTERVAL" env-default:"24h"`
+}
+
+type Health struct {
+	Interval         time.Duration `env:"HEALTH_CHECK_INTERVAL" env-default:"6h"`
+	Concurrency      int           `env:"HEALTH_CHECK_CONCURRENCY" env-default:"16"`
+	HostDelay        time.Duration `env:"HEALTH_CHECK_HOST_DELAY" env-default:"1s"`
+	Timeout          time.Duration `env:"HEALTH_CHECK_TIMEOUT" env-default:"10s"`
+	FailureThreshold int           `env:"HEALTH_CHECK_FAILURE_THRESHOLD" env-default:"2"`
+}
+
+type Preview struct {
+	Timeout  time.Duration `env:"PREVIEW_TIMEOUT" env-default:"5s"`
+	MaxBytes int64         `env:"PREVIEW_MAX_BYTES" env-default:"524288"`
+	Workers  int           `env:"PREVIEW_WORKERS" env-default:"4"`
+}
+
+type Trash struct {
+	PurgeAfter    time.Duration `env:"TRASH_PURGE_AFTER" env-default:"720h"`
+	PurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+}
+
+type NotFound struct {
+	FlushInterval time.Duration `env:"NOT_FOUND_FLUSH_INTERVAL" env-default:"1m"`
+	Retention     time.Duration `env:"NOT_FOUND_RETENTION" env-default:"720h"`
+}
+
+type Startup struct {
+	Attempts   int           `env:"STARTUP_ATTEMPTS" env-default:"10"`
+	Backoff    time.Duration `env:"STARTUP_BACKOFF" env-default:"500ms"`
+	MaxBackoff time.Duration `env:"STARTUP_MAX_BACKOFF" env-default:"10s"`
+}
+
+type Cache struct {
+	TTL         time.Duration `env:"CACHE_TTL" env-default:"1h"`
+	NegativeTTL time.Duration `env:"CACHE_NEGATIVE_TTL" env-default:"1m"`
+	LocalSize   int           `env:"CACHE_LOCAL_SIZE" env-default:"10000"`
+	LocalTTL    time.Duration `env:"CACHE_LOCAL_TTL" env-default:"10s"`
+}
+
+type Geo struct {
+	CountryHeader string `env:"GEO_COUNTRY_HEADER"`
+	Database      string `env:"GEO_DATABASE"`
+}
+
+type DeepLink struct {
+	AppleAppID          string   `env:"DEEPLINK_APPLE_APP_ID"`
+	AppleStoreURL       string   `env:"DEEPLINK_APPLE_STORE_URL"`
+	AndroidPackage      string   `env:"DEEPLINK_ANDROID_PACKAGE"`
+	AndroidFingerprints []string `env:"DEEPLINK_ANDROID_FINGERPRINTS" env-separator:","`
+	AndroidStoreURL     string   `env:"DEEPLINK_ANDROID_STORE_URL"`
 }
 
 func New() Config {
@@ -0,0 +1,10 @@
+package domain
+
+type Totals struct {
+	Users            int64 `json:"users"`
+	BannedUsers      int64 `json:"banned_users"`
+	Shortens         int64 `json:"shortens"`
+	DisabledShortens int64 `json:"disabled_shortens"`
+	Clicks           int64 `json:"clicks"`
+	ClicksLastDay    int64 `json:"clicks_last_day"`
+}
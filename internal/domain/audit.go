@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	AuditShortenCreate   = "shorten.create"
+	AuditShortenUpdate   = "shorten.update"
+	AuditShortenDelete   = "shorten.delete"
+	AuditShortenRestore  = "shorten.restore"
+	AuditShortenRollback = "shorten.rollback"
+	AuditShortenRules    = "shorten.rules"
+	AuditShortenVariants = "shorten.variants"
+
+	AuditSignUp           = "user.sign_up"
+	AuditUserRename       = "user.rename"
+	AuditUserPassword     = "user.password"
+	AuditUserDelete       = "user.delete"
+	AuditSignIn           = "session.create"
+	AuditSessionRefresh   = "session.refresh"
+	AuditSessionRevoke    = "session.revoke"
+	AuditSessionRevokeAll = "session.revoke_all"
+
+	AuditWorkspaceCreate  = "workspace.create"
+	AuditWorkspaceUpdate  = "workspace.update"
+	AuditWorkspaceDelete  = "workspace.delete"
+	AuditMemberUpdate     = "workspace.member.update"
+	AuditMemberRemove     = "workspace.member.remove"
+	AuditInvitationCreate = "workspace.invitation.create"
+	AuditInvitationDelete = "workspace.invitation.delete"
+	AuditInvitationAccept = "workspace.invitation.accept"
+
+	AuditBioSave   = "bio.save"
+	AuditBioDelete = "bio.delete"
+
+	AuditAdminDisableShorten = "admin.shorten.disable"
+	AuditAdminEnableShorten  = "admin.shorten.enable"
+	AuditAdminBanUser        = "admin.user.ban"
+	AuditAdminUnbanUser      = "admin.user.unban"
+	AuditAdminSetUserRole    = "admin.user.role"
+	AuditAdminPromote        = "admin.user.promote"
+)
+
+const (
+	TargetShorten   = "shorten"
+	TargetUser      = "user"
+	TargetSession   = "session"
+	TargetWorkspace = "workspace"
+	TargetBioPage   = "bio_page"
+)
+
+// AuditRecord is a mutating call: who made it, from where, what it changed
+// and how the target looked before and after.
+type AuditRecord struct {
+	ID         int64           `json:"id"`
+	ActorID    *uuid.UUID      `json:"actor_id"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -12,6 +12,22 @@ type Session struct {
 }
 
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	SessionID uuid.UUID `json:"session_id"`
 	jwt.RegisteredClaims
 }
+
+type Device struct {
+	ID        uuid.UUID `json:"id"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Platform  string    `json:"platform"`
+	OS        string    `json:"os"`
+	Browser   string    `json:"browser"`
+	Current   bool      `json:"current"`
+	CreatedAt int64     `json:"created_at"`
+	UpdatedAt int64     `json:"updated_at"`
+	ExpiresAt int64     `json:"expires_at"`
+}
+
+type Devices []Device
@@ -0,0 +1,42 @@
+package domain
+
+import "github.com/google/uuid"
+
+const (
+	ThemeLight Theme = "light"
+	ThemeDark  Theme = "dark"
+)
+
+type Theme string
+
+func (theme Theme) Valid() bool {
+	return theme == ThemeLight || theme == ThemeDark
+}
+
+// BioPage is a user's public page at /@name listing some of their links.
+type BioPage struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Title     string    `json:"title"`
+	AvatarURL string    `json:"avatar_url,omitempty"`
+	Theme     Theme     `json:"theme"`
+	Links     []BioLink `json:"links"`
+	CreatedAt int64     `json:"created_at"`
+	UpdatedAt int64     `json:"updated_at"`
+}
+
+// BioLink is a shorten as listed on a bio page. URL goes through the page so
+// that clicks are attributed to it.
+type BioLink struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// Active is false for links that are disabled, quarantined or expired;
+	// they are kept on the page but not shown to visitors.
+	Active bool `json:"active"`
+}
+
+type BioLinkStats struct {
+	Key    string `json:"key"`
+	Clicks int64  `json:"clicks"`
+}
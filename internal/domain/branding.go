@@ -0,0 +1,10 @@
+package domain
+
+// Branding customizes the pages visitors of a workspace's links see when a
+// link cannot be followed.
+type Branding struct {
+	Name    string `json:"name,omitempty"`
+	LogoURL string `json:"logo_url,omitempty"`
+	Color   string `json:"color,omitempty"`
+	HomeURL string `json:"home_url,omitempty"`
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DeepLinkFallback is where visitors go when the app does not open.
+type DeepLinkFallback string
+
+const (
+	FallbackWeb   DeepLinkFallback = "web"
+	FallbackStore DeepLinkFallback = "store"
+)
+
+func (fallback DeepLinkFallback) Valid() bool {
+	return fallback == FallbackWeb || fallback == FallbackStore
+}
+
+// Open is how a deep-linked shorten was opened, recorded with the click.
+const (
+	OpenApp      = "app"
+	OpenFallback = "fallback"
+)
+
+// DeepLink holds the app URIs a shorten opens on mobile, like
+// myapp://product/42, instead of its web destination.
+type DeepLink struct {
+	IOS      string           `json:"ios,omitempty"`
+	Android  string           `json:"android,omitempty"`
+	Fallback DeepLinkFallback `json:"fallback,omitempty"`
+}
+
+func (deepLink DeepLink) Empty() bool {
+	return deepLink.IOS == "" && deepLink.Android == ""
+}
+
+// Target returns the app URI for the visitor's OS, if there is one.
+func (deepLink DeepLink) Target(os string) string {
+	switch os {
+	case "iOS":
+		return deepLink.IOS
+	case "Android":
+		return deepLink.Android
+	default:
+		return ""
+	}
+}
+
+// Intent turns an Android app URI into an intent URL, which Chrome follows to
+// the app and otherwise to fallbackURL without leaving the page stuck.
+func Intent(uri, androidPackage, fallbackURL string) string {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return ""
+	}
+
+	intent := "intent://" + rest + "#Intent;scheme=" + scheme + ";"
+	if androidPackage != "" {
+		intent += "package=" + androidPackage + ";"
+	}
+	if fallbackURL != "" {
+		intent += "S.browser_fallback_url=" + url.QueryEscape(fallbackURL) + ";"
+	}
+
+	return intent + "end"
+}
@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"cc/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeepLink_Target(t *testing.T) {
+	deepLink := domain.DeepLink{IOS: "shop://item/1", Android: "shop://item/2"}
+
+	assert.Equal(t, "shop://item/1", deepLink.Target("iOS"))
+	assert.Equal(t, "shop://item/2", deepLink.Target("Android"))
+	assert.Empty(t, deepLink.Target("Windows"))
+	assert.False(t, deepLink.Empty())
+	assert.True(t, domain.DeepLink{}.Empty())
+}
+
+func TestIntent(t *testing.T) {
+	tests := []struct {
+		uri, pkg, fallback string
+		want               string
+	}{
+		{"shop://item/2?ref=cc", "com.shop", "https://cc.io/abc?dl=fallback", "intent://item/2?ref=cc#Intent;scheme=shop;package=com.shop;S.browser_fallback_url=https%3A%2F%2Fcc.io%2Fabc%3Fdl%3Dfallback;end"},
+		{"shop://item/2", "", "", "intent://item/2#Intent;scheme=shop;end"},
+		{"item/2", "com.shop", "", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, domain.Intent(tt.uri, tt.pkg, tt.fallback), tt.uri)
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+type HealthStatus string
+
+const (
+	HealthUnknown HealthStatus = "unknown"
+	HealthHealthy HealthStatus = "healthy"
+	HealthBroken  HealthStatus = "broken"
+)
+
+func (status HealthStatus) Valid() bool {
+	return status == HealthUnknown || status == HealthHealthy || status == HealthBroken
+}
+
+// Health is the outcome of the last destination check.
+type Health struct {
+	Status     HealthStatus `json:"status"`
+	StatusCode int          `json:"status_code,omitempty"`
+	LatencyMS  int64        `json:"latency_ms,omitempty"`
+	FinalURL   string       `json:"final_url,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	CheckedAt  int64        `json:"checked_at,omitempty"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+// OpenGraph is the card shown when a short link is shared. Empty fields are
+// left out of the card.
+type OpenGraph struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
+
+func (openGraph OpenGraph) Empty() bool {
+	return openGraph == OpenGraph{}
+}
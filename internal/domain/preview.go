@@ -0,0 +1,12 @@
+package domain
+
+// Preview is the metadata fetched from the destination page.
+type Preview struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	OGTitle     string `json:"og_title,omitempty"`
+	OGImage     string `json:"og_image,omitempty"`
+	Favicon     string `json:"favicon,omitempty"`
+	Error       string `json:"error,omitempty"`
+	FetchedAt   int64  `json:"fetched_at,omitempty"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "net/http"
+
+// RedirectType is how visitors of a shorten are sent to its destination:
+// an HTTP redirect with the given status code, the destination shown in a
+// frame under the short link, or a page that fires the tracking pixel and
+// then navigates.
+type RedirectType string
+
+const (
+	RedirectMovedPermanently RedirectType = "301"
+	RedirectFound            RedirectType = "302"
+	RedirectTemporary        RedirectType = "307"
+	RedirectPermanent        RedirectType = "308"
+	RedirectCloaked          RedirectType = "cloaked"
+	RedirectRefresh          RedirectType = "refresh"
+)
+
+func (redirectType RedirectType) Valid() bool {
+	switch redirectType {
+	case RedirectMovedPermanently, RedirectFound, RedirectTemporary, RedirectPermanent, RedirectCloaked, RedirectRefresh:
+		return true
+	}
+
+	return false
+}
+
+// Permanent reports whether browsers may cache the redirect and skip the
+// short link on later visits.
+func (redirectType RedirectType) Permanent() bool {
+	return redirectType == RedirectMovedPermanently || redirectType == RedirectPermanent
+}
+
+// Status is the HTTP status code of a redirect type, or 0 for the ones served
+// as a page. Unknown types, such as those missing from old cache entries,
+// fall back to the default.
+func (redirectType RedirectType) Status() int {
+	switch redirectType {
+	case RedirectMovedPermanently:
+		return http.StatusMovedPermanently
+	case RedirectTemporary:
+		return http.StatusTemporaryRedirect
+	case RedirectPermanent:
+		return http.StatusPermanentRedirect
+	case RedirectCloaked, RedirectRefresh:
+		return 0
+	}
+
+	return http.StatusFound
+}
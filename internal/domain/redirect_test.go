@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"cc/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestRedirectType_Status(t *testing.T) {
+	tests := []struct {
+		redirectType domain.RedirectType
+		valid        bool
+		status       int
+	}{
+		{domain.RedirectMovedPermanently, true, http.StatusMovedPermanently},
+		{domain.RedirectFound, true, http.StatusFound},
+		{domain.RedirectTemporary, true, http.StatusTemporaryRedirect},
+		{domain.RedirectPermanent, true, http.StatusPermanentRedirect},
+		{domain.RedirectCloaked, true, 0},
+		{domain.RedirectRefresh, true, 0},
+		{"", false, http.StatusFound},
+		{"303", false, http.StatusFound},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.redirectType), func(t *testing.T) {
+			assert.Equal(t, test.valid, test.redirectType.Valid())
+			assert.Equal(t, test.status, test.redirectType.Status())
+		})
+	}
+}
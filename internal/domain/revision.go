@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"reflect"
+	"strings"
+)
+
+type RevisionAction string
+
+const (
+	RevisionCreate   RevisionAction = "create"
+	RevisionUpdate   RevisionAction = "update"
+	RevisionRules    RevisionAction = "rules"
+	RevisionVariants RevisionAction = "variants"
+	RevisionRollback RevisionAction = "rollback"
+
+	// Lifecycle revisions do not change the state, only whether the shorten
+	// redirects. Their changes say how.
+	RevisionDelete     RevisionAction = "delete"
+	RevisionRestore    RevisionAction = "restore"
+	RevisionDisable    RevisionAction = "disable"
+	RevisionEnable     RevisionAction = "enable"
+	RevisionQuarantine RevisionAction = "quarantine"
+	RevisionRelease    RevisionAction = "release"
+)
+
+// ShortenState is the part of a shorten its owners edit, kept with every
+// revision so that any of them can be restored.
+type ShortenState struct {
+	URL          string       `json:"url"`
+	Title        string       `json:"title"`
+	Tags         []string     `json:"tags"`
+	OpenGraph    OpenGraph    `json:"open_graph"`
+	RedirectType RedirectType `json:"redirect_type"`
+	PixelURL     string       `json:"pixel_url"`
+	DeepLink     DeepLink     `json:"deep_link"`
+	ExpiresAt    *int64       `json:"expires_at"`
+	Rules        Rules        `json:"rules"`
+	Variants     Variants     `json:"variants"`
+}
+
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Changes are keyed by the JSON name of the changed field.
+type Changes map[string]Change
+
+// Diff lists the fields that differ in next. Empty and missing values are
+// the same.
+func (state ShortenState) Diff(next ShortenState) Changes {
+	changes := Changes{}
+
+	from, to := reflect.ValueOf(state), reflect.ValueOf(next)
+	for i := 0; i < from.NumField(); i++ {
+		a, b := from.Field(i), to.Field(i)
+		if empty(a) && empty(b) || reflect.DeepEqual(a.Interface(), b.Interface()) {
+			continue
+		}
+
+		name, _, _ := strings.Cut(from.Type().Field(i).Tag.Get("json"), ",")
+		changes[name] = Change{From: a.Interface(), To: b.Interface()}
+	}
+
+	return changes
+}
+
+func empty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+type Revision struct {
+	ID        int64          `json:"id"`
+	UserID    *uuid.UUID     `json:"user_id"`
+	Action    RevisionAction `json:"action"`
+	Changes   Changes        `json:"changes"`
+	State     ShortenState   `json:"state"`
+	CreatedAt int64          `json:"created_at"`
+}
+
+type Revisions []Revision
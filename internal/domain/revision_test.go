@@ -0,0 +1,28 @@
+package domain_test
+
+import (
+	"cc/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestShortenState_Diff(t *testing.T) {
+	before := domain.ShortenState{
+		URL:   "https://example.com/old",
+		Title: "Old",
+		Tags:  []string{},
+	}
+	after := domain.ShortenState{
+		URL:      "https://example.com/new",
+		Title:    "Old",
+		DeepLink: domain.DeepLink{IOS: "shop://item/1"},
+	}
+
+	changes := before.Diff(after)
+
+	assert.Equal(t, domain.Changes{
+		"url":       {From: "https://example.com/old", To: "https://example.com/new"},
+		"deep_link": {From: domain.DeepLink{}, To: domain.DeepLink{IOS: "shop://item/1"}},
+	}, changes)
+	assert.Empty(t, after.Diff(after))
+}
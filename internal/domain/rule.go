@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Visitor is what redirect rules are matched against.
+type Visitor struct {
+	OS       string
+	Platform string
+	Country  string
+	Bot      bool
+	Time     time.Time
+}
+
+// Rule sends visitors matching every non-empty condition to URL instead of the
+// shorten's own destination.
+type Rule struct {
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	OS        []string `json:"os,omitempty"`
+	Platforms []string `json:"platforms,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+	StartsAt  int64    `json:"starts_at,omitempty"`
+	EndsAt    int64    `json:"ends_at,omitempty"`
+}
+
+type Rules []Rule
+
+func (rule Rule) Matches(visitor Visitor) bool {
+	if len(rule.OS) > 0 && !contains(rule.OS, visitor.OS) {
+		return false
+	}
+
+	if len(rule.Platforms) > 0 && !contains(rule.Platforms, visitor.Platform) {
+		return false
+	}
+
+	if len(rule.Countries) > 0 && !contains(rule.Countries, visitor.Country) {
+		return false
+	}
+
+	if rule.StartsAt != 0 && visitor.Time.Unix() < rule.StartsAt {
+		return false
+	}
+
+	if rule.EndsAt != 0 && visitor.Time.Unix() >= rule.EndsAt {
+		return false
+	}
+
+	return true
+}
+
+// Match returns the first rule matching the visitor.
+func (rules Rules) Match(visitor Visitor) (Rule, bool) {
+	for _, rule := range rules {
+		if rule.Matches(visitor) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+// NeedsCountry reports whether any rule depends on the visitor's country, so
+// the lookup can be skipped otherwise.
+func (rules Rules) NeedsCountry() bool {
+	for _, rule := range rules {
+		if len(rule.Countries) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
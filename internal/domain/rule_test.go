@@ -0,0 +1,43 @@
+package domain_test
+
+import (
+	"cc/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRules_Match(t *testing.T) {
+	promoStart := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	rules := domain.Rules{
+		{Name: "promo", URL: "https://example.com/sale", StartsAt: promoStart.Unix(), EndsAt: promoStart.Add(24 * time.Hour).Unix()},
+		{Name: "ios", URL: "https://apps.apple.com/app/id1", OS: []string{"iOS"}},
+		{Name: "android", URL: "https://play.google.com/store/apps/details?id=app", OS: []string{"Android"}},
+		{Name: "germany", URL: "https://example.com/de", Countries: []string{"DE", "AT"}, Platforms: []string{"Desktop"}},
+	}
+
+	tests := []struct {
+		name    string
+		visitor domain.Visitor
+		want    string
+	}{
+		{"ios", domain.Visitor{OS: "iOS", Platform: "Mobile", Time: promoStart.Add(-time.Hour)}, "ios"},
+		{"promo wins by order", domain.Visitor{OS: "iOS", Time: promoStart.Add(time.Hour)}, "promo"},
+		{"promo ended", domain.Visitor{OS: "Android", Time: promoStart.Add(24 * time.Hour)}, "android"},
+		{"country", domain.Visitor{OS: "Windows", Platform: "Desktop", Country: "at"}, "germany"},
+		{"country wrong platform", domain.Visitor{OS: "Windows", Platform: "Tablet", Country: "DE"}, ""},
+		{"no match", domain.Visitor{OS: "Linux", Platform: "Desktop", Country: "US"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, ok := rules.Match(test.visitor)
+			assert.Equal(t, test.want != "", ok)
+			assert.Equal(t, test.want, rule.Name)
+		})
+	}
+
+	assert.True(t, rules.NeedsCountry())
+	assert.False(t, rules[:3].NeedsCountry())
+}
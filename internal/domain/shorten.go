@@ -1,13 +1,65 @@
 package domain
 
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
 type Shorten struct {
-	ID        string   `json:"id"`
-	Title     string   `json:"title"`
-	LongURL   string   `json:"long_url"`
-	ShortURL  string   `json:"short_url"`
-	Tags      []string `json:"tags"`
-	CreatedAt int64    `json:"created_at"`
-	UpdatedAt int64    `json:"updated_at"`
+	ID               string       `json:"id"`
+	WorkspaceID      uuid.UUID    `json:"workspace_id"`
+	Title            string       `json:"title"`
+	LongURL          string       `json:"long_url"`
+	ShortURL         string       `json:"short_url"`
+	Tags             []string     `json:"tags"`
+	Rules            Rules        `json:"rules"`
+	Variants         Variants     `json:"variants"`
+	RedirectType     RedirectType `json:"redirect_type"`
+	PixelURL         string       `json:"pixel_url,omitempty"`
+	DeepLink         DeepLink     `json:"deep_link"`
+	ExpiresAt        *int64       `json:"expires_at,omitempty"`
+	DeletedAt        *int64       `json:"deleted_at,omitempty"`
+	Disabled         bool         `json:"disabled"`
+	DisabledReason   string       `json:"disabled_reason,omitempty"`
+	Quarantined      bool         `json:"quarantined"`
+	QuarantineReason string       `json:"quarantine_reason,omitempty"`
+	Health           Health       `json:"health"`
+	Preview          Preview      `json:"preview"`
+	OpenGraph        OpenGraph    `json:"open_graph"`
+	CreatedAt        int64        `json:"created_at"`
+	UpdatedAt        int64        `json:"updated_at"`
 }
 
 type Shortens []Shorten
+
+// Redirect is what the redirect handler caches per key.
+type Redirect struct {
+	URL              string       `json:"url"`
+	Title            string       `json:"title,omitempty"`
+	Type             RedirectType `json:"type,omitempty"`
+	PixelURL         string       `json:"pixel_url,omitempty"`
+	Disabled         bool         `json:"disabled,omitempty"`
+	Quarantined      bool         `json:"quarantined,omitempty"`
+	QuarantineReason string       `json:"quarantine_reason,omitempty"`
+	OpenGraph        OpenGraph    `json:"open_graph,omitempty"`
+	Rules            Rules        `json:"rules,omitempty"`
+	Variants         Variants     `json:"variants,omitempty"`
+	DeepLink         DeepLink     `json:"deep_link,omitempty"`
+	ExpiresAt        int64        `json:"expires_at,omitempty"`
+	Branding         Branding     `json:"branding,omitempty"`
+}
+
+// Expired reports whether the link stopped working before at.
+func (redirect Redirect) Expired(at time.Time) bool {
+	return redirect.ExpiresAt != 0 && at.Unix() >= redirect.ExpiresAt
+}
+
+// Resolve returns the destination for the visitor and the name of the rule
+// that chose it, which is empty for the default destination.
+func (redirect Redirect) Resolve(visitor Visitor) (url string, rule string) {
+	if matched, ok := redirect.Rules.Match(visitor); ok {
+		return matched.URL, matched.Name
+	}
+
+	return redirect.URL, ""
+}
@@ -14,11 +14,25 @@ const (
 
 type Unit string
 
+const (
+	SourceDirect = "Direct"
+	SourceQR     = "QR"
+	SourceBio    = "Bio"
+)
+
+// QRMarker is appended as a query to short links encoded in QR codes so that
+// scans can be told apart from other clicks.
+const QRMarker = "src=qr"
+
 type Click struct {
 	ShortenID uint64    `json:"shorten_id"`
 	Platform  string    `json:"platform"`
 	OS        string    `json:"os"`
 	Referer   string    `json:"referer"`
+	Source    string    `json:"source"`
+	Rule      string    `json:"rule,omitempty"`
+	Variant   string    `json:"variant,omitempty"`
+	Open      string    `json:"open,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
@@ -27,6 +41,21 @@ type Stats struct {
 	Platform []Metric    `json:"platform"`
 	OS       []Metric    `json:"os"`
 	Referer  []Metric    `json:"referer"`
+	Source   []Metric    `json:"source"`
+	Rule     []Metric    `json:"rule"`
+	Variant  []Metric    `json:"variant"`
+	Open     []Metric    `json:"open"`
+	// Annotations mark events on the time series, such as a new destination.
+	Annotations []Annotation `json:"annotations"`
+}
+
+const AnnotationDestinationChanged = "destination_changed"
+
+type Annotation struct {
+	Kind      string    `json:"kind"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 type ClickMetric struct {
@@ -47,3 +76,12 @@ type Metric struct {
 		Count     int       `json:"count"`
 	} `json:"values"`
 }
+
+// MissedKey is a short link key that was requested but does not exist, such
+// as a typo of a popular link or a probe for guessable keys.
+type MissedKey struct {
+	Key        string    `json:"key"`
+	Hits       int64     `json:"hits"`
+	TopReferer string    `json:"top_referer"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TwoFactorStatus struct {
+	Enabled       bool `json:"enabled"`
+	RecoveryCodes int  `json:"recovery_codes"`
+}
+
+type RecoveryCodes []string
+
+// Challenge is returned by sign in instead of a Session when the user has
+// two-factor authentication enabled.
+type Challenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresAt      int64  `json:"expires_at"`
+}
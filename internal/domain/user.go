@@ -2,8 +2,30 @@ package domain
 
 import "github.com/google/uuid"
 
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
+)
+
+type UserRole string
+
+func (role UserRole) Valid() bool {
+	return role == UserRoleUser || role == UserRoleAdmin
+}
+
 type User struct {
-	ID       uuid.UUID `json:"id"`
-	Name     string    `json:"name"`
-	Password []byte    `json:"-"`
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Password  []byte    `json:"-"`
+	Role      UserRole  `json:"role"`
+	BannedAt  *int64    `json:"banned_at,omitempty"`
+	BanReason string    `json:"ban_reason,omitempty"`
+}
+
+func (user User) Admin() bool {
+	return user.Role == UserRoleAdmin
+}
+
+func (user User) Banned() bool {
+	return user.BannedAt != nil
 }
@@ -0,0 +1,43 @@
+package domain
+
+// Variant is one destination of an A/B split. Visitors are spread across
+// variants in proportion to their weights.
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+type Variants []Variant
+
+// Get returns the variant with the given name.
+func (variants Variants) Get(name string) (Variant, bool) {
+	for _, variant := range variants {
+		if variant.Name == name {
+			return variant, true
+		}
+	}
+
+	return Variant{}, false
+}
+
+// Pick maps n, uniformly drawn from [0, total weight), to a variant.
+func (variants Variants) Pick(n int) Variant {
+	for _, variant := range variants {
+		if n < variant.Weight {
+			return variant
+		}
+		n -= variant.Weight
+	}
+
+	return variants[len(variants)-1]
+}
+
+func (variants Variants) TotalWeight() int {
+	var total int
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	return total
+}
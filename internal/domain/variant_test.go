@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"cc/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVariants_Pick(t *testing.T) {
+	variants := domain.Variants{
+		{Name: "a", URL: "https://example.com/a", Weight: 70},
+		{Name: "b", URL: "https://example.com/b", Weight: 30},
+	}
+
+	assert.Equal(t, 100, variants.TotalWeight())
+
+	counts := make(map[string]int)
+	for n := 0; n < variants.TotalWeight(); n++ {
+		counts[variants.Pick(n).Name]++
+	}
+
+	assert.Equal(t, map[string]int{"a": 70, "b": 30}, counts)
+}
+
+func TestVariants_Get(t *testing.T) {
+	variants := domain.Variants{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}}
+
+	variant, ok := variants.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, "b", variant.Name)
+
+	_, ok = variants.Get("c")
+	assert.False(t, ok)
+}
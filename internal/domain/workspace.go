@@ -0,0 +1,69 @@
+package domain
+
+import "github.com/google/uuid"
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+type Role string
+
+func (role Role) rank() int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func (role Role) Valid() bool {
+	return role.rank() > 0
+}
+
+// Allows reports whether role grants at least the permissions of required.
+func (role Role) Allows(required Role) bool {
+	return role.Valid() && role.rank() >= required.rank()
+}
+
+type Workspace struct {
+	ID               uuid.UUID `json:"id"`
+	Name             string    `json:"name"`
+	Personal         bool      `json:"personal"`
+	RequireTwoFactor bool      `json:"require_two_factor"`
+	Branding         Branding  `json:"branding"`
+	Role             Role      `json:"role,omitempty"`
+	CreatedAt        int64     `json:"created_at"`
+	UpdatedAt        int64     `json:"updated_at"`
+}
+
+type Workspaces []Workspace
+
+type Member struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Role      Role      `json:"role"`
+	CreatedAt int64     `json:"created_at"`
+}
+
+type Members []Member
+
+type Invitation struct {
+	ID          uuid.UUID  `json:"id"`
+	WorkspaceID uuid.UUID  `json:"workspace_id"`
+	Token       string     `json:"token,omitempty"`
+	Role        Role       `json:"role"`
+	CreatedBy   *uuid.UUID `json:"created_by"`
+	CreatedAt   int64      `json:"created_at"`
+	ExpiresAt   int64      `json:"expires_at"`
+	AcceptedBy  *uuid.UUID `json:"accepted_by"`
+	AcceptedAt  *int64     `json:"accepted_at"`
+}
+
+type Invitations []Invitation
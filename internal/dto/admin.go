@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"cc/internal/domain"
+	"cc/pkg/apperror"
+	"unicode/utf8"
+)
+
+type SearchShortens struct {
+	URL    string `form:"url"`
+	Domain string `form:"domain"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+func (searchShortens *SearchShortens) Validate() error {
+	if searchShortens.Limit == 0 {
+		searchShortens.Limit = 50
+	}
+
+	if searchShortens.Limit < 0 || searchShortens.Limit > 200 {
+		return apperror.BadRequest.WithMessage("limit must be between 1 and 200")
+	}
+
+	if searchShortens.Offset < 0 {
+		return apperror.BadRequest.WithMessage("offset is invalid")
+	}
+
+	return nil
+}
+
+type DisableShorten struct {
+	Reason string `json:"reason"`
+}
+
+func (disableShorten DisableShorten) Validate() error {
+	return validateReason(disableShorten.Reason)
+}
+
+type BanUser struct {
+	Reason          string `json:"reason"`
+	DisableShortens bool   `json:"disable_shortens"`
+}
+
+func (banUser BanUser) Validate() error {
+	return validateReason(banUser.Reason)
+}
+
+type SetUserRole struct {
+	Role domain.UserRole `json:"role"`
+}
+
+func (setUserRole SetUserRole) Validate() error {
+	if !setUserRole.Role.Valid() {
+		return apperror.BadRequest.WithMessage("role must be one of user, admin")
+	}
+
+	return nil
+}
+
+func validateReason(reason string) error {
+	if reason == "" {
+		return apperror.BadRequest.WithMessage("reason is required")
+	}
+
+	if utf8.RuneCountInString(reason) > 500 {
+		return apperror.BadRequest.WithMessage("reason is to long")
+	}
+
+	return nil
+}
+
+type SelectMissedKeys struct {
+	Days  int `form:"days"`
+	Limit int `form:"limit"`
+}
+
+func (selectMissedKeys *SelectMissedKeys) Validate() error {
+	if selectMissedKeys.Days == 0 {
+		selectMissedKeys.Days = 7
+	}
+
+	if selectMissedKeys.Limit == 0 {
+		selectMissedKeys.Limit = 50
+	}
+
+	if selectMissedKeys.Days < 0 || selectMissedKeys.Days > 90 {
+		return apperror.BadRequest.WithMessage("days must be between 1 and 90")
+	}
+
+	if selectMissedKeys.Limit < 0 || selectMissedKeys.Limit > 200 {
+		return apperror.BadRequest.WithMessage("limit must be between 1 and 200")
+	}
+
+	return nil
+}
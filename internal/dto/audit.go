@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"cc/pkg/apperror"
+	"github.com/google/uuid"
+	"time"
+)
+
+// AuditEntry is a mutating call to record. IP and UserAgent default to the
+// origin of the request and ActorID to the signed-in user.
+type AuditEntry struct {
+	ActorID    *uuid.UUID
+	IP         string
+	UserAgent  string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     any
+	After      any
+}
+
+// SelectAudit filters the audit log. From and To are days, both included.
+type SelectAudit struct {
+	ActorID    string `form:"actor_id"`
+	Action     string `form:"action"`
+	TargetType string `form:"target_type"`
+	TargetID   string `form:"target_id"`
+	From       string `form:"from"`
+	To         string `form:"to"`
+	Limit      int    `form:"limit"`
+	Offset     int    `form:"offset"`
+}
+
+func (selectAudit *SelectAudit) Validate() error {
+	if selectAudit.ActorID != "" {
+		if _, err := uuid.Parse(selectAudit.ActorID); err != nil {
+			return apperror.BadRequest.WithMessage("actor_id is invalid")
+		}
+	}
+
+	if selectAudit.From != "" {
+		if _, err := time.Parse("2006-01-02", selectAudit.From); err != nil {
+			return apperror.BadRequest.WithMessage("from is invalid, expected 2006-01-02")
+		}
+	}
+
+	if selectAudit.To != "" {
+		if _, err := time.Parse("2006-01-02", selectAudit.To); err != nil {
+			return apperror.BadRequest.WithMessage("to is invalid, expected 2006-01-02")
+		}
+	}
+
+	if selectAudit.Limit == 0 {
+		selectAudit.Limit = 50
+	}
+
+	if selectAudit.Limit < 0 || selectAudit.Limit > 200 {
+		return apperror.BadRequest.WithMessage("limit must be between 1 and 200")
+	}
+
+	if selectAudit.Offset < 0 {
+		return apperror.BadRequest.WithMessage("offset is invalid")
+	}
+
+	return nil
+}
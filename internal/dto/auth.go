@@ -12,34 +12,135 @@ type Credentials struct {
 }
 
 func (credentials Credentials) Validate() error {
-	if credentials.Name == "" {
-		return apperror.BadRequest.WithMessage("name is required")
+	if err := validateName(credentials.Name); err != nil {
+		return err
 	}
 
-	if credentials.Password == "" {
-		return apperror.BadRequest.WithMessage("password is required")
+	return validatePassword(credentials.Password, "password")
+}
+
+type ChangePassword struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+func (changePassword ChangePassword) Validate() error {
+	if changePassword.OldPassword == "" {
+		return apperror.BadRequest.WithMessage("old_password is required")
 	}
 
-	if !utf8.ValidString(credentials.Name) {
-		return apperror.BadRequest.WithMessage("name is invalid")
+	return validatePassword(changePassword.NewPassword, "new_password")
+}
+
+type ChangeName struct {
+	Name string `json:"name"`
+}
+
+func (changeName ChangeName) Validate() error {
+	return validateName(changeName.Name)
+}
+
+type DeleteUser struct {
+	// Password is required unless the user only signs in with single sign-on.
+	Password string `json:"password"`
+}
+
+func (deleteUser DeleteUser) Validate() error {
+	return nil
+}
+
+type OIDCCallback struct {
+	Code             string `form:"code"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+	// Binding comes from the cookie set when the flow was started.
+	Binding string `form:"-"`
+}
+
+func (oidcCallback OIDCCallback) Validate() error {
+	if oidcCallback.Error != "" {
+		message := oidcCallback.Error
+		if oidcCallback.ErrorDescription != "" {
+			message += ": " + oidcCallback.ErrorDescription
+		}
+
+		return apperror.Unauthorized.WithMessage(message)
 	}
 
-	if !utf8.ValidString(credentials.Password) {
-		return apperror.BadRequest.WithMessage("password is invalid")
+	if oidcCallback.Code == "" {
+		return apperror.BadRequest.WithMessage("code is required")
 	}
 
-	nameLen := utf8.RuneCountInString(credentials.Name)
+	if oidcCallback.State == "" {
+		return apperror.BadRequest.WithMessage("state is required")
+	}
+
+	return nil
+}
+
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+func (twoFactorCode TwoFactorCode) Validate() error {
+	if twoFactorCode.Code == "" {
+		return apperror.BadRequest.WithMessage("code is required")
+	}
+
+	return nil
+}
+
+type TwoFactorSignIn struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+func (twoFactorSignIn TwoFactorSignIn) Validate() error {
+	if twoFactorSignIn.ChallengeToken == "" {
+		return apperror.BadRequest.WithMessage("challenge_token is required")
+	}
+
+	if twoFactorSignIn.Code == "" {
+		return apperror.BadRequest.WithMessage("code is required")
+	}
+
+	return nil
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return apperror.BadRequest.WithMessage("name is required")
+	}
+
+	if !utf8.ValidString(name) {
+		return apperror.BadRequest.WithMessage("name is invalid")
+	}
+
+	nameLen := utf8.RuneCountInString(name)
 	if nameLen < 3 {
 		return apperror.BadRequest.WithMessage("name is to short")
 	} else if nameLen > 20 {
 		return apperror.BadRequest.WithMessage("name is to long")
 	}
 
-	passwordLen := utf8.RuneCountInString(credentials.Password)
+	return nil
+}
+
+func validatePassword(password string, field string) error {
+	if password == "" {
+		return apperror.BadRequest.WithMessage(field + " is required")
+	}
+
+	if !utf8.ValidString(password) {
+		return apperror.BadRequest.WithMessage(field + " is invalid")
+	}
+
+	passwordLen := utf8.RuneCountInString(password)
 	if passwordLen < 5 {
-		return apperror.BadRequest.WithMessage("password is to short")
+		return apperror.BadRequest.WithMessage(field + " is to short")
 	} else if passwordLen > 50 {
-		return apperror.BadRequest.WithMessage("password is to long")
+		return apperror.BadRequest.WithMessage(field + " is to long")
 	}
 
 	return nil
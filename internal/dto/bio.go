@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"cc/internal/domain"
+	"cc/pkg/apperror"
+	"cc/pkg/base62"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+var regexpBioName = regexp.MustCompile(`^[a-z0-9_.-]{3,30}$`)
+
+type SaveBioPage struct {
+	Name      string       `json:"name"`
+	Title     string       `json:"title"`
+	AvatarURL string       `json:"avatar_url"`
+	Theme     domain.Theme `json:"theme"`
+	// Links are the keys of the shortens to list, in order.
+	Links []string `json:"links"`
+}
+
+// Validate also defaults the theme.
+func (saveBioPage *SaveBioPage) Validate() error {
+	if !regexpBioName.MatchString(saveBioPage.Name) {
+		return apperror.BadRequest.WithMessage("name must be 3 to 30 lowercase letters, digits, dots, dashes or underscores")
+	}
+
+	if saveBioPage.Title == "" || utf8.RuneCountInString(saveBioPage.Title) > 100 {
+		return apperror.BadRequest.WithMessage("title is required and must be at most 100 characters")
+	}
+
+	if saveBioPage.AvatarURL != "" && !isAbsoluteHTTP(saveBioPage.AvatarURL) {
+		return apperror.BadRequest.WithMessage("avatar must be an absolute http(s) url")
+	}
+
+	if saveBioPage.Theme == "" {
+		saveBioPage.Theme = domain.ThemeLight
+	}
+
+	if !saveBioPage.Theme.Valid() {
+		return apperror.BadRequest.WithMessage("theme must be one of light, dark")
+	}
+
+	if len(saveBioPage.Links) > 50 {
+		return apperror.BadRequest.WithMessage("at most 50 links are allowed")
+	}
+
+	keys := make(map[string]struct{}, len(saveBioPage.Links))
+	for _, key := range saveBioPage.Links {
+		if _, err := base62.Decode(key); err != nil {
+			return apperror.BadRequest.WithError(err).WithMessage("link " + key + " is invalid")
+		}
+
+		if _, ok := keys[key]; ok {
+			return apperror.BadRequest.WithMessage("links must be unique")
+		}
+		keys[key] = struct{}{}
+	}
+
+	return nil
+}
+
+type GetBioStats struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+func (getBioStats GetBioStats) Validate() error {
+	var err error
+	_, err = time.Parse("2006-01-02", getBioStats.From)
+	if err != nil {
+		return apperror.BadRequest.WithMessage("from is invalid, expected 2006-01-02")
+	}
+
+	_, err = time.Parse("2006-01-02", getBioStats.To)
+	if err != nil {
+		return apperror.BadRequest.WithMessage("to is invalid, expected 2006-01-02")
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"cc/pkg/apperror"
+	"cc/pkg/qr"
+	"net/url"
+)
+
+type GetQR struct {
+	Format     string   `form:"format"`
+	Size       int      `form:"size"`
+	Margin     *int     `form:"margin"`
+	ECC        qr.Level `form:"ecc"`
+	Foreground string   `form:"fg"`
+	Background string   `form:"bg"`
+	Logo       string   `form:"logo"`
+}
+
+// Validate also fills in defaults. Codes with a logo default to the highest
+// error correction level, since the logo hides part of the code.
+func (getQR *GetQR) Validate() error {
+	switch getQR.Format {
+	case "":
+		getQR.Format = "png"
+	case "png", "svg":
+	default:
+		return apperror.BadRequest.WithMessage("format must be one of png, svg")
+	}
+
+	if getQR.Size == 0 {
+		getQR.Size = 512
+	}
+
+	if getQR.Size < 64 || getQR.Size > 2048 {
+		return apperror.BadRequest.WithMessage("size must be between 64 and 2048")
+	}
+
+	if getQR.Margin == nil {
+		margin := 4
+		getQR.Margin = &margin
+	}
+
+	if *getQR.Margin < 0 || *getQR.Margin > 16 {
+		return apperror.BadRequest.WithMessage("margin must be between 0 and 16")
+	}
+
+	if getQR.ECC == "" {
+		getQR.ECC = qr.LevelM
+		if getQR.Logo != "" {
+			getQR.ECC = qr.LevelH
+		}
+	}
+
+	if !getQR.ECC.Valid() {
+		return apperror.BadRequest.WithMessage("ecc must be one of L, M, Q, H")
+	}
+
+	if getQR.Foreground == "" {
+		getQR.Foreground = "000000"
+	}
+
+	if _, err := qr.ParseColor(getQR.Foreground); err != nil {
+		return apperror.BadRequest.WithError(err).WithMessage("fg is invalid")
+	}
+
+	if getQR.Background == "" {
+		getQR.Background = "ffffff"
+	}
+
+	if _, err := qr.ParseColor(getQR.Background); err != nil {
+		return apperror.BadRequest.WithError(err).WithMessage("bg is invalid")
+	}
+
+	if getQR.Logo != "" {
+		logo, err := url.Parse(getQR.Logo)
+		if err != nil || (logo.Scheme != "http" && logo.Scheme != "https") || logo.Host == "" {
+			return apperror.BadRequest.WithMessage("logo must be an absolute http(s) url")
+		}
+	}
+
+	return nil
+}
@@ -1,26 +1,68 @@
 package dto
 
 import (
+	"cc/internal/domain"
 	"cc/pkg/apperror"
 	"cc/pkg/base62"
 	"cc/pkg/urlutils"
+	"github.com/google/uuid"
+	"net/url"
+	"strings"
+	"time"
 	"unicode/utf8"
 )
 
 type CreateShorten struct {
-	Key   string `json:"key"`
-	URL   string `json:"url"`
-	Title string `json:"title"`
+	Key          string              `json:"key"`
+	URL          string              `json:"url"`
+	Title        string              `json:"title"`
+	WorkspaceID  uuid.UUID           `json:"workspace_id"`
+	OpenGraph    *OpenGraph          `json:"open_graph,omitempty"`
+	RedirectType domain.RedirectType `json:"redirect_type,omitempty"`
+	PixelURL     string              `json:"pixel_url,omitempty"`
+	DeepLink     *domain.DeepLink    `json:"deep_link,omitempty"`
+	ExpiresAt    int64               `json:"expires_at,omitempty"`
 }
 
 type UpdateShorten struct {
-	Title string   `json:"title,omitempty"`
-	URL   string   `json:"url,omitempty"`
-	Tags  []string `json:"tags,omitempty"`
+	Title        string              `json:"title,omitempty"`
+	URL          string              `json:"url,omitempty"`
+	Tags         []string            `json:"tags,omitempty"`
+	OpenGraph    *OpenGraph          `json:"open_graph,omitempty"`
+	RedirectType domain.RedirectType `json:"redirect_type,omitempty"`
+	// PixelURL is removed when set to an empty string.
+	PixelURL *string `json:"pixel_url,omitempty"`
+	// DeepLink replaces the app URIs; send an empty object to remove them.
+	DeepLink *domain.DeepLink `json:"deep_link,omitempty"`
+	// ExpiresAt is a Unix time; 0 removes the expiry.
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+}
+
+// OpenGraph replaces the whole card; send empty fields to remove them.
+type OpenGraph struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
 }
 
 type SelectShortens struct {
-	Tags []string `form:"tags,omitempty"`
+	Tags   []string            `form:"tags,omitempty"`
+	Health domain.HealthStatus `form:"health,omitempty"`
+}
+
+func (selectShortens SelectShortens) Validate() error {
+	if selectShortens.Health != "" && !selectShortens.Health.Valid() {
+		return apperror.BadRequest.WithMessage("health must be one of unknown, healthy, broken")
+	}
+
+	return nil
+}
+
+// reservedKeys are paths served by the instance itself, which would shadow
+// links with these keys.
+var reservedKeys = map[string]bool{
+	"healthz": true,
+	"readyz":  true,
 }
 
 func (createShorten CreateShorten) Validate() error {
@@ -32,10 +74,35 @@ func (createShorten CreateShorten) Validate() error {
 		return apperror.BadRequest.WithError(err).WithMessage("url is invalid")
 	}
 
-	if _, err := base62.Decode(createShorten.Key); err != nil {
+	id, err := base62.Decode(createShorten.Key)
+	if err != nil {
 		return apperror.BadRequest.WithError(err).WithMessage("key is invalid")
 	}
 
+	// Leading zero digits are dropped from the link, so the key is compared
+	// the way it will be served.
+	if createShorten.Key != "" && reservedKeys[base62.Encode(id)] {
+		return apperror.BadRequest.WithMessage("key is reserved")
+	}
+
+	if err := validateRedirect(createShorten.RedirectType, createShorten.PixelURL); err != nil {
+		return err
+	}
+
+	if createShorten.DeepLink != nil {
+		if err := validateDeepLink(*createShorten.DeepLink); err != nil {
+			return err
+		}
+	}
+
+	if createShorten.ExpiresAt != 0 && createShorten.ExpiresAt <= time.Now().Unix() {
+		return apperror.BadRequest.WithMessage("expires_at must be in the future")
+	}
+
+	if createShorten.OpenGraph != nil {
+		return createShorten.OpenGraph.Validate()
+	}
+
 	return nil
 }
 
@@ -48,5 +115,180 @@ func (updateShorten UpdateShorten) Validate() error {
 		return apperror.BadRequest.WithMessage("url is invalid")
 	}
 
+	var pixelURL string
+	if updateShorten.PixelURL != nil {
+		pixelURL = *updateShorten.PixelURL
+	}
+
+	if err := validateRedirect(updateShorten.RedirectType, pixelURL); err != nil {
+		return err
+	}
+
+	if updateShorten.DeepLink != nil {
+		if err := validateDeepLink(*updateShorten.DeepLink); err != nil {
+			return err
+		}
+	}
+
+	if updateShorten.ExpiresAt != nil && *updateShorten.ExpiresAt < 0 {
+		return apperror.BadRequest.WithMessage("expires_at is invalid")
+	}
+
+	if updateShorten.OpenGraph != nil {
+		return updateShorten.OpenGraph.Validate()
+	}
+
+	return nil
+}
+
+func validateRedirect(redirectType domain.RedirectType, pixelURL string) error {
+	if redirectType != "" && !redirectType.Valid() {
+		return apperror.BadRequest.WithMessage("redirect type must be one of 301, 302, 307, 308, cloaked, refresh")
+	}
+
+	if pixelURL != "" && !isAbsoluteHTTP(pixelURL) {
+		return apperror.BadRequest.WithMessage("pixel url must be an absolute http(s) url")
+	}
+
+	return nil
+}
+
+func validateDeepLink(deepLink domain.DeepLink) error {
+	for _, uri := range []string{deepLink.IOS, deepLink.Android} {
+		if uri != "" && !isAppURI(uri) {
+			return apperror.BadRequest.WithMessage("deep link " + uri + " must be an app uri like myapp://path")
+		}
+	}
+
+	if deepLink.Fallback != "" && !deepLink.Fallback.Valid() {
+		return apperror.BadRequest.WithMessage("deep link fallback must be one of web, store")
+	}
+
+	return nil
+}
+
+// isAppURI accepts scheme://rest URIs other than ones that run code in the
+// browser.
+func isAppURI(uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil || len(uri) > 2048 || !strings.Contains(uri, "://") {
+		return false
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "javascript", "data", "vbscript", "file":
+		return false
+	}
+
+	return true
+}
+
+func isAbsoluteHTTP(link string) bool {
+	parsed, err := url.Parse(link)
+
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" && len(link) <= 2048
+}
+
+type SetRules struct {
+	Rules domain.Rules `json:"rules"`
+}
+
+// Validate also normalizes country codes to upper case.
+func (setRules SetRules) Validate() error {
+	if len(setRules.Rules) > 20 {
+		return apperror.BadRequest.WithMessage("at most 20 rules are allowed")
+	}
+
+	names := make(map[string]struct{}, len(setRules.Rules))
+	for i := range setRules.Rules {
+		rule := &setRules.Rules[i]
+
+		if rule.Name == "" || utf8.RuneCountInString(rule.Name) > 50 {
+			return apperror.BadRequest.WithMessage("rule name is required and must be at most 50 characters")
+		}
+
+		if _, ok := names[rule.Name]; ok {
+			return apperror.BadRequest.WithMessage("rule names must be unique")
+		}
+		names[rule.Name] = struct{}{}
+
+		if err := urlutils.Validate(rule.URL); err != nil {
+			return apperror.BadRequest.WithError(err).WithMessage("url of rule " + rule.Name + " is invalid")
+		}
+
+		if len(rule.OS) == 0 && len(rule.Platforms) == 0 && len(rule.Countries) == 0 && rule.StartsAt == 0 && rule.EndsAt == 0 {
+			return apperror.BadRequest.WithMessage("rule " + rule.Name + " has no conditions")
+		}
+
+		for _, platform := range rule.Platforms {
+			switch platform {
+			case "Mobile", "Desktop", "Tablet":
+			default:
+				return apperror.BadRequest.WithMessage("platforms must be one of Mobile, Desktop, Tablet")
+			}
+		}
+
+		for j, country := range rule.Countries {
+			if len(country) != 2 {
+				return apperror.BadRequest.WithMessage("countries must be ISO 3166-1 alpha-2 codes")
+			}
+			rule.Countries[j] = strings.ToUpper(country)
+		}
+
+		if rule.StartsAt != 0 && rule.EndsAt != 0 && rule.EndsAt <= rule.StartsAt {
+			return apperror.BadRequest.WithMessage("rule " + rule.Name + " ends before it starts")
+		}
+	}
+
+	return nil
+}
+
+type SetVariants struct {
+	Variants domain.Variants `json:"variants"`
+}
+
+func (setVariants SetVariants) Validate() error {
+	if len(setVariants.Variants) == 1 || len(setVariants.Variants) > 10 {
+		return apperror.BadRequest.WithMessage("a split test needs from 2 to 10 variants")
+	}
+
+	names := make(map[string]struct{}, len(setVariants.Variants))
+	for _, variant := range setVariants.Variants {
+		if variant.Name == "" || utf8.RuneCountInString(variant.Name) > 50 {
+			return apperror.BadRequest.WithMessage("variant name is required and must be at most 50 characters")
+		}
+
+		if _, ok := names[variant.Name]; ok {
+			return apperror.BadRequest.WithMessage("variant names must be unique")
+		}
+		names[variant.Name] = struct{}{}
+
+		if err := urlutils.Validate(variant.URL); err != nil {
+			return apperror.BadRequest.WithError(err).WithMessage("url of variant " + variant.Name + " is invalid")
+		}
+
+		if variant.Weight < 1 || variant.Weight > 1000 {
+			return apperror.BadRequest.WithMessage("variant weight must be from 1 to 1000")
+		}
+	}
+
+	return nil
+}
+
+func (openGraph OpenGraph) Validate() error {
+	if utf8.RuneCountInString(openGraph.Title) > 200 {
+		return apperror.BadRequest.WithMessage("open graph title is to long")
+	}
+
+	if utf8.RuneCountInString(openGraph.Description) > 500 {
+		return apperror.BadRequest.WithMessage("open graph description is to long")
+	}
+
+	if openGraph.Image != "" {
+		if !isAbsoluteHTTP(openGraph.Image) {
+			return apperror.BadRequest.WithMessage("open graph image must be an absolute http(s) url")
+		}
+	}
+
 	return nil
 }
@@ -3,16 +3,49 @@ package dto
 import (
 	"cc/internal/domain"
 	"cc/pkg/apperror"
+	"github.com/google/uuid"
 	"time"
 )
 
 type CreateClick struct {
-	ShortenID uint64    `json:"shorten_id"`
-	Platform  string    `json:"platform"`
-	OS        string    `json:"os"`
-	Referer   string    `json:"referer"`
-	IP        string    `json:"ip"`
-	Timestamp time.Time `json:"timestamp"`
+	ShortenID uint64     `json:"shorten_id"`
+	Platform  string     `json:"platform"`
+	OS        string     `json:"os"`
+	Referer   string     `json:"referer"`
+	IP        string     `json:"ip"`
+	Source    string     `json:"source"`
+	Rule      string     `json:"rule"`
+	Variant   string     `json:"variant"`
+	Open      string     `json:"open"`
+	BioPageID *uuid.UUID `json:"bio_page_id"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// Visit is an opened short link as seen by the redirect handler. Marker is
+// the query of the link and tells where it was found, Rule is the redirect
+// rule and Variant the split test variant that chose the destination.
+// BioPageID is set when the link was opened from a bio page and Open tells
+// whether a deep link opened the app or fell back.
+type Visit struct {
+	ShortenID uint64
+	Timestamp time.Time
+	UserAgent string
+	Referer   string
+	IP        string
+	Marker    string
+	Rule      string
+	Variant   string
+	Open      string
+	BioPageID *uuid.UUID
+}
+
+// NotFoundHit is a request for a key without a shorten. UserAgent is only
+// used to leave out bots and is not stored.
+type NotFoundHit struct {
+	Key       string
+	Timestamp time.Time
+	UserAgent string
+	Referer   string
 }
 
 type GetShortenStats struct {
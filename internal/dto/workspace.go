@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"cc/internal/domain"
+	"cc/pkg/apperror"
+	"regexp"
+	"unicode/utf8"
+)
+
+type CreateWorkspace struct {
+	Name string `json:"name"`
+}
+
+func (createWorkspace CreateWorkspace) Validate() error {
+	return validateWorkspaceName(createWorkspace.Name)
+}
+
+type UpdateWorkspace struct {
+	Name             string `json:"name,omitempty"`
+	RequireTwoFactor *bool  `json:"require_two_factor,omitempty"`
+	// Branding replaces the whole branding; send an empty object to remove it.
+	Branding *domain.Branding `json:"branding,omitempty"`
+}
+
+func (updateWorkspace UpdateWorkspace) Validate() error {
+	if updateWorkspace.Name == "" && updateWorkspace.RequireTwoFactor == nil && updateWorkspace.Branding == nil {
+		return apperror.BadRequest.WithMessage("nothing to update")
+	}
+
+	if updateWorkspace.Name != "" {
+		if err := validateWorkspaceName(updateWorkspace.Name); err != nil {
+			return err
+		}
+	}
+
+	if updateWorkspace.Branding != nil {
+		return validateBranding(*updateWorkspace.Branding)
+	}
+
+	return nil
+}
+
+var regexpColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func validateBranding(branding domain.Branding) error {
+	if utf8.RuneCountInString(branding.Name) > 100 {
+		return apperror.BadRequest.WithMessage("branding name is to long")
+	}
+
+	if branding.Color != "" && !regexpColor.MatchString(branding.Color) {
+		return apperror.BadRequest.WithMessage("branding color must look like #1a73e8")
+	}
+
+	if branding.LogoURL != "" && !isAbsoluteHTTP(branding.LogoURL) {
+		return apperror.BadRequest.WithMessage("branding logo must be an absolute http(s) url")
+	}
+
+	if branding.HomeURL != "" && !isAbsoluteHTTP(branding.HomeURL) {
+		return apperror.BadRequest.WithMessage("branding home must be an absolute http(s) url")
+	}
+
+	return nil
+}
+
+type UpdateMember struct {
+	Role domain.Role `json:"role"`
+}
+
+func (updateMember UpdateMember) Validate() error {
+	if !updateMember.Role.Valid() {
+		return apperror.BadRequest.WithMessage("role must be one of owner, editor, viewer")
+	}
+
+	return nil
+}
+
+type CreateInvitation struct {
+	Role      domain.Role `json:"role"`
+	ExpiresIn int64       `json:"expires_in"`
+}
+
+func (createInvitation CreateInvitation) Validate() error {
+	if !createInvitation.Role.Valid() {
+		return apperror.BadRequest.WithMessage("role must be one of owner, editor, viewer")
+	}
+
+	if createInvitation.ExpiresIn < 0 {
+		return apperror.BadRequest.WithMessage("expires_in is invalid")
+	}
+
+	return nil
+}
+
+type AcceptInvitation struct {
+	Token string `json:"token"`
+}
+
+func (acceptInvitation AcceptInvitation) Validate() error {
+	if acceptInvitation.Token == "" {
+		return apperror.BadRequest.WithMessage("token is required")
+	}
+
+	return nil
+}
+
+func validateWorkspaceName(name string) error {
+	if name == "" {
+		return apperror.BadRequest.WithMessage("name is required")
+	}
+
+	if utf8.RuneCountInString(name) > 100 {
+		return apperror.BadRequest.WithMessage("name is to long")
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"cc/internal/domain"
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type AuditRecord struct {
+	ID         int64           `db:"id"`
+	ActorID    *uuid.UUID      `db:"actor_id"`
+	IP         string          `db:"ip"`
+	UserAgent  string          `db:"user_agent"`
+	Action     string          `db:"action"`
+	TargetType string          `db:"target_type"`
+	TargetID   string          `db:"target_id"`
+	Before     json.RawMessage `db:"before"`
+	After      json.RawMessage `db:"after"`
+	CreatedAt  time.Time       `db:"created_at"`
+}
+
+func (record AuditRecord) Domain() domain.AuditRecord {
+	return domain.AuditRecord(record)
+}
+
+type AuditRecords []AuditRecord
+
+func (records AuditRecords) Domain() []domain.AuditRecord {
+	res := make([]domain.AuditRecord, len(records))
+
+	for i, record := range records {
+		res[i] = record.Domain()
+	}
+
+	return res
+}
+
+// AuditFilter selects records, newest first. Zero fields match everything;
+// BeforeID pages through results without offsets.
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	BeforeID   int64
+	Limit      int
+	Offset     int
+}
@@ -6,10 +6,15 @@ import (
 )
 
 type Session struct {
-	ID           uuid.UUID `db:"id"`
-	UserID       uuid.UUID `db:"user_id"`
-	RefreshToken uuid.UUID `db:"refresh_token"`
-	IP           string    `db:"ip"`
-	CreatedAt    time.Time `db:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at"`
+	ID           uuid.UUID  `db:"id"`
+	UserID       uuid.UUID  `db:"user_id"`
+	RefreshToken uuid.UUID  `db:"refresh_token"`
+	IP           string     `db:"ip"`
+	UserAgent    string     `db:"user_agent"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+	ExpiresAt    time.Time  `db:"expires_at"`
+	RevokedAt    *time.Time `db:"revoked_at"`
 }
+
+type Sessions []Session
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"cc/internal/domain"
+	"github.com/google/uuid"
+	"time"
+)
+
+type BioPage struct {
+	ID         uuid.UUID `db:"id"`
+	UserID     uuid.UUID `db:"user_id"`
+	Name       string    `db:"name"`
+	Title      string    `db:"title"`
+	AvatarURL  string    `db:"avatar_url"`
+	Theme      string    `db:"theme"`
+	ShortenIDs []int64   `db:"shorten_ids"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// Domain lists the page's links in order, skipping shortens that no longer
+// exist.
+func (page BioPage) Domain(shortens Shortens, url string, now time.Time) domain.BioPage {
+	byID := make(map[uint64]Shorten, len(shortens))
+	for _, shorten := range shortens {
+		byID[shorten.ID] = shorten
+	}
+
+	links := make([]domain.BioLink, 0, len(page.ShortenIDs))
+	for _, id := range page.ShortenIDs {
+		shorten, ok := byID[uint64(id)]
+		if !ok {
+			continue
+		}
+
+		key := shorten.Domain(url).ID
+		links = append(links, domain.BioLink{
+			Key:    key,
+			Title:  shorten.Title,
+			URL:    url + "/@" + page.Name + "/" + key,
+			Active: shorten.DisabledAt == nil && shorten.QuarantinedAt == nil && (shorten.ExpiresAt == nil || now.Before(*shorten.ExpiresAt)),
+		})
+	}
+
+	return domain.BioPage{
+		ID:        page.ID,
+		Name:      page.Name,
+		Title:     page.Title,
+		AvatarURL: page.AvatarURL,
+		Theme:     domain.Theme(page.Theme),
+		Links:     links,
+		CreatedAt: page.CreatedAt.Unix(),
+		UpdatedAt: page.UpdatedAt.Unix(),
+	}
+}
+
+type BioLinkStats struct {
+	ShortenID int64 `db:"shorten_id"`
+	Clicks    int64 `db:"clicks"`
+}
@@ -0,0 +1,35 @@
+package model_test
+
+import (
+	"cc/internal/model"
+	"cc/pkg/base62"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBioPage_Domain(t *testing.T) {
+	now := time.Date(2023, 8, 5, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+
+	page := model.BioPage{Name: "jane", ShortenIDs: []int64{3, 1, 4, 2}}
+	shortens := model.Shortens{
+		{ID: 1, Title: "Blog"},
+		{ID: 2, Title: "Shop", ExpiresAt: &past},
+		{ID: 3, Title: "Podcast", DisabledAt: &past},
+	}
+
+	bio := page.Domain(shortens, "https://cc.io", now)
+
+	if assert.Len(t, bio.Links, 3) {
+		assert.Equal(t, "Podcast", bio.Links[0].Title)
+		assert.False(t, bio.Links[0].Active)
+
+		assert.Equal(t, "Blog", bio.Links[1].Title)
+		assert.True(t, bio.Links[1].Active)
+		assert.Equal(t, "https://cc.io/@jane/"+base62.Encode(1), bio.Links[1].URL)
+
+		assert.Equal(t, "Shop", bio.Links[2].Title)
+		assert.False(t, bio.Links[2].Active)
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"cc/internal/domain"
+	"time"
+)
+
+type Health struct {
+	Status     string     `db:"health_status"`
+	StatusCode int        `db:"health_status_code"`
+	LatencyMS  int64      `db:"health_latency_ms"`
+	FinalURL   string     `db:"health_final_url"`
+	Error      string     `db:"health_error"`
+	Failures   int        `db:"health_failures"`
+	CheckedAt  *time.Time `db:"health_checked_at"`
+}
+
+func (health Health) Domain() domain.Health {
+	h := domain.Health{
+		Status:     domain.HealthStatus(health.Status),
+		StatusCode: health.StatusCode,
+		LatencyMS:  health.LatencyMS,
+		FinalURL:   health.FinalURL,
+		Error:      health.Error,
+	}
+
+	if h.Status == "" {
+		h.Status = domain.HealthUnknown
+	}
+
+	if health.CheckedAt != nil {
+		h.CheckedAt = health.CheckedAt.Unix()
+	}
+
+	return h
+}
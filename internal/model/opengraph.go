@@ -0,0 +1,17 @@
+package model
+
+import "cc/internal/domain"
+
+type OpenGraph struct {
+	Title       string `db:"og_title"`
+	Description string `db:"og_description"`
+	Image       string `db:"og_image"`
+}
+
+func (openGraph OpenGraph) Domain() domain.OpenGraph {
+	return domain.OpenGraph{
+		Title:       openGraph.Title,
+		Description: openGraph.Description,
+		Image:       openGraph.Image,
+	}
+}
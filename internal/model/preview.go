@@ -0,0 +1,33 @@
+package model
+
+import (
+	"cc/internal/domain"
+	"time"
+)
+
+type Preview struct {
+	Title       string     `db:"preview_title"`
+	Description string     `db:"preview_description"`
+	OGTitle     string     `db:"preview_og_title"`
+	OGImage     string     `db:"preview_og_image"`
+	Favicon     string     `db:"preview_favicon"`
+	Error       string     `db:"preview_error"`
+	FetchedAt   *time.Time `db:"preview_fetched_at"`
+}
+
+func (preview Preview) Domain() domain.Preview {
+	p := domain.Preview{
+		Title:       preview.Title,
+		Description: preview.Description,
+		OGTitle:     preview.OGTitle,
+		OGImage:     preview.OGImage,
+		Favicon:     preview.Favicon,
+		Error:       preview.Error,
+	}
+
+	if preview.FetchedAt != nil {
+		p.FetchedAt = preview.FetchedAt.Unix()
+	}
+
+	return p
+}
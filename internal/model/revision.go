@@ -0,0 +1,99 @@
+package model
+
+import (
+	"cc/internal/domain"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Revision struct {
+	ID        int64                 `db:"id"`
+	ShortenID uint64                `db:"shorten_id"`
+	UserID    *uuid.UUID            `db:"user_id"`
+	Action    domain.RevisionAction `db:"action"`
+	Changes   domain.Changes        `db:"changes"`
+	State     domain.ShortenState   `db:"state"`
+	CreatedAt time.Time             `db:"created_at"`
+}
+
+func (revision Revision) Domain() domain.Revision {
+	return domain.Revision{
+		ID:        revision.ID,
+		UserID:    revision.UserID,
+		Action:    revision.Action,
+		Changes:   revision.Changes,
+		State:     revision.State,
+		CreatedAt: revision.CreatedAt.Unix(),
+	}
+}
+
+type Revisions []Revision
+
+func (revisions Revisions) Domain() domain.Revisions {
+	res := make(domain.Revisions, len(revisions))
+
+	for i, revision := range revisions {
+		res[i] = revision.Domain()
+	}
+
+	return res
+}
+
+// DestinationChange is a revision that changed the URL of a shorten.
+type DestinationChange struct {
+	From      string    `db:"from"`
+	To        string    `db:"to"`
+	Timestamp time.Time `db:"timestamp"`
+}
+
+func (change DestinationChange) Annotation() domain.Annotation {
+	return domain.Annotation{
+		Kind:      domain.AnnotationDestinationChanged,
+		From:      change.From,
+		To:        change.To,
+		Timestamp: change.Timestamp,
+	}
+}
+
+func (s Shorten) State() domain.ShortenState {
+	state := domain.ShortenState{
+		URL:          s.URL,
+		Title:        s.Title,
+		Tags:         s.Tags,
+		OpenGraph:    s.OpenGraph.Domain(),
+		RedirectType: s.RedirectType,
+		PixelURL:     s.PixelURL,
+		DeepLink:     s.DeepLink,
+		Rules:        s.Rules,
+		Variants:     s.Variants,
+	}
+
+	if s.ExpiresAt != nil {
+		expiresAt := s.ExpiresAt.Unix()
+		state.ExpiresAt = &expiresAt
+	}
+
+	return state
+}
+
+// Restore sets the editable fields back to state.
+func (s *Shorten) Restore(state domain.ShortenState) {
+	s.URL = state.URL
+	s.Title = state.Title
+	s.Tags = state.Tags
+	if s.Tags == nil {
+		s.Tags = []string{}
+	}
+	s.OpenGraph = OpenGraph(state.OpenGraph)
+	s.RedirectType = state.RedirectType
+	s.PixelURL = state.PixelURL
+	s.DeepLink = state.DeepLink
+	s.Rules = state.Rules
+	s.Variants = state.Variants
+
+	s.ExpiresAt = nil
+	if state.ExpiresAt != nil {
+		expiresAt := time.Unix(*state.ExpiresAt, 0)
+		s.ExpiresAt = &expiresAt
+	}
+}
@@ -8,29 +8,110 @@ import (
 )
 
 type Shorten struct {
-	ID        uint64    `db:"id"`
-	URL       string    `db:"url"`
-	UserID    uuid.UUID `db:"user_id"`
-	Title     string    `db:"title"`
-	Tags      []string  `db:"tags"`
+	ID               uint64              `db:"id"`
+	URL              string              `db:"url"`
+	UserID           uuid.UUID           `db:"user_id"`
+	WorkspaceID      uuid.UUID           `db:"workspace_id"`
+	Title            string              `db:"title"`
+	Tags             []string            `db:"tags"`
+	Rules            domain.Rules        `db:"rules"`
+	Variants         domain.Variants     `db:"variants"`
+	RedirectType     domain.RedirectType `db:"redirect_type"`
+	PixelURL         string              `db:"pixel_url"`
+	DeepLink         domain.DeepLink     `db:"deep_link"`
+	ExpiresAt        *time.Time          `db:"expires_at"`
+	DeletedAt        *time.Time          `db:"deleted_at"`
+	DisabledAt       *time.Time          `db:"disabled_at"`
+	DisabledReason   string              `db:"disabled_reason"`
+	QuarantinedAt    *time.Time          `db:"quarantined_at"`
+	QuarantineReason string              `db:"quarantine_reason"`
+	ScreenedAt       *time.Time          `db:"screened_at"`
+	Health
+	Preview
+	OpenGraph
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
 type Shortens []Shorten
 
+// Redirect is the part of a shorten the redirect path needs.
+type Redirect struct {
+	URL              string              `db:"url"`
+	Title            string              `db:"title"`
+	RedirectType     domain.RedirectType `db:"redirect_type"`
+	PixelURL         string              `db:"pixel_url"`
+	ExpiresAt        *time.Time          `db:"expires_at"`
+	DisabledAt       *time.Time          `db:"disabled_at"`
+	QuarantinedAt    *time.Time          `db:"quarantined_at"`
+	QuarantineReason string              `db:"quarantine_reason"`
+	Branding         domain.Branding     `db:"branding"`
+	Rules            domain.Rules        `db:"rules"`
+	Variants         domain.Variants     `db:"variants"`
+	DeepLink         domain.DeepLink     `db:"deep_link"`
+	OpenGraph
+}
+
+func (redirect Redirect) Domain() domain.Redirect {
+	res := domain.Redirect{
+		URL:              redirect.URL,
+		Title:            redirect.Title,
+		Type:             redirect.RedirectType,
+		PixelURL:         redirect.PixelURL,
+		Disabled:         redirect.DisabledAt != nil,
+		Quarantined:      redirect.QuarantinedAt != nil,
+		QuarantineReason: redirect.QuarantineReason,
+		OpenGraph:        redirect.OpenGraph.Domain(),
+		Rules:            redirect.Rules,
+		Variants:         redirect.Variants,
+		DeepLink:         redirect.DeepLink,
+		Branding:         redirect.Branding,
+	}
+
+	if redirect.ExpiresAt != nil {
+		res.ExpiresAt = redirect.ExpiresAt.Unix()
+	}
+
+	return res
+}
+
 func (s Shorten) Domain(url string) domain.Shorten {
 	id := base62.Encode(s.ID)
 
-	return domain.Shorten{
-		ID:        id,
-		Title:     s.Title,
-		LongURL:   s.URL,
-		ShortURL:  url + "/" + id,
-		Tags:      s.Tags,
-		CreatedAt: s.CreatedAt.Unix(),
-		UpdatedAt: s.UpdatedAt.Unix(),
+	res := domain.Shorten{
+		ID:               id,
+		WorkspaceID:      s.WorkspaceID,
+		Title:            s.Title,
+		LongURL:          s.URL,
+		ShortURL:         url + "/" + id,
+		Tags:             s.Tags,
+		Rules:            s.Rules,
+		Variants:         s.Variants,
+		RedirectType:     s.RedirectType,
+		PixelURL:         s.PixelURL,
+		DeepLink:         s.DeepLink,
+		Disabled:         s.DisabledAt != nil,
+		DisabledReason:   s.DisabledReason,
+		Quarantined:      s.QuarantinedAt != nil,
+		QuarantineReason: s.QuarantineReason,
+		Health:           s.Health.Domain(),
+		Preview:          s.Preview.Domain(),
+		OpenGraph:        s.OpenGraph.Domain(),
+		CreatedAt:        s.CreatedAt.Unix(),
+		UpdatedAt:        s.UpdatedAt.Unix(),
+	}
+
+	if s.ExpiresAt != nil {
+		expiresAt := s.ExpiresAt.Unix()
+		res.ExpiresAt = &expiresAt
 	}
+
+	if s.DeletedAt != nil {
+		deletedAt := s.DeletedAt.Unix()
+		res.DeletedAt = &deletedAt
+	}
+
+	return res
 }
 
 func (shortens Shortens) Domain(url string) domain.Shortens {
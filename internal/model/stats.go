@@ -3,16 +3,22 @@ package model
 import (
 	"cc/internal/domain"
 	"encoding/json"
+	"github.com/google/uuid"
 	"time"
 )
 
 type Click struct {
-	ShortenID uint64    `db:"shorten_id"`
-	Platform  string    `db:"platform"`
-	OS        string    `db:"os"`
-	Referer   string    `db:"referer"`
-	IP        string    `db:"ip"`
-	Timestamp time.Time `db:"timestamp"`
+	ShortenID uint64     `db:"shorten_id"`
+	Platform  string     `db:"platform"`
+	OS        string     `db:"os"`
+	Referer   string     `db:"referer"`
+	IP        string     `db:"ip"`
+	Source    string     `db:"source"`
+	Rule      string     `db:"rule"`
+	Variant   string     `db:"variant"`
+	Open      string     `db:"open"`
+	BioPageID *uuid.UUID `db:"bio_page_id"`
+	Timestamp time.Time  `db:"timestamp"`
 }
 
 type Clicks []Click
@@ -67,6 +73,10 @@ func (c Click) Domain() domain.Click {
 		Platform:  c.Platform,
 		OS:        c.OS,
 		Referer:   c.Referer,
+		Source:    c.Source,
+		Rule:      c.Rule,
+		Variant:   c.Variant,
+		Open:      c.Open,
 		Timestamp: c.Timestamp,
 	}
 }
@@ -80,3 +90,32 @@ func (m Clicks) Domain() []domain.Click {
 
 	return metrics
 }
+
+// NotFoundHit counts the requests for a key without a shorten from one
+// referer on one day.
+type NotFoundHit struct {
+	Key        string    `db:"key"`
+	Referer    string    `db:"referer"`
+	Day        time.Time `db:"day"`
+	Hits       int64     `db:"hits"`
+	LastSeenAt time.Time `db:"last_seen_at"`
+}
+
+type MissedKey struct {
+	Key        string    `db:"key"`
+	Hits       int64     `db:"hits"`
+	TopReferer string    `db:"top_referer"`
+	LastSeenAt time.Time `db:"last_seen_at"`
+}
+
+type MissedKeys []MissedKey
+
+func (m MissedKeys) Domain() []domain.MissedKey {
+	keys := make([]domain.MissedKey, len(m))
+
+	for i, v := range m {
+		keys[i] = domain.MissedKey(v)
+	}
+
+	return keys
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type TOTP struct {
+	UserID      uuid.UUID  `db:"user_id"`
+	Secret      string     `db:"secret"`
+	LastCounter int64      `db:"last_counter"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ConfirmedAt *time.Time `db:"confirmed_at"`
+}
+
+func (totp TOTP) Confirmed() bool {
+	return totp.ConfirmedAt != nil
+}
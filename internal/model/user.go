@@ -3,18 +3,41 @@ package model
 import (
 	"cc/internal/domain"
 	"github.com/google/uuid"
+	"time"
 )
 
 type User struct {
-	ID       uuid.UUID `db:"id"`
-	Name     string    `db:"name"`
-	Password []byte    `db:"password"`
+	ID        uuid.UUID  `db:"id"`
+	Name      string     `db:"name"`
+	Password  []byte     `db:"password"`
+	Role      string     `db:"role"`
+	BannedAt  *time.Time `db:"banned_at"`
+	BanReason string     `db:"ban_reason"`
 }
 
+type Users []User
+
 func (user User) Domain() domain.User {
-	return domain.User{
-		ID:       user.ID,
-		Name:     user.Name,
-		Password: user.Password,
+	res := domain.User{
+		ID:        user.ID,
+		Name:      user.Name,
+		Password:  user.Password,
+		Role:      domain.UserRole(user.Role),
+		BanReason: user.BanReason,
+	}
+
+	if user.BannedAt != nil {
+		bannedAt := user.BannedAt.Unix()
+		res.BannedAt = &bannedAt
 	}
+
+	return res
+}
+
+type Identity struct {
+	Issuer    string    `db:"issuer"`
+	Subject   string    `db:"subject"`
+	UserID    uuid.UUID `db:"user_id"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
 }
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"cc/internal/domain"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Workspace struct {
+	ID               uuid.UUID       `db:"id"`
+	Name             string          `db:"name"`
+	Personal         bool            `db:"personal"`
+	RequireTwoFactor bool            `db:"require_two_factor"`
+	Branding         domain.Branding `db:"branding"`
+	Role             string          `db:"role"`
+	CreatedAt        time.Time       `db:"created_at"`
+	UpdatedAt        time.Time       `db:"updated_at"`
+}
+
+type Workspaces []Workspace
+
+// Access is what a user may do in a workspace: their role, if any, and
+// whether the workspace's two-factor requirement is met.
+type Access struct {
+	Role             string `db:"role"`
+	RequireTwoFactor bool   `db:"require_two_factor"`
+	TwoFactor        bool   `db:"two_factor"`
+}
+
+type Member struct {
+	WorkspaceID uuid.UUID `db:"workspace_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Name        string    `db:"name"`
+	Role        string    `db:"role"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type Members []Member
+
+type Invitation struct {
+	ID          uuid.UUID  `db:"id"`
+	WorkspaceID uuid.UUID  `db:"workspace_id"`
+	TokenHash   []byte     `db:"token_hash"`
+	Role        string     `db:"role"`
+	CreatedBy   *uuid.UUID `db:"created_by"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ExpiresAt   time.Time  `db:"expires_at"`
+	AcceptedBy  *uuid.UUID `db:"accepted_by"`
+	AcceptedAt  *time.Time `db:"accepted_at"`
+}
+
+type Invitations []Invitation
+
+func (workspace Workspace) Domain() domain.Workspace {
+	return domain.Workspace{
+		ID:               workspace.ID,
+		Name:             workspace.Name,
+		Personal:         workspace.Personal,
+		RequireTwoFactor: workspace.RequireTwoFactor,
+		Branding:         workspace.Branding,
+		Role:             domain.Role(workspace.Role),
+		CreatedAt:        workspace.CreatedAt.Unix(),
+		UpdatedAt:        workspace.UpdatedAt.Unix(),
+	}
+}
+
+func (workspaces Workspaces) Domain() domain.Workspaces {
+	res := make(domain.Workspaces, len(workspaces))
+
+	for i, workspace := range workspaces {
+		res[i] = workspace.Domain()
+	}
+
+	return res
+}
+
+func (member Member) Domain() domain.Member {
+	return domain.Member{
+		UserID:    member.UserID,
+		Name:      member.Name,
+		Role:      domain.Role(member.Role),
+		CreatedAt: member.CreatedAt.Unix(),
+	}
+}
+
+func (members Members) Domain() domain.Members {
+	res := make(domain.Members, len(members))
+
+	for i, member := range members {
+		res[i] = member.Domain()
+	}
+
+	return res
+}
+
+func (invitation Invitation) Domain() domain.Invitation {
+	res := domain.Invitation{
+		ID:          invitation.ID,
+		WorkspaceID: invitation.WorkspaceID,
+		Role:        domain.Role(invitation.Role),
+		CreatedBy:   invitation.CreatedBy,
+		CreatedAt:   invitation.CreatedAt.Unix(),
+		ExpiresAt:   invitation.ExpiresAt.Unix(),
+		AcceptedBy:  invitation.AcceptedBy,
+	}
+
+	if invitation.AcceptedAt != nil {
+		acceptedAt := invitation.AcceptedAt.Unix()
+		res.AcceptedAt = &acceptedAt
+	}
+
+	return res
+}
+
+func (invitations Invitations) Domain() domain.Invitations {
+	res := make(domain.Invitations, len(invitations))
+
+	for i, invitation := range invitations {
+		res[i] = invitation.Domain()
+	}
+
+	return res
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/base62"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+type AdminService interface {
+	Authorize(ctx context.Context, userID uuid.UUID) error
+	PromoteAdmins(ctx context.Context, names []string) error
+
+	SearchShortens(ctx context.Context, request dto.SearchShortens) (domain.Shortens, error)
+	DisableShorten(ctx context.Context, shortenID uint64, request dto.DisableShorten) error
+	EnableShorten(ctx context.Context, shortenID uint64) error
+
+	BanUser(ctx context.Context, adminID, userID uuid.UUID, request dto.BanUser) ([]uint64, error)
+	UnbanUser(ctx context.Context, userID uuid.UUID) error
+	SetUserRole(ctx context.Context, adminID, userID uuid.UUID, request dto.SetUserRole) error
+
+	GetTotals(ctx context.Context) (domain.Totals, error)
+	SelectMissedKeys(ctx context.Context, request dto.SelectMissedKeys) ([]domain.MissedKey, error)
+}
+
+type adminService struct {
+	userStorage    storage.UserStorage
+	shortenStorage storage.ShortenStorage
+	statsStorage   storage.StatsStorage
+	revisions      storage.RevisionStorage
+	tx             storage.Transactor
+	audit          AuditService
+	domainURL      string
+}
+
+func NewAdminService(userStorage storage.UserStorage, shortenStorage storage.ShortenStorage, statsStorage storage.StatsStorage, revisions storage.RevisionStorage, tx storage.Transactor, audit AuditService, domainURL string) AdminService {
+	return &adminService{userStorage: userStorage, shortenStorage: shortenStorage, statsStorage: statsStorage, revisions: revisions, tx: tx, audit: audit, domainURL: domainURL}
+}
+
+// Authorize looks the role up on every request rather than trusting the
+// access token, so revoking admin rights takes effect immediately.
+func (service *adminService) Authorize(ctx context.Context, userID uuid.UUID) (err error) {
+	var usr model.User
+	usr, err = service.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("authorize admin")
+		}
+
+		return apperror.Forbidden.WithMessage("admin role is required")
+	}
+
+	user := usr.Domain()
+	if !user.Admin() || user.Banned() {
+		return apperror.Forbidden.WithMessage("admin role is required")
+	}
+
+	return
+}
+
+func (service *adminService) PromoteAdmins(ctx context.Context, names []string) (err error) {
+	if len(names) == 0 {
+		return
+	}
+
+	var promoted []string
+	promoted, err = service.userStorage.PromoteByNames(ctx, names, string(domain.UserRoleAdmin))
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("promote admins")
+		}
+
+		return
+	}
+
+	// Promotion comes from the config at startup, so there is no actor.
+	for _, name := range promoted {
+		service.record(ctx, nil, domain.AuditAdminPromote, domain.TargetUser, name, nil, map[string]string{"role": string(domain.UserRoleAdmin)})
+	}
+
+	return
+}
+
+func (service *adminService) SearchShortens(ctx context.Context, request dto.SearchShortens) (shortens domain.Shortens, err error) {
+	var shrtns model.Shortens
+	shrtns, err = service.shortenStorage.Search(ctx, request.URL, request.Domain, request.Limit, request.Offset)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shortens, apperr.WithScope("search shortens")
+		}
+
+		return
+	}
+
+	return shrtns.Domain(service.domainURL), nil
+}
+
+func (service *adminService) DisableShorten(ctx context.Context, shortenID uint64, request dto.DisableShorten) (err error) {
+	now := time.Now()
+
+	err = inTx(ctx, service.tx, func(ctx context.Context) error {
+		shrtn, err := service.shortenStorage.GetByID(ctx, shortenID)
+		if err != nil {
+			return err
+		}
+
+		if err = service.shortenStorage.SetDisabled(ctx, shortenID, request.Reason, &now); err != nil {
+			return err
+		}
+
+		return recordRevision(ctx, service.revisions, actorOf(ctx), shrtn, domain.RevisionDisable, toggled("disabled", true, request.Reason))
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("disable shorten")
+		}
+
+		return
+	}
+
+	service.record(ctx, nil, domain.AuditAdminDisableShorten, domain.TargetShorten, base62.Encode(shortenID), nil, map[string]any{"reason": request.Reason, "disabled_at": now})
+
+	return
+}
+
+func (service *adminService) EnableShorten(ctx context.Context, shortenID uint64) (err error) {
+	err = inTx(ctx, service.tx, func(ctx context.Context) error {
+		shrtn, err := service.shortenStorage.GetByID(ctx, shortenID)
+		if err != nil {
+			return err
+		}
+
+		if err = service.shortenStorage.SetDisabled(ctx, shortenID, "", nil); err != nil {
+			return err
+		}
+
+		return recordRevision(ctx, service.revisions, actorOf(ctx), shrtn, domain.RevisionEnable, toggled("disabled", false, ""))
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("enable shorten")
+		}
+
+		return
+	}
+
+	service.record(ctx, nil, domain.AuditAdminEnableShorten, domain.TargetShorten, base62.Encode(shortenID), nil, nil)
+
+	return
+}
+
+// BanUser blocks the account and, on request, disables every link it created.
+// It returns the ids of the links that were disabled.
+func (service *adminService) BanUser(ctx context.Context, adminID, userID uuid.UUID, request dto.BanUser) (disabled []uint64, err error) {
+	if adminID == userID {
+		return nil, apperror.BadRequest.WithMessage("you cannot ban yourself")
+	}
+
+	var usr model.User
+	usr, err = service.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return nil, apperr.WithScope("ban user")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return nil, apperror.NotFound.WithMessage("user with this id does not exist")
+		}
+
+		return
+	}
+
+	if usr.Domain().Admin() {
+		return nil, apperror.BadRequest.WithMessage("admins cannot be banned, revoke the role first")
+	}
+
+	now := time.Now()
+
+	err = inTx(ctx, service.tx, func(ctx context.Context) (err error) {
+		if err = service.userStorage.Ban(ctx, userID, request.Reason, now); err != nil {
+			return
+		}
+
+		if !request.DisableShortens {
+			return
+		}
+
+		disabled, err = service.shortenStorage.DisableByUser(ctx, userID, request.Reason, now)
+		if err != nil || len(disabled) == 0 {
+			return
+		}
+
+		var shrtns model.Shortens
+		shrtns, err = service.shortenStorage.SelectByIDs(ctx, disabled)
+		if err != nil {
+			return
+		}
+
+		for _, shrtn := range shrtns {
+			err = recordRevision(ctx, service.revisions, &adminID, shrtn, domain.RevisionDisable, toggled("disabled", true, request.Reason))
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return nil, apperr.WithScope("ban user")
+		}
+
+		return nil, err
+	}
+
+	keys := make([]string, len(disabled))
+	for i, shortenID := range disabled {
+		keys[i] = base62.Encode(shortenID)
+	}
+
+	service.record(ctx, &adminID, domain.AuditAdminBanUser, domain.TargetUser, userID.String(), nil, map[string]any{
+		"reason":            request.Reason,
+		"banned_at":         now,
+		"disabled_shortens": keys,
+	})
+
+	return
+}
+
+func (service *adminService) UnbanUser(ctx context.Context, userID uuid.UUID) (err error) {
+	err = service.userStorage.Unban(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("unban user")
+		}
+
+		return
+	}
+
+	service.record(ctx, nil, domain.AuditAdminUnbanUser, domain.TargetUser, userID.String(), nil, nil)
+
+	return
+}
+
+func (service *adminService) SetUserRole(ctx context.Context, adminID, userID uuid.UUID, request dto.SetUserRole) (err error) {
+	if adminID == userID && request.Role != domain.UserRoleAdmin {
+		return apperror.BadRequest.WithMessage("you cannot revoke your own admin role")
+	}
+
+	var usr model.User
+	usr, err = service.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("set user role")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return apperror.NotFound.WithMessage("user with this id does not exist")
+		}
+
+		return
+	}
+
+	err = service.userStorage.UpdateRole(ctx, userID, string(request.Role))
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("set user role")
+		}
+
+		return
+	}
+
+	service.record(ctx, &adminID, domain.AuditAdminSetUserRole, domain.TargetUser, userID.String(),
+		map[string]string{"role": usr.Role},
+		map[string]string{"role": string(request.Role)},
+	)
+
+	return
+}
+
+func (service *adminService) GetTotals(ctx context.Context) (totals domain.Totals, err error) {
+	totals.Users, totals.BannedUsers, err = service.userStorage.Count(ctx)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return totals, apperr.WithScope("get totals")
+		}
+
+		return
+	}
+
+	totals.Shortens, totals.DisabledShortens, err = service.shortenStorage.Count(ctx)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return totals, apperr.WithScope("get totals")
+		}
+
+		return
+	}
+
+	totals.Clicks, totals.ClicksLastDay, err = service.statsStorage.CountClicks(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return totals, apperr.WithScope("get totals")
+		}
+
+		return
+	}
+
+	return
+}
+
+func (service *adminService) SelectMissedKeys(ctx context.Context, request dto.SelectMissedKeys) (keys []domain.MissedKey, err error) {
+	since := time.Now().AddDate(0, 0, -request.Days)
+
+	var mssdKeys model.MissedKeys
+	mssdKeys, err = service.statsStorage.SelectMissedKeys(ctx, since, request.Limit)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return keys, apperr.WithScope("select missed keys")
+		}
+
+		return
+	}
+
+	return mssdKeys.Domain(), nil
+}
+
+// record writes an admin action to the audit log. Without an adminID the
+// actor is taken from the request.
+func (service *adminService) record(ctx context.Context, adminID *uuid.UUID, action, targetType, targetID string, before, after any) {
+	if service.audit == nil {
+		return
+	}
+
+	service.audit.Record(ctx, dto.AuditEntry{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	})
+}
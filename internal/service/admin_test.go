@@ -0,0 +1,204 @@
+package service_test
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	st "cc/internal/storage"
+	"cc/mock/storage"
+	"cc/pkg/apperror"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// accountStorage keeps users by id, the way admins look them up.
+type accountStorage struct {
+	st.UserStorage
+	users map[uuid.UUID]model.User
+}
+
+func (storage *accountStorage) GetByID(_ context.Context, id uuid.UUID) (model.User, error) {
+	usr, ok := storage.users[id]
+	if !ok {
+		return model.User{}, apperror.NotFound
+	}
+
+	return usr, nil
+}
+
+func (storage *accountStorage) Ban(_ context.Context, id uuid.UUID, reason string, now time.Time) error {
+	usr := storage.users[id]
+	usr.BannedAt, usr.BanReason = &now, reason
+	storage.users[id] = usr
+	return nil
+}
+
+func (storage *accountStorage) Unban(_ context.Context, id uuid.UUID) error {
+	usr := storage.users[id]
+	usr.BannedAt, usr.BanReason = nil, ""
+	storage.users[id] = usr
+	return nil
+}
+
+func TestAdminService_BanUser(t *testing.T) {
+	admin := model.User{ID: uuid.New(), Name: "admin", Role: string(domain.UserRoleAdmin)}
+	otherAdmin := model.User{ID: uuid.New(), Name: "other", Role: string(domain.UserRoleAdmin)}
+	user := model.User{ID: uuid.New(), Name: "spammer", Role: string(domain.UserRoleUser)}
+
+	tests := []struct {
+		name      string
+		userID    uuid.UUID
+		request   dto.BanUser
+		revisions revisionStorage
+		want      []uint64
+		err       apperror.Error
+	}{
+		{
+			name:    "ban",
+			userID:  user.ID,
+			request: dto.BanUser{Reason: "spam"},
+		},
+		{
+			name:    "ban and disable shortens",
+			userID:  user.ID,
+			request: dto.BanUser{Reason: "spam", DisableShortens: true},
+			want:    []uint64{1, 2},
+		},
+		{
+			name:      "failed revision",
+			userID:    user.ID,
+			request:   dto.BanUser{Reason: "spam", DisableShortens: true},
+			revisions: revisionStorage{fail: true},
+			err:       apperror.Internal,
+		},
+		{
+			name:    "yourself",
+			userID:  admin.ID,
+			request: dto.BanUser{Reason: "spam"},
+			err:     apperror.BadRequest,
+		},
+		{
+			name:    "another admin",
+			userID:  otherAdmin.ID,
+			request: dto.BanUser{Reason: "spam"},
+			err:     apperror.BadRequest,
+		},
+		{
+			name:    "unknown user",
+			userID:  uuid.New(),
+			request: dto.BanUser{Reason: "spam"},
+			err:     apperror.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &accountStorage{users: map[uuid.UUID]model.User{admin.ID: admin, otherAdmin.ID: otherAdmin, user.ID: user}}
+			shortenStorage := &storage.ShortenStorageMock{
+				DisableByUserFunc: func(context.Context, uuid.UUID, string, time.Time) ([]uint64, error) {
+					return []uint64{1, 2}, nil
+				},
+				SelectByIDsFunc: func(_ context.Context, ids []uint64) (model.Shortens, error) {
+					shrtns := make(model.Shortens, len(ids))
+					for i, id := range ids {
+						shrtns[i] = model.Shorten{ID: id, URL: "https://example.com"}
+					}
+					return shrtns, nil
+				},
+			}
+			audit := &auditStorage{}
+			committed := false
+
+			adminService := service.NewAdminService(users, shortenStorage, nil, tt.revisions, transactor{committed: &committed}, service.NewAuditService(audit), domainURL)
+
+			disabled, err := adminService.BanUser(context.Background(), admin.ID, tt.userID, tt.request)
+			if tt.err.Code == 0 {
+				assert.NoError(t, err)
+				assert.True(t, committed)
+				assert.Equal(t, tt.want, disabled)
+				assert.True(t, users.users[tt.userID].Domain().Banned())
+				assert.Equal(t, tt.request.Reason, users.users[tt.userID].BanReason)
+				if !tt.request.DisableShortens {
+					assert.Empty(t, shortenStorage.DisableByUserCalls())
+				}
+
+				if assert.Len(t, audit.records, 1) {
+					assert.Equal(t, domain.AuditAdminBanUser, audit.records[0].Action)
+					assert.Equal(t, &admin.ID, audit.records[0].ActorID)
+				}
+				return
+			}
+
+			_, ok := apperror.Is(err, tt.err)
+			assert.True(t, ok, err)
+			assert.Empty(t, disabled)
+			assert.False(t, committed)
+			assert.Empty(t, audit.records)
+		})
+	}
+}
+
+func TestAdminService_UnbanUser(t *testing.T) {
+	bannedAt := time.Now().Add(-time.Hour)
+	user := model.User{ID: uuid.New(), Name: "spammer", BannedAt: &bannedAt, BanReason: "spam"}
+
+	users := &accountStorage{users: map[uuid.UUID]model.User{user.ID: user}}
+	audit := &auditStorage{}
+
+	adminService := service.NewAdminService(users, nil, nil, nil, nil, service.NewAuditService(audit), domainURL)
+
+	assert.NoError(t, adminService.UnbanUser(context.Background(), user.ID))
+	assert.False(t, users.users[user.ID].Domain().Banned())
+	assert.Empty(t, users.users[user.ID].BanReason)
+
+	if assert.Len(t, audit.records, 1) {
+		assert.Equal(t, domain.AuditAdminUnbanUser, audit.records[0].Action)
+	}
+}
+
+func TestAdminService_DisableShorten(t *testing.T) {
+	ctx := context.Background()
+
+	var disabledAt *time.Time
+	reason := ""
+	shortenStorage := &storage.ShortenStorageMock{
+		GetByIDFunc: func(_ context.Context, id uint64) (model.Shorten, error) {
+			if id != 1 {
+				return model.Shorten{}, apperror.NotFound
+			}
+			return model.Shorten{ID: id, URL: "https://example.com"}, nil
+		},
+		SetDisabledFunc: func(_ context.Context, _ uint64, r string, at *time.Time) error {
+			reason, disabledAt = r, at
+			return nil
+		},
+	}
+	audit := &auditStorage{}
+	committed := false
+
+	adminService := service.NewAdminService(nil, shortenStorage, nil, revisionStorage{}, transactor{committed: &committed}, service.NewAuditService(audit), domainURL)
+
+	assert.NoError(t, adminService.DisableShorten(ctx, 1, dto.DisableShorten{Reason: "phishing"}))
+	assert.True(t, committed)
+	assert.NotNil(t, disabledAt)
+	assert.Equal(t, "phishing", reason)
+
+	assert.NoError(t, adminService.EnableShorten(ctx, 1))
+	assert.Nil(t, disabledAt)
+	assert.Empty(t, reason)
+
+	if assert.Len(t, audit.records, 2) {
+		assert.Equal(t, domain.AuditAdminDisableShorten, audit.records[0].Action)
+		assert.Equal(t, domain.AuditAdminEnableShorten, audit.records[1].Action)
+	}
+
+	err := adminService.DisableShorten(ctx, 2, dto.DisableShorten{Reason: "phishing"})
+	_, ok := apperror.Is(err, apperror.NotFound)
+	assert.True(t, ok)
+	assert.False(t, committed)
+	assert.Len(t, shortenStorage.SetDisabledCalls(), 2)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"time"
+)
+
+// OriginKey is where middleware.Origin keeps the request's Origin. Handlers
+// pass their gin context on to services, so it can be read from there.
+const OriginKey = "origin"
+
+// Origin is the client a request came from.
+type Origin struct {
+	IP        string
+	UserAgent string
+}
+
+const auditExportBatch = 1000
+
+type AuditService interface {
+	// Record appends an entry to the audit log. Failures are logged rather
+	// than returned, since the audited change has already been made.
+	Record(ctx context.Context, entry dto.AuditEntry)
+	Select(ctx context.Context, request dto.SelectAudit) ([]domain.AuditRecord, error)
+	// Export writes every matching record to w as JSON Lines, newest first,
+	// ignoring the limit and offset of the request.
+	Export(ctx context.Context, request dto.SelectAudit, w io.Writer) error
+}
+
+type auditService struct {
+	storage storage.AuditStorage
+}
+
+func NewAuditService(storage storage.AuditStorage) AuditService {
+	return &auditService{storage: storage}
+}
+
+func (service *auditService) Record(ctx context.Context, entry dto.AuditEntry) {
+	if origin, ok := ctx.Value(OriginKey).(Origin); ok {
+		if entry.IP == "" {
+			entry.IP = origin.IP
+		}
+		if entry.UserAgent == "" {
+			entry.UserAgent = origin.UserAgent
+		}
+	}
+
+	if entry.ActorID == nil {
+		entry.ActorID = actorOf(ctx)
+	}
+
+	record := model.AuditRecord{
+		ActorID:    entry.ActorID,
+		IP:         entry.IP,
+		UserAgent:  entry.UserAgent,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		CreatedAt:  time.Now(),
+	}
+
+	var err error
+	if record.Before, err = payload(entry.Before); err != nil {
+		log.Println(err)
+	}
+	if record.After, err = payload(entry.After); err != nil {
+		log.Println(err)
+	}
+
+	// The request may be cancelled as soon as the response is written, which
+	// must not lose the record.
+	err = service.storage.Create(context.Background(), record)
+	if err != nil {
+		log.Println(apperror.Internal.WithError(err).WithScope("auditService.Record"))
+	}
+}
+
+// actorOf returns the signed-in user of the request ctx belongs to, if any.
+func actorOf(ctx context.Context) *uuid.UUID {
+	if userID, ok := ctx.Value("user_id").(uuid.UUID); ok {
+		return &userID
+	}
+
+	return nil
+}
+
+func payload(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
+
+func (service *auditService) Select(ctx context.Context, request dto.SelectAudit) (records []domain.AuditRecord, err error) {
+	var rcrds model.AuditRecords
+	rcrds, err = service.storage.Select(ctx, auditFilter(request))
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return records, apperr.WithScope("auditService.Select")
+		}
+
+		return
+	}
+
+	return rcrds.Domain(), nil
+}
+
+func (service *auditService) Export(ctx context.Context, request dto.SelectAudit, w io.Writer) error {
+	filter := auditFilter(request)
+	filter.Limit, filter.Offset = auditExportBatch, 0
+
+	encoder := json.NewEncoder(w)
+
+	for {
+		records, err := service.storage.Select(ctx, filter)
+		if err != nil {
+			if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+				return apperr.WithScope("auditService.Export")
+			}
+
+			return err
+		}
+
+		for _, record := range records {
+			if err = encoder.Encode(record.Domain()); err != nil {
+				return err
+			}
+		}
+
+		if len(records) < filter.Limit {
+			return nil
+		}
+
+		filter.BeforeID = records[len(records)-1].ID
+	}
+}
+
+// auditFilter expects a validated request.
+func auditFilter(request dto.SelectAudit) model.AuditFilter {
+	filter := model.AuditFilter{
+		Action:     request.Action,
+		TargetType: request.TargetType,
+		TargetID:   request.TargetID,
+		Limit:      request.Limit,
+		Offset:     request.Offset,
+	}
+
+	if actorID, err := uuid.Parse(request.ActorID); err == nil {
+		filter.ActorID = &actorID
+	}
+
+	if from, err := time.Parse("2006-01-02", request.From); err == nil {
+		filter.From = &from
+	}
+
+	if to, err := time.Parse("2006-01-02", request.To); err == nil {
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	return filter
+}
@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"bytes"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type auditStorage struct {
+	records model.AuditRecords
+	filters []model.AuditFilter
+}
+
+func (storage *auditStorage) Create(_ context.Context, record model.AuditRecord) error {
+	record.ID = int64(len(storage.records) + 1)
+	storage.records = append(storage.records, record)
+	return nil
+}
+
+func (storage *auditStorage) Select(_ context.Context, filter model.AuditFilter) (model.AuditRecords, error) {
+	storage.filters = append(storage.filters, filter)
+
+	var records model.AuditRecords
+	for i := len(storage.records) - 1; i >= 0 && len(records) < filter.Limit; i-- {
+		if filter.BeforeID == 0 || storage.records[i].ID < filter.BeforeID {
+			records = append(records, storage.records[i])
+		}
+	}
+
+	return records, nil
+}
+
+func TestAuditService_Record(t *testing.T) {
+	userID := uuid.New()
+
+	ctx := context.WithValue(context.Background(), "user_id", userID)
+	ctx = context.WithValue(ctx, service.OriginKey, service.Origin{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+
+	storage := &auditStorage{}
+	service.NewAuditService(storage).Record(ctx, dto.AuditEntry{
+		Action:     "shorten.update",
+		TargetType: "shorten",
+		TargetID:   "abc",
+		Before:     map[string]string{"url": "https://a.example"},
+		After:      map[string]string{"url": "https://b.example"},
+	})
+
+	if assert.Len(t, storage.records, 1) {
+		record := storage.records[0]
+		assert.Equal(t, &userID, record.ActorID)
+		assert.Equal(t, "203.0.113.7", record.IP)
+		assert.Equal(t, "curl/8.0", record.UserAgent)
+		assert.JSONEq(t, `{"url":"https://a.example"}`, string(record.Before))
+		assert.JSONEq(t, `{"url":"https://b.example"}`, string(record.After))
+	}
+}
+
+func TestAuditService_Export(t *testing.T) {
+	storage := &auditStorage{}
+	audit := service.NewAuditService(storage)
+	for i := 0; i < 2500; i++ {
+		audit.Record(context.Background(), dto.AuditEntry{Action: "user.sign_up"})
+	}
+
+	var buf bytes.Buffer
+	err := audit.Export(context.Background(), dto.SelectAudit{Limit: 10, Offset: 5}, &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2500, strings.Count(buf.String(), "\n"))
+	assert.Len(t, storage.filters, 3)
+	assert.Equal(t, int64(501), storage.filters[2].BeforeID)
+	assert.Zero(t, storage.filters[2].Offset)
+}
@@ -7,41 +7,81 @@ import (
 	"cc/internal/model"
 	"cc/internal/storage"
 	"cc/pkg/apperror"
+	"cc/pkg/jwks"
+	"cc/pkg/lru"
 	"context"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+	"github.com/mileusna/useragent"
+	"github.com/pkg/errors"
 	"time"
 )
 
 type AuthService interface {
-	CreateSession(ctx context.Context, userID uuid.UUID, ip string) (domain.Session, error)
-	UpdateSession(ctx context.Context, request dto.Refresh) (domain.Session, error)
+	CreateSession(ctx context.Context, userID uuid.UUID, ip, userAgent string) (domain.Session, error)
+	UpdateSession(ctx context.Context, request dto.Refresh, ip, userAgent string) (domain.Session, error)
+	RevokeSession(ctx context.Context, request dto.Refresh) error
+	RevokeSessionByID(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeSessions(ctx context.Context, userID, except uuid.UUID) error
+	SelectDevices(ctx context.Context, userID, current uuid.UUID) (domain.Devices, error)
 	ParseToken(token string) (*jwt.Token, error)
+	// Authenticate rejects access tokens whose session has been revoked or
+	// has expired, or whose user is banned. Active sessions are remembered
+	// for config.Auth.SessionCacheTTL.
+	Authenticate(ctx context.Context, userID, sessionID uuid.UUID) error
+	JWKS() jwks.Set
 }
 
+const activeSessionsSize = 10000
+
 type authService struct {
 	storage storage.AuthStorage
 	config  config.Auth
+	keyring *jwks.Keyring
+	audit   AuditService
+	// active maps the ids of sessions found active to their users.
+	active *lru.Cache[uuid.UUID, uuid.UUID]
 }
 
-func NewAuthService(storage storage.AuthStorage, config config.Auth) AuthService {
-	return &authService{storage: storage, config: config}
+func NewAuthService(storage storage.AuthStorage, config config.Auth, keyring *jwks.Keyring, audit AuditService) AuthService {
+	return &authService{
+		storage: storage,
+		config:  config,
+		keyring: keyring,
+		audit:   audit,
+		active:  lru.New[uuid.UUID, uuid.UUID](activeSessionsSize, config.SessionCacheTTL),
+	}
 }
 
-func (service *authService) CreateSession(ctx context.Context, userID uuid.UUID, ip string) (session domain.Session, err error) {
+func (service *authService) CreateSession(ctx context.Context, userID uuid.UUID, ip, userAgent string) (session domain.Session, err error) {
 	now := time.Now()
 
-	var accessToken string
-	accessToken, err = createToken(userID, service.config.SigningKey, now.Add(service.config.ExpirationAt))
+	err = service.storage.DeleteExpiredSessions(ctx, userID, now)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return session, apperr.WithScope("create session")
+		}
+
+		return
+	}
 
 	sssn := model.Session{
 		ID:           uuid.New(),
 		UserID:       userID,
 		RefreshToken: uuid.New(),
 		IP:           ip,
+		UserAgent:    userAgent,
 		CreatedAt:    now,
 		UpdatedAt:    now,
+		ExpiresAt:    now.Add(service.config.RefreshExpirationAt),
+	}
+
+	var accessToken string
+	accessToken, err = service.createToken(sssn.UserID, sssn.ID, now.Add(service.config.ExpirationAt))
+	if err != nil {
+		return
 	}
+
 	err = service.storage.CreateSession(ctx, sssn)
 	if err != nil {
 		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
@@ -51,6 +91,8 @@ func (service *authService) CreateSession(ctx context.Context, userID uuid.UUID,
 		return
 	}
 
+	service.auditSession(ctx, domain.AuditSignIn, sssn, nil)
+
 	session = domain.Session{
 		UserID:       sssn.UserID,
 		AccessToken:  accessToken,
@@ -60,7 +102,9 @@ func (service *authService) CreateSession(ctx context.Context, userID uuid.UUID,
 	return
 }
 
-func (service *authService) UpdateSession(ctx context.Context, request dto.Refresh) (session domain.Session, err error) {
+func (service *authService) UpdateSession(ctx context.Context, request dto.Refresh, ip, userAgent string) (session domain.Session, err error) {
+	now := time.Now()
+
 	var sssn model.Session
 	sssn, err = service.storage.GetSessionByRefreshToken(ctx, request.RefreshToken)
 	if err != nil {
@@ -68,29 +112,50 @@ func (service *authService) UpdateSession(ctx context.Context, request dto.Refre
 			return session, apperr.WithScope("update session")
 		}
 
+		if errors.Is(err, apperror.NotFound) {
+			return session, service.detectReuse(ctx, request.RefreshToken, now)
+		}
+
 		return
 	}
 
-	now := time.Now()
+	if sssn.RevokedAt != nil {
+		return session, apperror.Unauthorized.WithMessage("session has been revoked")
+	}
+
+	if now.After(sssn.ExpiresAt) {
+		return session, apperror.Unauthorized.WithMessage("refresh token has expired")
+	}
 
 	var accessToken string
-	accessToken, err = createToken(sssn.UserID, service.config.SigningKey, now.Add(service.config.ExpirationAt))
+	accessToken, err = service.createToken(sssn.UserID, sssn.ID, now.Add(service.config.ExpirationAt))
 	if err != nil {
 		return
 	}
 
+	before := sssn
+
 	sssn.RefreshToken = uuid.New()
+	sssn.IP = ip
+	sssn.UserAgent = userAgent
 	sssn.UpdatedAt = now
+	sssn.ExpiresAt = now.Add(service.config.RefreshExpirationAt)
 
-	err = service.storage.UpdateSession(ctx, sssn)
+	err = service.storage.RotateSession(ctx, sssn, request.RefreshToken)
 	if err != nil {
 		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
 			return session, apperr.WithScope("update session")
 		}
 
+		if errors.Is(err, apperror.NotFound) {
+			return session, service.detectReuse(ctx, request.RefreshToken, now)
+		}
+
 		return
 	}
 
+	service.auditSession(ctx, domain.AuditSessionRefresh, sssn, &before)
+
 	session = domain.Session{
 		UserID:       sssn.UserID,
 		AccessToken:  accessToken,
@@ -100,10 +165,136 @@ func (service *authService) UpdateSession(ctx context.Context, request dto.Refre
 	return
 }
 
+// detectReuse is called when a refresh token no longer matches a live session.
+// If the token was already rotated, someone is replaying it, so the whole
+// session family is revoked.
+func (service *authService) detectReuse(ctx context.Context, refreshToken uuid.UUID, now time.Time) error {
+	sssn, err := service.storage.GetSessionByUsedRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("detect refresh token reuse")
+		}
+
+		return apperror.Unauthorized.WithMessage("invalid refresh token")
+	}
+
+	_, err = service.storage.RevokeSession(ctx, sssn.UserID, sssn.ID, now)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("detect refresh token reuse")
+		}
+
+		return err
+	}
+
+	service.active.Delete(sssn.ID)
+
+	return apperror.Unauthorized.WithMessage("refresh token reuse detected, session has been revoked")
+}
+
+func (service *authService) RevokeSession(ctx context.Context, request dto.Refresh) (err error) {
+	var sssn model.Session
+	sssn, err = service.storage.GetSessionByRefreshToken(ctx, request.RefreshToken)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("revoke session")
+		}
+
+		return apperror.Unauthorized.WithMessage("invalid refresh token")
+	}
+
+	_, err = service.storage.RevokeSession(ctx, sssn.UserID, sssn.ID, time.Now())
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("revoke session")
+		}
+
+		return
+	}
+
+	service.active.Delete(sssn.ID)
+	service.auditRevoke(ctx, domain.AuditSessionRevoke, &sssn.UserID, domain.TargetSession, sssn.ID.String())
+
+	return
+}
+
+func (service *authService) RevokeSessionByID(ctx context.Context, userID, sessionID uuid.UUID) (err error) {
+	var revoked bool
+	revoked, err = service.storage.RevokeSession(ctx, userID, sessionID, time.Now())
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("revoke session by id")
+		}
+
+		return
+	}
+
+	if !revoked {
+		return apperror.NotFound.WithMessage("session with this id does not exist")
+	}
+
+	service.active.Delete(sessionID)
+	service.auditRevoke(ctx, domain.AuditSessionRevoke, &userID, domain.TargetSession, sessionID.String())
+
+	return
+}
+
+func (service *authService) RevokeSessions(ctx context.Context, userID, except uuid.UUID) (err error) {
+	var sessionIDs []uuid.UUID
+	sessionIDs, err = service.storage.RevokeSessions(ctx, userID, except, time.Now())
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("revoke sessions")
+		}
+
+		return
+	}
+
+	service.active.Delete(sessionIDs...)
+
+	// Admins revoke sessions of other users, so the actor comes from the
+	// request.
+	service.auditRevoke(ctx, domain.AuditSessionRevokeAll, nil, domain.TargetUser, userID.String())
+
+	return
+}
+
+func (service *authService) SelectDevices(ctx context.Context, userID, current uuid.UUID) (devices domain.Devices, err error) {
+	var sssns model.Sessions
+	sssns, err = service.storage.SelectActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return devices, apperr.WithScope("select devices")
+		}
+
+		return
+	}
+
+	devices = make(domain.Devices, len(sssns))
+	for i, sssn := range sssns {
+		userAgent := useragent.Parse(sssn.UserAgent)
+
+		devices[i] = domain.Device{
+			ID:        sssn.ID,
+			IP:        sssn.IP,
+			UserAgent: sssn.UserAgent,
+			Platform:  platformOf(userAgent),
+			OS:        userAgent.OS,
+			Browser:   userAgent.Name,
+			Current:   sssn.ID == current,
+			CreatedAt: sssn.CreatedAt.Unix(),
+			UpdatedAt: sssn.UpdatedAt.Unix(),
+			ExpiresAt: sssn.ExpiresAt.Unix(),
+		}
+	}
+
+	return
+}
+
 func (service *authService) ParseToken(payload string) (token *jwt.Token, err error) {
-	token, err = jwt.ParseWithClaims(payload, &domain.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(service.config.SigningKey), nil
-	})
+	token, err = jwt.ParseWithClaims(payload, &domain.Claims{}, service.keyring.Keyfunc,
+		jwt.WithValidMethods(service.keyring.Methods()),
+	)
 	if err != nil {
 		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
 			return token, apperr.WithScope("parse token")
@@ -115,24 +306,84 @@ func (service *authService) ParseToken(payload string) (token *jwt.Token, err er
 	return
 }
 
-func createToken(userID uuid.UUID, signingKey string, expirationTime time.Time) (accessToken string, err error) {
+func (service *authService) Authenticate(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if cached, ok := service.active.Get(sessionID); ok && cached == userID {
+		return nil
+	}
+
+	active, err := service.storage.IsSessionActive(ctx, userID, sessionID, time.Now())
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("authenticate")
+		}
+
+		return err
+	}
+
+	if !active {
+		return apperror.Unauthorized.WithMessage("session has been revoked")
+	}
+
+	service.active.Set(sessionID, userID)
+
+	return nil
+}
+
+func (service *authService) JWKS() jwks.Set {
+	return service.keyring.Set()
+}
+
+func (service *authService) createToken(userID, sessionID uuid.UUID, expirationTime time.Time) (accessToken string, err error) {
 	claims := domain.Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	accessToken, err = token.SignedString([]byte(signingKey))
+	accessToken, err = service.keyring.Sign(claims)
 	if err != nil {
-		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
-			return accessToken, apperr.WithScope("create token")
-		}
+		return accessToken, apperror.Internal.WithError(err).WithScope("create token")
+	}
+
+	return
+}
 
+// auditSession records a sign-in or refresh. Tokens are left out of the
+// payload, only where the session was used from is kept.
+func (service *authService) auditSession(ctx context.Context, action string, sssn model.Session, before *model.Session) {
+	if service.audit == nil {
 		return
 	}
 
-	return
+	entry := dto.AuditEntry{
+		ActorID:    &sssn.UserID,
+		IP:         sssn.IP,
+		UserAgent:  sssn.UserAgent,
+		Action:     action,
+		TargetType: domain.TargetSession,
+		TargetID:   sssn.ID.String(),
+		After:      map[string]string{"ip": sssn.IP, "user_agent": sssn.UserAgent},
+	}
+	if before != nil {
+		entry.Before = map[string]string{"ip": before.IP, "user_agent": before.UserAgent}
+	}
+
+	service.audit.Record(ctx, entry)
+}
+
+// auditRevoke records sessions being signed out. actorID is nil when it
+// should be taken from the request.
+func (service *authService) auditRevoke(ctx context.Context, action string, actorID *uuid.UUID, targetType, targetID string) {
+	if service.audit == nil {
+		return
+	}
+
+	service.audit.Record(ctx, dto.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+	})
 }
@@ -0,0 +1,235 @@
+package service_test
+
+import (
+	"cc/internal/config"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	st "cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/jwks"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// authStorage keeps sessions in memory. Rotated refresh tokens are
+// remembered the way the sessions table keeps previous tokens.
+type authStorage struct {
+	st.AuthStorage
+	sessions map[uuid.UUID]model.Session
+	used     map[uuid.UUID]uuid.UUID
+	banned   map[uuid.UUID]bool
+	lookups  int
+}
+
+func newAuthStorage() *authStorage {
+	return &authStorage{
+		sessions: make(map[uuid.UUID]model.Session),
+		used:     make(map[uuid.UUID]uuid.UUID),
+		banned:   make(map[uuid.UUID]bool),
+	}
+}
+
+func (storage *authStorage) DeleteExpiredSessions(context.Context, uuid.UUID, time.Time) error {
+	return nil
+}
+
+func (storage *authStorage) CreateSession(_ context.Context, session model.Session) error {
+	storage.sessions[session.ID] = session
+	return nil
+}
+
+func (storage *authStorage) RotateSession(_ context.Context, session model.Session, previousRefreshToken uuid.UUID) error {
+	storage.sessions[session.ID] = session
+	storage.used[previousRefreshToken] = session.ID
+	return nil
+}
+
+func (storage *authStorage) GetSessionByRefreshToken(_ context.Context, refreshToken uuid.UUID) (model.Session, error) {
+	for _, session := range storage.sessions {
+		if session.RefreshToken == refreshToken {
+			return session, nil
+		}
+	}
+
+	return model.Session{}, apperror.NotFound
+}
+
+func (storage *authStorage) GetSessionByUsedRefreshToken(_ context.Context, refreshToken uuid.UUID) (model.Session, error) {
+	sessionID, ok := storage.used[refreshToken]
+	if !ok {
+		return model.Session{}, apperror.NotFound
+	}
+
+	return storage.sessions[sessionID], nil
+}
+
+func (storage *authStorage) RevokeSession(_ context.Context, userID uuid.UUID, sessionID uuid.UUID, now time.Time) (bool, error) {
+	session, ok := storage.sessions[sessionID]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return false, nil
+	}
+
+	session.RevokedAt = &now
+	storage.sessions[sessionID] = session
+
+	return true, nil
+}
+
+func (storage *authStorage) RevokeSessions(_ context.Context, userID uuid.UUID, except uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for id, session := range storage.sessions {
+		if session.UserID != userID || id == except || session.RevokedAt != nil {
+			continue
+		}
+
+		session.RevokedAt = &now
+		storage.sessions[id] = session
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func (storage *authStorage) IsSessionActive(_ context.Context, userID uuid.UUID, sessionID uuid.UUID, now time.Time) (bool, error) {
+	storage.lookups++
+
+	session, ok := storage.sessions[sessionID]
+
+	return ok && session.UserID == userID && session.RevokedAt == nil && now.Before(session.ExpiresAt) && !storage.banned[userID], nil
+}
+
+func newAuthService(t *testing.T, storage st.AuthStorage, cfg config.Auth) service.AuthService {
+	keyring, err := jwks.NewKeyring(jwks.NewHMACKey([]byte("secret")))
+	assert.NoError(t, err)
+
+	cfg.ExpirationAt = time.Minute
+	if cfg.RefreshExpirationAt == 0 {
+		cfg.RefreshExpirationAt = time.Hour
+	}
+
+	return service.NewAuthService(storage, cfg, keyring, nil)
+}
+
+func TestAuthService_UpdateSession(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("reuse revokes the session", func(t *testing.T) {
+		authService := newAuthService(t, newAuthStorage(), config.Auth{})
+
+		session, err := authService.CreateSession(ctx, userID, "127.0.0.1", "test")
+		assert.NoError(t, err)
+
+		refreshed, err := authService.UpdateSession(ctx, dto.Refresh{RefreshToken: session.RefreshToken}, "127.0.0.1", "test")
+		assert.NoError(t, err)
+		assert.NotEqual(t, session.RefreshToken, refreshed.RefreshToken)
+
+		_, err = authService.UpdateSession(ctx, dto.Refresh{RefreshToken: session.RefreshToken}, "127.0.0.1", "test")
+		apperr, ok := apperror.Is(err, apperror.Unauthorized)
+		assert.True(t, ok)
+		assert.Contains(t, apperr.Message, "reuse")
+
+		// The token issued by the legitimate refresh dies with the session.
+		_, err = authService.UpdateSession(ctx, dto.Refresh{RefreshToken: refreshed.RefreshToken}, "127.0.0.1", "test")
+		apperr, ok = apperror.Is(err, apperror.Unauthorized)
+		assert.True(t, ok)
+		assert.Contains(t, apperr.Message, "revoked")
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		authService := newAuthService(t, newAuthStorage(), config.Auth{})
+
+		_, err := authService.UpdateSession(ctx, dto.Refresh{RefreshToken: uuid.New()}, "127.0.0.1", "test")
+		_, ok := apperror.Is(err, apperror.Unauthorized)
+		assert.True(t, ok)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		authService := newAuthService(t, newAuthStorage(), config.Auth{RefreshExpirationAt: -time.Minute})
+
+		session, err := authService.CreateSession(ctx, userID, "127.0.0.1", "test")
+		assert.NoError(t, err)
+
+		_, err = authService.UpdateSession(ctx, dto.Refresh{RefreshToken: session.RefreshToken}, "127.0.0.1", "test")
+		apperr, ok := apperror.Is(err, apperror.Unauthorized)
+		assert.True(t, ok)
+		assert.Contains(t, apperr.Message, "expired")
+	})
+}
+
+func TestAuthService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	storage := newAuthStorage()
+	authService := newAuthService(t, storage, config.Auth{SessionCacheTTL: time.Minute})
+
+	session, err := authService.CreateSession(ctx, userID, "127.0.0.1", "test")
+	assert.NoError(t, err)
+
+	var sessionID uuid.UUID
+	for id := range storage.sessions {
+		sessionID = id
+	}
+
+	assert.NoError(t, authService.Authenticate(ctx, userID, sessionID))
+	assert.NoError(t, authService.Authenticate(ctx, userID, sessionID))
+	assert.Equal(t, 1, storage.lookups, "active sessions are cached")
+
+	_, ok := apperror.Is(authService.Authenticate(ctx, uuid.New(), sessionID), apperror.Unauthorized)
+	assert.True(t, ok, "session of another user")
+
+	assert.NoError(t, authService.RevokeSession(ctx, dto.Refresh{RefreshToken: session.RefreshToken}))
+	_, ok = apperror.Is(authService.Authenticate(ctx, userID, sessionID), apperror.Unauthorized)
+	assert.True(t, ok, "revoked session")
+
+	t.Run("all sessions revoked", func(t *testing.T) {
+		storage := newAuthStorage()
+		authService := newAuthService(t, storage, config.Auth{SessionCacheTTL: time.Minute})
+
+		for i := 0; i < 3; i++ {
+			_, err := authService.CreateSession(ctx, userID, "127.0.0.1", "test")
+			assert.NoError(t, err)
+		}
+
+		var current uuid.UUID
+		for id := range storage.sessions {
+			current = id
+			assert.NoError(t, authService.Authenticate(ctx, userID, id))
+		}
+
+		assert.NoError(t, authService.RevokeSessions(ctx, userID, current))
+
+		for id := range storage.sessions {
+			err := authService.Authenticate(ctx, userID, id)
+			if id == current {
+				assert.NoError(t, err)
+				continue
+			}
+
+			_, ok := apperror.Is(err, apperror.Unauthorized)
+			assert.True(t, ok, "revoked sessions are evicted from the cache")
+		}
+	})
+
+	t.Run("banned", func(t *testing.T) {
+		storage := newAuthStorage()
+		authService := newAuthService(t, storage, config.Auth{})
+
+		_, err := authService.CreateSession(ctx, userID, "127.0.0.1", "test")
+		assert.NoError(t, err)
+
+		var sessionID uuid.UUID
+		for id := range storage.sessions {
+			sessionID = id
+		}
+
+		storage.banned[userID] = true
+		_, ok := apperror.Is(authService.Authenticate(ctx, userID, sessionID), apperror.Unauthorized)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/base62"
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type BioService interface {
+	Get(ctx context.Context, userID uuid.UUID) (domain.BioPage, error)
+	Save(ctx context.Context, userID uuid.UUID, request dto.SaveBioPage) (domain.BioPage, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+	GetStats(ctx context.Context, userID uuid.UUID, request dto.GetBioStats) ([]domain.BioLinkStats, error)
+
+	// GetPublic returns the page as visitors see it, with inactive links
+	// left out.
+	GetPublic(ctx context.Context, name string) (domain.BioPage, error)
+	// GetLink resolves a link clicked on a page. Keys that are not on the
+	// page are not found, so clicks cannot be attributed to other pages.
+	GetLink(ctx context.Context, name, key string) (pageID uuid.UUID, shortenID uint64, err error)
+}
+
+type bioService struct {
+	storage        storage.BioStorage
+	shortenStorage storage.ShortenStorage
+	authorizer     Authorizer
+	audit          AuditService
+	domainURL      string
+}
+
+func NewBioService(storage storage.BioStorage, shortenStorage storage.ShortenStorage, authorizer Authorizer, audit AuditService, domainURL string) BioService {
+	return &bioService{storage: storage, shortenStorage: shortenStorage, authorizer: authorizer, audit: audit, domainURL: domainURL}
+}
+
+func (service *bioService) Get(ctx context.Context, userID uuid.UUID) (page domain.BioPage, err error) {
+	var pg model.BioPage
+	pg, err = service.storage.GetByUser(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return page, apperr.WithScope("bioService.Get")
+		}
+
+		return page, apperror.NotFound.WithMessage("you have no bio page")
+	}
+
+	return service.domain(ctx, pg)
+}
+
+func (service *bioService) Save(ctx context.Context, userID uuid.UUID, request dto.SaveBioPage) (page domain.BioPage, err error) {
+	var taken model.BioPage
+	taken, err = service.storage.GetByName(ctx, request.Name)
+	if err == nil && taken.UserID != userID {
+		return page, apperror.AlreadyExists.WithMessage("name is already taken")
+	}
+	if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+		return page, apperr.WithScope("bioService.Save.GetByName")
+	}
+
+	shortenIDs := make([]int64, len(request.Links))
+	for i, key := range request.Links {
+		var shortenID uint64
+		shortenID, err = base62.Decode(key)
+		if err != nil {
+			return page, apperror.BadRequest.WithError(err).WithMessage("link " + key + " is invalid")
+		}
+
+		// Listing a link publishes it, which takes more than being able to
+		// view it.
+		err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleEditor)
+		if err != nil {
+			return
+		}
+
+		shortenIDs[i] = int64(shortenID)
+	}
+
+	now := time.Now()
+
+	var before any
+	pg, err := service.storage.GetByUser(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return page, apperr.WithScope("bioService.Save.GetByUser")
+		}
+
+		pg = model.BioPage{ID: uuid.New(), UserID: userID, CreatedAt: now}
+	} else {
+		before = bioState(pg)
+	}
+
+	pg.Name = request.Name
+	pg.Title = request.Title
+	pg.AvatarURL = request.AvatarURL
+	pg.Theme = string(request.Theme)
+	pg.ShortenIDs = shortenIDs
+	pg.UpdatedAt = now
+
+	err = service.storage.Save(ctx, pg)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return page, apperr.WithScope("bioService.Save")
+		}
+
+		return
+	}
+
+	service.auditBio(ctx, domain.AuditBioSave, pg.ID, before, bioState(pg))
+
+	return service.domain(ctx, pg)
+}
+
+func (service *bioService) Delete(ctx context.Context, userID uuid.UUID) (err error) {
+	var pg model.BioPage
+	pg, err = service.storage.GetByUser(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("bioService.Delete.GetByUser")
+		}
+
+		return apperror.NotFound.WithMessage("you have no bio page")
+	}
+
+	err = service.storage.Delete(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("bioService.Delete")
+		}
+
+		return
+	}
+
+	service.auditBio(ctx, domain.AuditBioDelete, pg.ID, bioState(pg), nil)
+
+	return
+}
+
+// auditBio records a change to a bio page. Its links are kept by id, as
+// they were listed.
+func (service *bioService) auditBio(ctx context.Context, action string, pageID uuid.UUID, before, after any) {
+	if service.audit == nil {
+		return
+	}
+
+	service.audit.Record(ctx, dto.AuditEntry{
+		Action:     action,
+		TargetType: domain.TargetBioPage,
+		TargetID:   pageID.String(),
+		Before:     before,
+		After:      after,
+	})
+}
+
+func bioState(pg model.BioPage) map[string]any {
+	return map[string]any{
+		"name":        pg.Name,
+		"title":       pg.Title,
+		"avatar_url":  pg.AvatarURL,
+		"theme":       pg.Theme,
+		"shorten_ids": pg.ShortenIDs,
+	}
+}
+
+func (service *bioService) GetStats(ctx context.Context, userID uuid.UUID, request dto.GetBioStats) (stats []domain.BioLinkStats, err error) {
+	var pg model.BioPage
+	pg, err = service.storage.GetByUser(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return stats, apperr.WithScope("bioService.GetStats")
+		}
+
+		return stats, apperror.NotFound.WithMessage("you have no bio page")
+	}
+
+	var lnkStats []model.BioLinkStats
+	lnkStats, err = service.storage.SelectLinkStats(ctx, pg.ID, request.From, request.To)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return stats, apperr.WithScope("bioService.GetStats.SelectLinkStats")
+		}
+
+		return
+	}
+
+	stats = make([]domain.BioLinkStats, len(lnkStats))
+	for i, lnkStat := range lnkStats {
+		stats[i] = domain.BioLinkStats{Key: base62.Encode(uint64(lnkStat.ShortenID)), Clicks: lnkStat.Clicks}
+	}
+
+	return stats, nil
+}
+
+func (service *bioService) GetPublic(ctx context.Context, name string) (page domain.BioPage, err error) {
+	var pg model.BioPage
+	pg, err = service.storage.GetByName(ctx, name)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return page, apperr.WithScope("bioService.GetPublic")
+		}
+
+		return
+	}
+
+	page, err = service.domain(ctx, pg)
+	if err != nil {
+		return
+	}
+
+	active := page.Links[:0]
+	for _, link := range page.Links {
+		if link.Active {
+			active = append(active, link)
+		}
+	}
+	page.Links = active
+
+	return page, nil
+}
+
+func (service *bioService) GetLink(ctx context.Context, name, key string) (pageID uuid.UUID, shortenID uint64, err error) {
+	shortenID, err = base62.Decode(key)
+	if err != nil {
+		return pageID, 0, apperror.NotFound
+	}
+
+	var pg model.BioPage
+	pg, err = service.storage.GetByName(ctx, name)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return pageID, 0, apperr.WithScope("bioService.GetLink")
+		}
+
+		return
+	}
+
+	for _, id := range pg.ShortenIDs {
+		if uint64(id) == shortenID {
+			return pg.ID, shortenID, nil
+		}
+	}
+
+	return pageID, 0, apperror.NotFound
+}
+
+func (service *bioService) domain(ctx context.Context, pg model.BioPage) (page domain.BioPage, err error) {
+	ids := make([]uint64, len(pg.ShortenIDs))
+	for i, id := range pg.ShortenIDs {
+		ids[i] = uint64(id)
+	}
+
+	var shrtns model.Shortens
+	if len(ids) > 0 {
+		shrtns, err = service.shortenStorage.SelectByIDs(ctx, ids)
+		if err != nil {
+			if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+				return page, apperr.WithScope("bioService.SelectByIDs")
+			}
+
+			return
+		}
+	}
+
+	return pg.Domain(shrtns, service.domainURL, time.Now()), nil
+}
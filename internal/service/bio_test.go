@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	st "cc/internal/storage"
+	"cc/mock/storage"
+	"cc/pkg/apperror"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type bioStorage struct {
+	st.BioStorage
+	saved bool
+}
+
+func (storage *bioStorage) GetByName(context.Context, string) (model.BioPage, error) {
+	return model.BioPage{}, apperror.NotFound
+}
+
+func (storage *bioStorage) GetByUser(context.Context, uuid.UUID) (model.BioPage, error) {
+	return model.BioPage{}, apperror.NotFound
+}
+
+func (storage *bioStorage) Save(context.Context, model.BioPage) error {
+	storage.saved = true
+	return nil
+}
+
+// roleAuthorizer grants shortens up to a fixed role.
+type roleAuthorizer domain.Role
+
+func (authorizer roleAuthorizer) Authorize(context.Context, uuid.UUID, uuid.UUID, domain.Role) error {
+	return nil
+}
+
+func (authorizer roleAuthorizer) AuthorizeShorten(_ context.Context, _ uuid.UUID, _ uint64, role domain.Role) error {
+	if !domain.Role(authorizer).Allows(role) {
+		return apperror.Forbidden
+	}
+
+	return nil
+}
+
+func TestBioService_Save(t *testing.T) {
+	request := dto.SaveBioPage{Name: "jane", Title: "Jane", Theme: domain.ThemeLight, Links: []string{"b"}}
+
+	t.Run("viewer", func(t *testing.T) {
+		pages := &bioStorage{}
+		bioService := service.NewBioService(pages, nil, roleAuthorizer(domain.RoleViewer), nil, domainURL)
+
+		_, err := bioService.Save(context.Background(), uuid.New(), request)
+		_, ok := apperror.Is(err, apperror.Forbidden)
+		assert.True(t, ok)
+		assert.False(t, pages.saved)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		pages := &bioStorage{}
+		shortenStorage := &storage.ShortenStorageMock{
+			SelectByIDsFunc: func(context.Context, []uint64) (model.Shortens, error) {
+				return nil, nil
+			},
+		}
+		bioService := service.NewBioService(pages, shortenStorage, roleAuthorizer(domain.RoleEditor), nil, domainURL)
+
+		_, err := bioService.Save(context.Background(), uuid.New(), request)
+		assert.NoError(t, err)
+		assert.True(t, pages.saved)
+	})
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"cc/internal/config"
+	"cc/internal/domain"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/base62"
+	"cc/pkg/lru"
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v9"
+	"github.com/pkg/errors"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	redirectKeyPrefix = "shorten:"
+	// notFoundMarker is cached in Redis for keys without a shorten.
+	notFoundMarker = "-"
+	// invalidateChannel tells other instances to drop entries from their
+	// in-memory tier.
+	invalidateChannel = "shorten:invalidate"
+)
+
+// RedirectCache reads redirects through an in-memory LRU and Redis in front of
+// the storage. Unknown keys are cached as well, for a shorter time, so that
+// guessing keys does not reach the database. Redis failures are logged and
+// fall through to the storage, and storage failures are answered with stale
+// in-memory entries when there are any.
+type RedirectCache interface {
+	Get(ctx context.Context, shortenID uint64) (domain.Redirect, error)
+	// Invalidate drops the shortens from every tier, including the in-memory
+	// tiers of other instances.
+	Invalidate(ctx context.Context, shortenIDs ...uint64)
+	// Run applies invalidations published by other instances until ctx is done.
+	Run(ctx context.Context)
+	// Warm reports whether the in-memory tier holds entries it can serve
+	// while the database is down.
+	Warm() bool
+}
+
+type redirectCache struct {
+	storage storage.ShortenStorage
+	client  *redis.Client
+	local   *lru.Cache[uint64, *domain.Redirect]
+	config  config.Cache
+}
+
+func NewRedirectCache(storage storage.ShortenStorage, client *redis.Client, config config.Cache) RedirectCache {
+	return &redirectCache{
+		storage: storage,
+		client:  client,
+		local:   lru.New[uint64, *domain.Redirect](config.LocalSize, config.LocalTTL),
+		config:  config,
+	}
+}
+
+func (cache *redirectCache) Get(ctx context.Context, shortenID uint64) (redirect domain.Redirect, err error) {
+	if cached, ok := cache.local.Get(shortenID); ok {
+		if cached == nil {
+			return redirect, apperror.NotFound
+		}
+
+		return *cached, nil
+	}
+
+	key := redirectKey(shortenID)
+
+	// Entries that fail to decode, such as plain URLs written by older
+	// versions, are treated as misses.
+	value, err := cache.client.Get(ctx, key).Result()
+	switch {
+	case err == nil && value == notFoundMarker:
+		cache.local.Set(shortenID, nil)
+		return redirect, apperror.NotFound
+	case err == nil:
+		if json.Unmarshal([]byte(value), &redirect) == nil && redirect.URL != "" {
+			cache.local.Set(shortenID, &redirect)
+			return redirect, nil
+		}
+	case !errors.Is(err, redis.Nil):
+		log.Println(err)
+	}
+
+	rdrct, err := cache.storage.GetRedirect(ctx, shortenID)
+	if err != nil {
+		if _, ok := apperror.Is(err, apperror.NotFound); ok {
+			cache.local.Set(shortenID, nil)
+			cache.set(ctx, key, notFoundMarker, cache.config.NegativeTTL)
+			return redirect, apperror.NotFound
+		}
+
+		// While the database is unavailable, links that were visited
+		// recently keep working from the in-memory tier.
+		if stale, ok := cache.local.GetStale(shortenID); ok && stale != nil {
+			log.Println(err)
+			return *stale, nil
+		}
+
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return redirect, apperr.WithScope("redirectCache.Get")
+		}
+
+		return
+	}
+
+	redirect = rdrct.Domain()
+	cache.local.Set(shortenID, &redirect)
+
+	if encoded, err := json.Marshal(redirect); err == nil {
+		cache.set(ctx, key, string(encoded), cache.config.TTL)
+	}
+
+	return redirect, nil
+}
+
+func (cache *redirectCache) Invalidate(ctx context.Context, shortenIDs ...uint64) {
+	if len(shortenIDs) == 0 {
+		return
+	}
+
+	cache.local.Delete(shortenIDs...)
+
+	keys := make([]string, len(shortenIDs))
+	for i, shortenID := range shortenIDs {
+		keys[i] = redirectKey(shortenID)
+	}
+
+	if err := cache.client.Del(ctx, keys...).Err(); err != nil {
+		log.Println(err)
+	}
+
+	if cache.config.LocalSize > 0 {
+		if err := cache.client.Publish(ctx, invalidateChannel, strings.Join(keys, " ")).Err(); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (cache *redirectCache) Run(ctx context.Context) {
+	if cache.config.LocalSize <= 0 {
+		return
+	}
+
+	subscription := cache.client.Subscribe(ctx, invalidateChannel)
+	defer subscription.Close()
+
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			for _, key := range strings.Fields(message.Payload) {
+				shortenID, err := base62.Decode(strings.TrimPrefix(key, redirectKeyPrefix))
+				if err == nil {
+					cache.local.Delete(shortenID)
+				}
+			}
+		}
+	}
+}
+
+func (cache *redirectCache) Warm() bool {
+	return cache.local.Len() > 0
+}
+
+func (cache *redirectCache) set(ctx context.Context, key, value string, ttl time.Duration) {
+	if err := cache.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		log.Println(err)
+	}
+}
+
+func redirectKey(shortenID uint64) string {
+	return redirectKeyPrefix + base62.Encode(shortenID)
+}
@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"cc/internal/config"
+	"cc/internal/model"
+	"cc/internal/service"
+	"cc/mock/storage"
+	"cc/pkg/apperror"
+	"context"
+	"github.com/go-redis/redis/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// unreachableRedis fails every command at once, so only the in-memory tier
+// and the storage are exercised.
+func unreachableRedis() *redis.Client {
+	return redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+}
+
+func TestRedirectCache(t *testing.T) {
+	ctx := context.Background()
+
+	urls := map[uint64]string{1: "https://example.com"}
+	shortenStorage := &storage.ShortenStorageMock{
+		GetRedirectFunc: func(ctx context.Context, shortenID uint64) (model.Redirect, error) {
+			url, ok := urls[shortenID]
+			if !ok {
+				return model.Redirect{}, apperror.NotFound
+			}
+
+			return model.Redirect{URL: url}, nil
+		},
+	}
+
+	cache := service.NewRedirectCache(shortenStorage, unreachableRedis(), config.Cache{
+		TTL:         time.Hour,
+		NegativeTTL: time.Minute,
+		LocalSize:   10,
+		LocalTTL:    time.Minute,
+	})
+
+	for i := 0; i < 2; i++ {
+		redirect, err := cache.Get(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com", redirect.URL)
+
+		_, err = cache.Get(ctx, 2)
+		assert.ErrorIs(t, err, apperror.NotFound)
+	}
+	assert.Len(t, shortenStorage.GetRedirectCalls(), 2, "hits and misses are cached")
+
+	urls[1] = "https://example.org"
+	urls[2] = "https://example.net"
+	cache.Invalidate(ctx, 1, 2)
+
+	redirect, err := cache.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.org", redirect.URL)
+
+	redirect, err = cache.Get(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.net", redirect.URL)
+	assert.Len(t, shortenStorage.GetRedirectCalls(), 4)
+}
+
+func TestRedirectCache_StaleWhileStorageDown(t *testing.T) {
+	ctx := context.Background()
+
+	storageDown := false
+	shortenStorage := &storage.ShortenStorageMock{
+		GetRedirectFunc: func(ctx context.Context, shortenID uint64) (model.Redirect, error) {
+			if storageDown {
+				return model.Redirect{}, apperror.Internal
+			}
+
+			return model.Redirect{URL: "https://example.com"}, nil
+		},
+	}
+
+	cache := service.NewRedirectCache(shortenStorage, unreachableRedis(), config.Cache{
+		TTL:         time.Hour,
+		NegativeTTL: time.Minute,
+		LocalSize:   10,
+		LocalTTL:    time.Millisecond,
+	})
+
+	_, err := cache.Get(ctx, 1)
+	assert.NoError(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+	storageDown = true
+
+	redirect, err := cache.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com", redirect.URL)
+
+	_, err = cache.Get(ctx, 2)
+	assert.Error(t, err)
+	assert.Len(t, shortenStorage.GetRedirectCalls(), 3)
+}
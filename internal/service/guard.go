@@ -0,0 +1,113 @@
+package service
+
+import (
+	"cc/internal/config"
+	"cc/pkg/apperror"
+	"cc/pkg/ratelimit"
+	"context"
+	"log"
+	"time"
+)
+
+const strikesWindow = 24 * time.Hour
+
+type GuardService interface {
+	Check(ctx context.Context, ip, name string) error
+	Fail(ctx context.Context, name string) error
+	Succeed(ctx context.Context, name string)
+}
+
+type guardService struct {
+	limiter *ratelimit.Limiter
+	config  config.RateLimit
+}
+
+func NewGuardService(limiter *ratelimit.Limiter, config config.RateLimit) GuardService {
+	return &guardService{limiter: limiter, config: config}
+}
+
+// Check counts an authentication attempt from ip and rejects it while ip is
+// over its limit or the account name is locked out. Redis failures never block
+// a login.
+func (service *guardService) Check(ctx context.Context, ip, name string) error {
+	count, reset, err := service.limiter.Hit(ctx, "ip:"+ip, service.config.IPWindow)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	if count > service.config.IPAttempts {
+		return apperror.TooManyRequests.
+			WithMessage("too many attempts, try again later").
+			WithRetryAfter(reset)
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	var locked time.Duration
+	locked, err = service.limiter.Locked(ctx, "lock:"+name)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	if locked > 0 {
+		return apperror.TooManyRequests.
+			WithMessage("account is temporarily locked, try again later").
+			WithRetryAfter(locked)
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt for name. Once the account runs out of
+// attempts it is locked, and every further lockout within a day lasts twice as
+// long as the previous one.
+func (service *guardService) Fail(ctx context.Context, name string) error {
+	count, _, err := service.limiter.Hit(ctx, "fail:"+name, service.config.AccountWindow)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	if count < service.config.AccountAttempts {
+		return nil
+	}
+
+	var strikes int64
+	strikes, _, err = service.limiter.Hit(ctx, "strikes:"+name, strikesWindow)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	lockout := service.config.Lockout
+	for i := int64(1); i < strikes && lockout < service.config.MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	if lockout > service.config.MaxLockout {
+		lockout = service.config.MaxLockout
+	}
+
+	if err = service.limiter.Lock(ctx, "lock:"+name, lockout); err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	if err = service.limiter.Reset(ctx, "fail:"+name); err != nil {
+		log.Println(err)
+	}
+
+	return apperror.TooManyRequests.
+		WithMessage("account is temporarily locked, try again later").
+		WithRetryAfter(lockout)
+}
+
+func (service *guardService) Succeed(ctx context.Context, name string) {
+	if err := service.limiter.Reset(ctx, "fail:"+name, "strikes:"+name); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"cc/internal/config"
+	"cc/internal/service"
+	"cc/pkg/apperror"
+	"cc/pkg/ratelimit"
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var guardConfig = config.RateLimit{
+	IPAttempts:      3,
+	IPWindow:        time.Minute,
+	AccountAttempts: 2,
+	AccountWindow:   15 * time.Minute,
+	Lockout:         time.Minute,
+	MaxLockout:      3 * time.Minute,
+}
+
+func newGuardService(t *testing.T) (service.GuardService, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return service.NewGuardService(ratelimit.New(client, "guard:"), guardConfig), server
+}
+
+// lockedFor fails name until it is locked and returns the lockout.
+func lockedFor(t *testing.T, guardService service.GuardService, name string) time.Duration {
+	ctx := context.Background()
+
+	for i := int64(1); i < guardConfig.AccountAttempts; i++ {
+		assert.NoError(t, guardService.Fail(ctx, name))
+	}
+
+	apperr, ok := apperror.Is(guardService.Fail(ctx, name), apperror.TooManyRequests)
+	assert.True(t, ok)
+
+	return apperr.RetryAfter
+}
+
+func TestGuardService_Check(t *testing.T) {
+	ctx := context.Background()
+	guardService, server := newGuardService(t)
+
+	for i := int64(0); i < guardConfig.IPAttempts; i++ {
+		assert.NoError(t, guardService.Check(ctx, "127.0.0.1", ""))
+	}
+
+	apperr, ok := apperror.Is(guardService.Check(ctx, "127.0.0.1", ""), apperror.TooManyRequests)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, apperr.RetryAfter)
+	assert.NoError(t, guardService.Check(ctx, "127.0.0.2", ""), "other addresses are not limited")
+
+	server.FastForward(time.Minute)
+	assert.NoError(t, guardService.Check(ctx, "127.0.0.1", ""))
+}
+
+func TestGuardService_Fail(t *testing.T) {
+	ctx := context.Background()
+	guardService, server := newGuardService(t)
+
+	assert.Equal(t, time.Minute, lockedFor(t, guardService, "alice"))
+
+	apperr, ok := apperror.Is(guardService.Check(ctx, "127.0.0.1", "alice"), apperror.TooManyRequests)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, apperr.RetryAfter)
+	assert.NoError(t, guardService.Check(ctx, "127.0.0.2", "bob"))
+
+	// Every lockout within a day doubles, up to the maximum.
+	server.FastForward(time.Minute)
+	assert.NoError(t, guardService.Check(ctx, "127.0.0.3", "alice"))
+	assert.Equal(t, 2*time.Minute, lockedFor(t, guardService, "alice"))
+
+	server.FastForward(2 * time.Minute)
+	assert.Equal(t, 3*time.Minute, lockedFor(t, guardService, "alice"))
+}
+
+func TestGuardService_Succeed(t *testing.T) {
+	ctx := context.Background()
+	guardService, server := newGuardService(t)
+
+	assert.Equal(t, time.Minute, lockedFor(t, guardService, "alice"))
+	server.FastForward(time.Minute)
+
+	guardService.Succeed(ctx, "alice")
+
+	assert.Equal(t, time.Minute, lockedFor(t, guardService, "alice"), "strikes are forgotten")
+}
+
+func TestGuardService_FailOpen(t *testing.T) {
+	ctx := context.Background()
+	guardService, server := newGuardService(t)
+
+	server.Close()
+
+	for i := int64(0); i <= guardConfig.IPAttempts; i++ {
+		assert.NoError(t, guardService.Check(ctx, "127.0.0.1", "alice"))
+	}
+	for i := int64(0); i <= guardConfig.AccountAttempts; i++ {
+		assert.NoError(t, guardService.Fail(ctx, "alice"))
+	}
+	guardService.Succeed(ctx, "alice")
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"cc/internal/domain"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/healthcheck"
+	"context"
+	"log"
+	"time"
+)
+
+const healthCheckBatch = 100
+
+type HealthService interface {
+	// Check checks every enabled shorten that was not checked since the
+	// interval started and returns how many were checked.
+	Check(ctx context.Context, interval time.Duration) (int, error)
+	// Run calls Check every interval until ctx is done.
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type healthService struct {
+	storage          storage.ShortenStorage
+	checker          *healthcheck.Checker
+	failureThreshold int
+}
+
+// NewHealthService creates a HealthService that marks a link broken after
+// failureThreshold consecutive failed checks, so a single timeout does not
+// show up in reports.
+func NewHealthService(storage storage.ShortenStorage, checker *healthcheck.Checker, failureThreshold int) HealthService {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	return &healthService{storage: storage, checker: checker, failureThreshold: failureThreshold}
+}
+
+func (service *healthService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := service.Check(ctx, interval); err != nil {
+			log.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (service *healthService) Check(ctx context.Context, interval time.Duration) (checked int, err error) {
+	before := time.Now().Add(-interval)
+
+	for ctx.Err() == nil {
+		var shrtns model.Shortens
+		shrtns, err = service.storage.SelectForHealthCheck(ctx, before, healthCheckBatch)
+		if err != nil {
+			if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+				return checked, apperr.WithScope("healthService.Check")
+			}
+
+			return
+		}
+
+		if len(shrtns) == 0 {
+			return checked, nil
+		}
+
+		urls := make([]string, len(shrtns))
+		for i, shrtn := range shrtns {
+			urls[i] = shrtn.URL
+		}
+
+		results := service.checker.CheckAll(ctx, urls)
+		if ctx.Err() != nil {
+			return checked, nil
+		}
+
+		now := time.Now()
+		for i, shrtn := range shrtns {
+			health := service.health(shrtn.Health, results[i], now)
+
+			err = service.storage.SetHealth(ctx, shrtn.ID, health)
+			if err != nil {
+				if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+					return checked, apperr.WithScope("healthService.Check")
+				}
+
+				return
+			}
+
+			checked++
+		}
+	}
+
+	return checked, nil
+}
+
+func (service *healthService) health(previous model.Health, result healthcheck.Result, now time.Time) model.Health {
+	health := model.Health{
+		Status:     previous.Status,
+		StatusCode: result.StatusCode,
+		LatencyMS:  result.Latency.Milliseconds(),
+		FinalURL:   result.FinalURL,
+		Error:      result.Error,
+		CheckedAt:  &now,
+	}
+
+	if result.Healthy() {
+		health.Status = string(domain.HealthHealthy)
+		return health
+	}
+
+	health.Failures = previous.Failures + 1
+	if health.Failures >= service.failureThreshold {
+		health.Status = string(domain.HealthBroken)
+	}
+
+	if health.Status == "" {
+		health.Status = string(domain.HealthUnknown)
+	}
+
+	return health
+}
@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"cc/internal/domain"
+	"cc/internal/model"
+	"cc/internal/service"
+	"cc/mock/storage"
+	"cc/pkg/base62"
+	"cc/pkg/healthcheck"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthService_Check(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	shrtns := model.Shortens{
+		{ID: 1, URL: server.URL + "/ok"},
+		{ID: 2, URL: server.URL + "/gone"},
+		{ID: 3, URL: server.URL + "/ok", Health: model.Health{Status: string(domain.HealthBroken), Failures: 3}},
+	}
+
+	checked := false
+	shortenStorage := &storage.ShortenStorageMock{
+		SelectForHealthCheckFunc: func(context.Context, time.Time, int) (model.Shortens, error) {
+			if checked {
+				return nil, nil
+			}
+
+			checked = true
+			return shrtns, nil
+		},
+		SetHealthFunc: func(_ context.Context, shortenID uint64, health model.Health) error {
+			for i := range shrtns {
+				if shrtns[i].ID == shortenID {
+					shrtns[i].Health = health
+				}
+			}
+			return nil
+		},
+	}
+
+	checker := healthcheck.New(server.Client(), healthcheck.Config{Concurrency: 2})
+	healthService := service.NewHealthService(shortenStorage, checker, 2)
+
+	tests := []struct {
+		name     string
+		statuses []domain.HealthStatus
+		failures []int
+	}{
+		{
+			name:     "first check",
+			statuses: []domain.HealthStatus{domain.HealthHealthy, domain.HealthUnknown, domain.HealthHealthy},
+			failures: []int{0, 1, 0},
+		},
+		{
+			name:     "failed twice",
+			statuses: []domain.HealthStatus{domain.HealthHealthy, domain.HealthBroken, domain.HealthHealthy},
+			failures: []int{0, 2, 0},
+		},
+		{
+			name:     "still broken",
+			statuses: []domain.HealthStatus{domain.HealthHealthy, domain.HealthBroken, domain.HealthHealthy},
+			failures: []int{0, 3, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checked = false
+
+			n, err := healthService.Check(context.Background(), time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, len(shrtns), n)
+
+			for i, shrtn := range shrtns {
+				assert.Equal(t, string(tt.statuses[i]), shrtn.Health.Status, shrtn.URL)
+				assert.Equal(t, tt.failures[i], shrtn.Health.Failures, shrtn.URL)
+				assert.NotNil(t, shrtn.Health.CheckedAt)
+			}
+		})
+	}
+
+	assert.Equal(t, http.StatusGone, shrtns[1].Health.StatusCode)
+}
+
+func TestShortenService_SelectByHealth(t *testing.T) {
+	userID := uuid.New()
+	own, foreign := uuid.New(), uuid.New()
+
+	shrtns := model.Shortens{
+		{ID: 1, URL: "https://example.com/ok", WorkspaceID: own, Health: model.Health{Status: string(domain.HealthHealthy)}},
+		{ID: 2, URL: "https://example.com/gone", WorkspaceID: own, Health: model.Health{Status: string(domain.HealthBroken), Failures: 2}},
+		{ID: 3, URL: "https://example.com/gone", WorkspaceID: foreign, Health: model.Health{Status: string(domain.HealthBroken), Failures: 2}},
+	}
+
+	shortenStorage := &storage.ShortenStorageMock{
+		SelectByHealthFunc: func(_ context.Context, _ uuid.UUID, status string, _ []string) (model.Shortens, error) {
+			var res model.Shortens
+			for _, shrtn := range shrtns {
+				if shrtn.Health.Status == status {
+					res = append(res, shrtn)
+				}
+			}
+			return res, nil
+		},
+	}
+
+	shortenService := service.NewShortenService(shortenStorage, nil, nil, nil, workspaceAuthorizer{own: true}, nil, nil, nil, domainURL)
+
+	broken, err := shortenService.SelectByHealth(context.Background(), userID, domain.HealthBroken, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, broken, 1, "only broken links of accessible workspaces are listed") {
+		assert.Equal(t, base62.Encode(2), broken[0].ID)
+		assert.Equal(t, domain.HealthBroken, broken[0].Health.Status)
+	}
+}
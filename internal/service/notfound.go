@@ -0,0 +1,145 @@
+package service
+
+import (
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/base62"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// maxPendingMisses bounds the counts kept between flushes. Once reached, hits
+// for keys not seen since the last flush are dropped.
+const maxPendingMisses = 10000
+
+// maxMissedKey is the length of the largest key in base62, longer ones cannot
+// exist.
+const maxMissedKey = 11
+
+type NotFoundService interface {
+	// Record counts a request for a key without a shorten in memory, so
+	// guessing keys does not write to the database on every request. Bots and
+	// keys that could never be claimed are left out.
+	Record(hit dto.NotFoundHit)
+	// Flush adds the counts to the database and drops the ones older than
+	// the retention period.
+	Flush(ctx context.Context) error
+	// Run calls Flush every interval until ctx is done, and once more then.
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type missKey struct {
+	key     string
+	referer string
+	day     time.Time
+}
+
+type notFoundService struct {
+	storage   storage.StatsStorage
+	retention time.Duration
+
+	mu      sync.Mutex
+	pending map[missKey]*model.NotFoundHit
+}
+
+func NewNotFoundService(storage storage.StatsStorage, retention time.Duration) NotFoundService {
+	return &notFoundService{storage: storage, retention: retention, pending: make(map[missKey]*model.NotFoundHit)}
+}
+
+func (service *notFoundService) Record(hit dto.NotFoundHit) {
+	if len(hit.Key) > maxMissedKey {
+		return
+	}
+
+	if _, err := base62.Decode(hit.Key); err != nil {
+		return
+	}
+
+	if NewVisitor(hit.UserAgent, "", hit.Timestamp).Bot {
+		return
+	}
+
+	y, m, d := hit.Timestamp.UTC().Date()
+	key := missKey{key: hit.Key, referer: normalizeReferer(hit.Referer), day: time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	pending, ok := service.pending[key]
+	if !ok {
+		if len(service.pending) >= maxPendingMisses {
+			return
+		}
+
+		pending = &model.NotFoundHit{Key: key.key, Referer: key.referer, Day: key.day}
+		service.pending[key] = pending
+	}
+
+	pending.Hits++
+	if hit.Timestamp.After(pending.LastSeenAt) {
+		pending.LastSeenAt = hit.Timestamp
+	}
+}
+
+func (service *notFoundService) Flush(ctx context.Context) error {
+	service.mu.Lock()
+	pending := service.pending
+	service.pending = make(map[missKey]*model.NotFoundHit)
+	service.mu.Unlock()
+
+	if len(pending) > 0 {
+		hits := make([]model.NotFoundHit, 0, len(pending))
+		for _, hit := range pending {
+			hits = append(hits, *hit)
+		}
+
+		err := service.storage.AddNotFoundHits(ctx, hits)
+		if err != nil {
+			if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+				return apperr.WithScope("notFoundService.Flush")
+			}
+
+			return err
+		}
+	}
+
+	if service.retention <= 0 {
+		return nil
+	}
+
+	_, err := service.storage.PurgeNotFoundHits(ctx, time.Now().Add(-service.retention))
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("notFoundService.Flush.Purge")
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (service *notFoundService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The server is shutting down, ctx can no longer be used.
+			if err := service.Flush(context.Background()); err != nil {
+				log.Println(err)
+			}
+
+			return
+		case <-ticker.C:
+			if err := service.Flush(ctx); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
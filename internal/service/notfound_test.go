@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	"cc/internal/storage"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// missStorage keeps the counts it is given. Other methods are not expected
+// to be called.
+type missStorage struct {
+	storage.StatsStorage
+	hits   []model.NotFoundHit
+	purged time.Time
+}
+
+func (storage *missStorage) AddNotFoundHits(_ context.Context, hits []model.NotFoundHit) error {
+	storage.hits = append(storage.hits, hits...)
+	return nil
+}
+
+func (storage *missStorage) PurgeNotFoundHits(_ context.Context, before time.Time) (int64, error) {
+	storage.purged = before
+	return 0, nil
+}
+
+func TestNotFoundService(t *testing.T) {
+	const browser = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36"
+
+	at := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	storage := &missStorage{}
+	notFound := service.NewNotFoundService(storage, 30*24*time.Hour)
+
+	notFound.Record(dto.NotFoundHit{Key: "abc", UserAgent: browser, Timestamp: at})
+	notFound.Record(dto.NotFoundHit{Key: "abc", UserAgent: browser, Timestamp: at.Add(time.Minute)})
+	notFound.Record(dto.NotFoundHit{Key: "abc", UserAgent: "Googlebot/2.1 (+http://www.google.com/bot.html)", Timestamp: at})
+	notFound.Record(dto.NotFoundHit{Key: "not-base62", UserAgent: browser, Timestamp: at})
+	notFound.Record(dto.NotFoundHit{Key: strings.Repeat("a", 100), UserAgent: browser, Timestamp: at})
+
+	assert.NoError(t, notFound.Flush(context.Background()))
+	if assert.Len(t, storage.hits, 1) {
+		assert.Equal(t, "abc", storage.hits[0].Key)
+		assert.Equal(t, int64(2), storage.hits[0].Hits)
+		assert.Equal(t, at.Add(time.Minute), storage.hits[0].LastSeenAt)
+	}
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), storage.purged, time.Minute)
+
+	assert.NoError(t, notFound.Flush(context.Background()))
+	assert.Len(t, storage.hits, 1, "counts are only added once")
+}
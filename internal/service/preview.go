@@ -0,0 +1,148 @@
+package service
+
+import (
+	"cc/internal/domain"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/preview"
+	"context"
+	"github.com/google/uuid"
+	"github.com/goware/urlx"
+	"log"
+	"time"
+)
+
+type PreviewService interface {
+	// Enqueue schedules a background fetch. It never blocks; when the queue is
+	// full the fetch is dropped and can be repeated with Refresh.
+	Enqueue(shortenID uint64)
+	Refresh(ctx context.Context, userID uuid.UUID, shortenID uint64) (domain.Shorten, error)
+	// Run processes the queue with the given number of workers until ctx is
+	// done.
+	Run(ctx context.Context, workers int)
+}
+
+type previewService struct {
+	storage    storage.ShortenStorage
+	authorizer Authorizer
+	fetcher    *preview.Fetcher
+	timeout    time.Duration
+	queue      chan uint64
+	domainURL  string
+}
+
+func NewPreviewService(storage storage.ShortenStorage, authorizer Authorizer, fetcher *preview.Fetcher, timeout time.Duration, domainURL string) PreviewService {
+	return &previewService{
+		storage:    storage,
+		authorizer: authorizer,
+		fetcher:    fetcher,
+		timeout:    timeout,
+		queue:      make(chan uint64, 1024),
+		domainURL:  domainURL,
+	}
+}
+
+func (service *previewService) Enqueue(shortenID uint64) {
+	select {
+	case service.queue <- shortenID:
+	default:
+		log.Printf("preview queue is full, dropping shorten %d", shortenID)
+	}
+}
+
+func (service *previewService) Run(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case shortenID := <-service.queue:
+					shrtn, err := service.storage.GetByID(ctx, shortenID)
+					if err != nil {
+						log.Println(err)
+						continue
+					}
+
+					if err = service.fetch(ctx, shrtn); err != nil {
+						log.Println(err)
+					}
+				}
+			}
+		}()
+	}
+}
+
+func (service *previewService) Refresh(ctx context.Context, userID uuid.UUID, shortenID uint64) (shorten domain.Shorten, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleEditor)
+	if err != nil {
+		return
+	}
+
+	var shrtn model.Shorten
+	shrtn, err = service.storage.GetByID(ctx, shortenID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("previewService.Refresh")
+		}
+
+		return
+	}
+
+	if shrtn.QuarantinedAt != nil {
+		return shorten, apperror.BadRequest.WithMessage("quarantined links are not fetched")
+	}
+
+	err = service.fetch(ctx, shrtn)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("previewService.Refresh")
+		}
+
+		return
+	}
+
+	shrtn, err = service.storage.GetByID(ctx, shortenID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("previewService.Refresh")
+		}
+
+		return
+	}
+
+	return shrtn.Domain(service.domainURL), nil
+}
+
+// fetch stores the destination's metadata on the shorten. A failed fetch is
+// stored too, so the error shows up next to the link.
+func (service *previewService) fetch(ctx context.Context, shrtn model.Shorten) error {
+	if shrtn.QuarantinedAt != nil {
+		return nil
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	now := time.Now()
+	prvw := model.Preview{FetchedAt: &now}
+
+	metadata, err := service.fetcher.Fetch(fetchCtx, shrtn.URL)
+	if err != nil {
+		prvw.Error = err.Error()
+	} else {
+		prvw.Title = metadata.Title
+		prvw.Description = metadata.Description
+		prvw.OGTitle = metadata.OGTitle
+		prvw.OGImage = metadata.OGImage
+		prvw.Favicon = metadata.Favicon
+	}
+
+	var placeholder string
+	if url, err := urlx.Parse(shrtn.URL); err == nil {
+		placeholder = url.Host
+	}
+
+	return service.storage.SetPreview(ctx, shrtn.ID, prvw, metadata.PageTitle(), placeholder)
+}
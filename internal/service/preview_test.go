@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"cc/internal/model"
+	"cc/internal/service"
+	"cc/mock/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/preview"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type setPreview struct {
+	shortenID   uint64
+	preview     model.Preview
+	title       string
+	placeholder string
+}
+
+func TestPreviewService_Run(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><title>Article</title><meta property="og:title" content="Card title"><meta name="description" content="About it."></head></html>`)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	quarantinedAt := time.Now()
+	shrtns := map[uint64]model.Shorten{
+		1: {ID: 1, URL: server.URL + "/article"},
+		2: {ID: 2, URL: server.URL + "/missing"},
+		3: {ID: 3, URL: server.URL + "/article", QuarantinedAt: &quarantinedAt},
+	}
+
+	stored := make(chan setPreview, len(shrtns))
+	shortenStorage := &storage.ShortenStorageMock{
+		GetByIDFunc: func(_ context.Context, id uint64) (model.Shorten, error) {
+			return shrtns[id], nil
+		},
+		SetPreviewFunc: func(_ context.Context, shortenID uint64, prvw model.Preview, title, placeholder string) error {
+			stored <- setPreview{shortenID: shortenID, preview: prvw, title: title, placeholder: placeholder}
+			return nil
+		},
+	}
+
+	fetcher := preview.New(server.Client(), preview.Config{})
+	previewService := service.NewPreviewService(shortenStorage, authorizer{}, fetcher, time.Second, domainURL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A single worker takes the shortens in order, so the quarantined one has
+	// been skipped once the others are stored.
+	previewService.Enqueue(3)
+	previewService.Enqueue(1)
+	previewService.Enqueue(2)
+	previewService.Run(ctx, 1)
+
+	previews := make(map[uint64]setPreview)
+	for len(previews) < 2 {
+		select {
+		case s := <-stored:
+			previews[s.shortenID] = s
+		case <-time.After(5 * time.Second):
+			t.Fatal("previews were not stored")
+		}
+	}
+
+	article := previews[1]
+	assert.Equal(t, "Card title", article.title)
+	assert.Equal(t, "Article", article.preview.Title)
+	assert.Equal(t, "About it.", article.preview.Description)
+	assert.Empty(t, article.preview.Error)
+	assert.NotNil(t, article.preview.FetchedAt)
+	assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), article.placeholder)
+
+	missing := previews[2]
+	assert.Empty(t, missing.title)
+	assert.Contains(t, missing.preview.Error, "404")
+	assert.NotNil(t, missing.preview.FetchedAt, "failed fetches are stored too")
+
+	assert.Len(t, shortenStorage.SetPreviewCalls(), 2)
+}
+
+func TestPreviewService_Refresh(t *testing.T) {
+	quarantinedAt := time.Now()
+	shortenStorage := &storage.ShortenStorageMock{
+		GetByIDFunc: func(context.Context, uint64) (model.Shorten, error) {
+			return model.Shorten{ID: 1, URL: "https://example.com", QuarantinedAt: &quarantinedAt}, nil
+		},
+	}
+
+	previewService := service.NewPreviewService(shortenStorage, authorizer{}, preview.New(http.DefaultClient, preview.Config{}), time.Second, domainURL)
+
+	_, err := previewService.Refresh(context.Background(), uuid.New(), 1)
+	_, ok := apperror.Is(err, apperror.BadRequest)
+	assert.True(t, ok)
+	assert.Empty(t, shortenStorage.SetPreviewCalls())
+}
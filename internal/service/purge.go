@@ -0,0 +1,60 @@
+package service
+
+import (
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"context"
+	"log"
+	"time"
+)
+
+type PurgeService interface {
+	// Purge deletes shortens that have been in the trash for longer than the
+	// configured period, along with their clicks, and returns how many.
+	Purge(ctx context.Context) (int, error)
+	// Run calls Purge every interval until ctx is done.
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type purgeService struct {
+	storage storage.ShortenStorage
+	after   time.Duration
+}
+
+func NewPurgeService(storage storage.ShortenStorage, after time.Duration) PurgeService {
+	return &purgeService{storage: storage, after: after}
+}
+
+func (service *purgeService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := service.Purge(ctx); err != nil {
+			log.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (service *purgeService) Purge(ctx context.Context) (int, error) {
+	ids, err := service.storage.Purge(ctx, time.Now().Add(-service.after))
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return 0, apperr.WithScope("purgeService.Purge")
+		}
+
+		return 0, err
+	}
+
+	if len(ids) > 0 {
+		log.Printf("purged %d shortens from the trash", len(ids))
+	}
+
+	return len(ids), nil
+}
@@ -0,0 +1,26 @@
+package service_test
+
+import (
+	"cc/internal/service"
+	"cc/mock/storage"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPurgeService_Purge(t *testing.T) {
+	var before time.Time
+	shortenStorage := &storage.ShortenStorageMock{
+		PurgeFunc: func(ctx context.Context, b time.Time) ([]uint64, error) {
+			before = b
+			return []uint64{1, 2}, nil
+		},
+	}
+
+	purged, err := service.NewPurgeService(shortenStorage, 30*24*time.Hour).Purge(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), before, time.Minute)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/pkg/apperror"
+	"cc/pkg/qr"
+	"context"
+	"github.com/google/uuid"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Logos are bounded in bytes and in pixels, a small file can still decode to
+// a huge image.
+const (
+	maxLogoBytes  = 1 << 20
+	maxLogoPixels = 4096
+)
+
+type QRService interface {
+	// Generate returns the QR code image for the shorten and its content type.
+	Generate(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.GetQR) ([]byte, string, error)
+}
+
+type qrService struct {
+	shortenService ShortenService
+	client         *http.Client
+}
+
+func NewQRService(shortenService ShortenService, client *http.Client) QRService {
+	return &qrService{shortenService: shortenService, client: client}
+}
+
+func (service *qrService) Generate(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.GetQR) (data []byte, contentType string, err error) {
+	var shorten domain.Shorten
+	shorten, err = service.shortenService.GetByID(ctx, userID, shortenID)
+	if err != nil {
+		return
+	}
+
+	options := qr.Options{
+		Size:   request.Size,
+		Margin: *request.Margin,
+		Level:  request.ECC,
+	}
+	options.Foreground, _ = qr.ParseColor(request.Foreground)
+	options.Background, _ = qr.ParseColor(request.Background)
+
+	if request.Logo != "" {
+		options.Logo, err = service.logo(ctx, request.Logo)
+		if err != nil {
+			return
+		}
+	}
+
+	content := qrContent(shorten.ShortURL)
+
+	if request.Format == "svg" {
+		data, err = qr.SVG(content, options)
+		contentType = "image/svg+xml"
+	} else {
+		data, err = qr.PNG(content, options)
+		contentType = "image/png"
+	}
+	if err != nil {
+		return nil, "", apperror.Internal.WithError(err).WithScope("qrService.Generate")
+	}
+
+	return data, contentType, nil
+}
+
+func (service *qrService) logo(ctx context.Context, url string) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, apperror.BadRequest.WithError(err).WithMessage("logo is invalid")
+	}
+
+	resp, err := service.client.Do(req)
+	if err != nil {
+		return nil, apperror.BadRequest.WithError(err).WithMessage("logo could not be downloaded")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperror.BadRequest.WithMessage("logo could not be downloaded")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLogoBytes+1))
+	if err != nil {
+		return nil, apperror.BadRequest.WithError(err).WithMessage("logo could not be downloaded")
+	}
+
+	if len(data) > maxLogoBytes {
+		return nil, apperror.BadRequest.WithMessage("logo must be a png, jpeg or gif image up to 1 MB")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width > maxLogoPixels || config.Height > maxLogoPixels {
+		return nil, apperror.BadRequest.WithMessage("logo must be a png, jpeg or gif image up to 4096x4096")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, apperror.BadRequest.WithError(err).WithMessage("logo must be a png, jpeg or gif image up to 1 MB")
+	}
+
+	return img, nil
+}
+
+// qrContent adds a scheme to the short URL, without one scanners treat the
+// code as plain text, and the marker that attributes the click to a scan.
+func qrContent(shortURL string) string {
+	if !strings.Contains(shortURL, "://") {
+		shortURL = "https://" + shortURL
+	}
+
+	return shortURL + "?" + domain.QRMarker
+}
@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	st "cc/internal/storage"
+	"cc/pkg/qr"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type qrShortens struct {
+	service.ShortenService
+	shorten domain.Shorten
+}
+
+func (shortens qrShortens) GetByID(context.Context, uuid.UUID, uint64) (domain.Shorten, error) {
+	return shortens.shorten, nil
+}
+
+// clickStorage keeps the clicks it is asked to create.
+type clickStorage struct {
+	st.StatsStorage
+	clicks []model.Click
+}
+
+func (storage *clickStorage) CreateClick(_ context.Context, click model.Click) error {
+	storage.clicks = append(storage.clicks, click)
+	return nil
+}
+
+func TestQRService_Generate(t *testing.T) {
+	shortens := qrShortens{shorten: domain.Shorten{ID: "abc", ShortURL: domainURL + "/abc"}}
+	qrService := service.NewQRService(shortens, http.DefaultClient)
+
+	request := dto.GetQR{Format: "svg"}
+	assert.NoError(t, request.Validate())
+
+	data, contentType, err := qrService.Generate(context.Background(), uuid.New(), 1, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/svg+xml", contentType)
+
+	// The code holds the short URL with a scheme and the QR marker.
+	options := qr.Options{Size: request.Size, Margin: *request.Margin, Level: request.ECC}
+	options.Foreground, _ = qr.ParseColor(request.Foreground)
+	options.Background, _ = qr.ParseColor(request.Background)
+
+	want, err := qr.SVG("https://"+domainURL+"/abc?"+domain.QRMarker, options)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(data))
+}
+
+func TestStatsService_CreateClickByUserAgent(t *testing.T) {
+	const ua = "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1"
+	pageID := uuid.New()
+
+	tests := []struct {
+		name   string
+		visit  dto.Visit
+		source string
+	}{
+		{
+			name:   "scanned",
+			visit:  dto.Visit{ShortenID: 1, UserAgent: ua, Marker: domain.QRMarker},
+			source: domain.SourceQR,
+		},
+		{
+			name:   "opened",
+			visit:  dto.Visit{ShortenID: 1, UserAgent: ua},
+			source: domain.SourceDirect,
+		},
+		{
+			name:   "other query",
+			visit:  dto.Visit{ShortenID: 1, UserAgent: ua, Marker: "utm_source=mail"},
+			source: domain.SourceDirect,
+		},
+		{
+			name:   "bio page",
+			visit:  dto.Visit{ShortenID: 1, UserAgent: ua, BioPageID: &pageID},
+			source: domain.SourceBio,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clicks := &clickStorage{}
+			statsService := service.NewStatsService(clicks, nil, nil)
+
+			tt.visit.Timestamp = time.Now()
+			assert.NoError(t, statsService.CreateClickByUserAgent(context.Background(), tt.visit))
+
+			if assert.Len(t, clicks.clicks, 1) {
+				assert.Equal(t, tt.source, clicks.clicks[0].Source)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"cc/internal/domain"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+// inTx runs fn in a transaction of tx, or on its own when there is no tx.
+// Errors from starting or committing the transaction are internal.
+func inTx(ctx context.Context, tx storage.Transactor, fn func(ctx context.Context) error) error {
+	if tx == nil {
+		return fn(ctx)
+	}
+
+	err := tx.InTx(ctx, fn)
+
+	var apperr apperror.Error
+	if err != nil && !errors.As(err, &apperr) {
+		return apperror.Internal.WithError(err)
+	}
+
+	return err
+}
+
+// recordRevision appends a revision of a shorten. userID is nil for changes
+// made by the system, e.g. screening. Without revisions nothing is kept.
+func recordRevision(ctx context.Context, revisions storage.RevisionStorage, userID *uuid.UUID, shrtn model.Shorten, action domain.RevisionAction, changes domain.Changes) error {
+	if revisions == nil {
+		return nil
+	}
+
+	return revisions.Create(ctx, model.Revision{
+		ShortenID: shrtn.ID,
+		UserID:    userID,
+		Action:    action,
+		Changes:   changes,
+		State:     shrtn.State(),
+		CreatedAt: time.Now(),
+	})
+}
+
+// toggled describes a lifecycle revision that turned a flag on or off, with
+// the reason when there is one.
+func toggled(name string, on bool, reason string) domain.Changes {
+	changes := domain.Changes{name: {From: !on, To: on}}
+	if reason != "" {
+		changes[name+"_reason"] = domain.Change{From: "", To: reason}
+	}
+
+	return changes
+}
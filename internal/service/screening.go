@@ -0,0 +1,192 @@
+package service
+
+import (
+	"cc/internal/domain"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/screening"
+	"context"
+	"log"
+	"time"
+)
+
+const screeningBatch = 100
+
+type ScreeningService interface {
+	// Rescan screens every shorten that was not screened since the interval
+	// started and returns how many of them changed quarantine state.
+	Rescan(ctx context.Context, interval time.Duration) (int, error)
+	// Run calls Rescan every interval until ctx is done.
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type screeningService struct {
+	storage   storage.ShortenStorage
+	revisions storage.RevisionStorage
+	tx        storage.Transactor
+	screener  screening.Screener
+	cache     RedirectCache
+}
+
+func NewScreeningService(storage storage.ShortenStorage, revisions storage.RevisionStorage, tx storage.Transactor, screener screening.Screener, cache RedirectCache) ScreeningService {
+	return &screeningService{storage: storage, revisions: revisions, tx: tx, screener: screener, cache: cache}
+}
+
+func (service *screeningService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := service.Rescan(ctx, interval); err != nil {
+			log.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (service *screeningService) Rescan(ctx context.Context, interval time.Duration) (changed int, err error) {
+	if reloader, ok := service.screener.(screening.Reloader); ok {
+		if err = reloader.Reload(); err != nil {
+			log.Println(err)
+		}
+	}
+
+	before := time.Now().Add(-interval)
+
+	for {
+		var shrtns model.Shortens
+		shrtns, err = service.storage.SelectForScreening(ctx, before, screeningBatch)
+		if err != nil {
+			if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+				return changed, apperr.WithScope("screeningService.Rescan")
+			}
+
+			return
+		}
+
+		if len(shrtns) == 0 {
+			return changed, nil
+		}
+
+		var verdicts []screening.Verdict
+		verdicts, err = screenDestinations(ctx, service.screener, shrtns)
+		if err != nil {
+			return changed, apperror.Internal.WithError(err).WithScope("screeningService.Rescan")
+		}
+
+		now := time.Now()
+
+		var changedIDs []uint64
+		for i, shrtn := range shrtns {
+			quarantined := shrtn.QuarantinedAt != nil
+			applyVerdict(&shrtn, verdicts[i], now)
+
+			err = inTx(ctx, service.tx, func(ctx context.Context) error {
+				if err := service.storage.SetScreening(ctx, shrtn.ID, shrtn.QuarantinedAt, shrtn.QuarantineReason, now); err != nil {
+					return err
+				}
+
+				switch {
+				case !quarantined && shrtn.QuarantinedAt != nil:
+					return recordRevision(ctx, service.revisions, nil, shrtn, domain.RevisionQuarantine, toggled("quarantined", true, shrtn.QuarantineReason))
+				case quarantined && shrtn.QuarantinedAt == nil:
+					return recordRevision(ctx, service.revisions, nil, shrtn, domain.RevisionRelease, toggled("quarantined", false, ""))
+				}
+
+				return nil
+			})
+			if err != nil {
+				if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+					return changed, apperr.WithScope("screeningService.Rescan")
+				}
+
+				return
+			}
+
+			if quarantined != (shrtn.QuarantinedAt != nil) {
+				changed++
+				changedIDs = append(changedIDs, shrtn.ID)
+			}
+		}
+
+		service.cache.Invalidate(ctx, changedIDs...)
+	}
+}
+
+// screen records the screener's verdict on shrtn. Failures are only logged:
+// the shorten stays unscreened and is picked up by the next re-scan.
+func screen(ctx context.Context, screener screening.Screener, shrtn *model.Shorten) {
+	if screener == nil {
+		return
+	}
+
+	verdicts, err := screenDestinations(ctx, screener, model.Shortens{*shrtn})
+	if err != nil {
+		log.Println(err)
+		shrtn.ScreenedAt = nil
+		return
+	}
+
+	applyVerdict(shrtn, verdicts[0], time.Now())
+}
+
+// screenDestinations screens the URL, rule URLs and variant URLs of each
+// shorten in one call. A shorten's verdict is that of its first flagged
+// destination, since visitors may be sent to any of them.
+func screenDestinations(ctx context.Context, screener screening.Screener, shrtns model.Shortens) ([]screening.Verdict, error) {
+	var urls []string
+	owners := make([]int, 0, len(shrtns))
+	for i, shrtn := range shrtns {
+		for _, url := range destinations(shrtn) {
+			urls = append(urls, url)
+			owners = append(owners, i)
+		}
+	}
+
+	screened, err := screener.Screen(ctx, urls)
+	if err != nil {
+		return nil, err
+	}
+
+	verdicts := make([]screening.Verdict, len(shrtns))
+	for j, verdict := range screened {
+		if i := owners[j]; verdict.Flagged() && !verdicts[i].Flagged() {
+			verdicts[i] = verdict
+		}
+	}
+
+	return verdicts, nil
+}
+
+func destinations(shrtn model.Shorten) []string {
+	urls := []string{shrtn.URL}
+	for _, rule := range shrtn.Rules {
+		urls = append(urls, rule.URL)
+	}
+	for _, variant := range shrtn.Variants {
+		urls = append(urls, variant.URL)
+	}
+
+	return urls
+}
+
+func applyVerdict(shrtn *model.Shorten, verdict screening.Verdict, now time.Time) {
+	shrtn.ScreenedAt = &now
+
+	if !verdict.Flagged() {
+		shrtn.QuarantinedAt = nil
+		shrtn.QuarantineReason = ""
+		return
+	}
+
+	if shrtn.QuarantinedAt == nil {
+		shrtn.QuarantinedAt = &now
+	}
+	shrtn.QuarantineReason = verdict.Threat
+}
@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"cc/internal/domain"
+	"cc/internal/model"
+	"cc/internal/service"
+	"cc/mock/storage"
+	"cc/pkg/screening"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type redirectCache struct {
+	service.RedirectCache
+	invalidated []uint64
+}
+
+func (cache *redirectCache) Invalidate(_ context.Context, shortenIDs ...uint64) {
+	cache.invalidated = append(cache.invalidated, shortenIDs...)
+}
+
+func TestScreeningService_Rescan(t *testing.T) {
+	shrtns := model.Shortens{
+		{ID: 1, URL: "https://example.com"},
+		{ID: 2, URL: "https://example.com", Rules: domain.Rules{{Name: "ios", URL: "https://evil.example/app"}}},
+		{ID: 3, URL: "https://example.com", Variants: domain.Variants{{Name: "b", URL: "https://evil.example/b", Weight: 1}}},
+	}
+
+	quarantined := make(map[uint64]string)
+	screened := false
+	shortenStorage := &storage.ShortenStorageMock{
+		SelectForScreeningFunc: func(context.Context, time.Time, int) (model.Shortens, error) {
+			if screened {
+				return nil, nil
+			}
+
+			screened = true
+			return shrtns, nil
+		},
+		SetScreeningFunc: func(_ context.Context, shortenID uint64, quarantinedAt *time.Time, reason string, _ time.Time) error {
+			if quarantinedAt != nil {
+				quarantined[shortenID] = reason
+			}
+			return nil
+		},
+	}
+
+	cache := &redirectCache{}
+	screeningService := service.NewScreeningService(shortenStorage, nil, nil, screening.NewBlocklist("evil.example"), cache)
+
+	changed, err := screeningService.Rescan(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, changed)
+	assert.Equal(t, []uint64{2, 3}, cache.invalidated)
+	assert.Len(t, quarantined, 2)
+	assert.NotEmpty(t, quarantined[2])
+	assert.NotEmpty(t, quarantined[3])
+}
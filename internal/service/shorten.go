@@ -7,6 +7,7 @@ import (
 	"cc/internal/storage"
 	"cc/pkg/apperror"
 	"cc/pkg/base62"
+	"cc/pkg/screening"
 	"context"
 	"github.com/google/uuid"
 	"github.com/goware/urlx"
@@ -18,19 +19,38 @@ type ShortenService interface {
 	Create(ctx context.Context, userID uuid.UUID, request dto.CreateShorten) (domain.Shorten, error)
 	Delete(ctx context.Context, userID uuid.UUID, shortenID uint64) error
 	Update(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.UpdateShorten) (domain.Shorten, error)
-	GetByID(ctx context.Context, shortenID uint64) (domain.Shorten, error)
+	GetByID(ctx context.Context, userID uuid.UUID, shortenID uint64) (domain.Shorten, error)
 	SelectByUser(ctx context.Context, userID uuid.UUID) (domain.Shortens, error)
 	SelectByTags(ctx context.Context, userID uuid.UUID, tags []string) (domain.Shortens, error)
-	GetURL(ctx context.Context, shortenID uint64) (string, error)
+	SelectByWorkspace(ctx context.Context, userID, workspaceID uuid.UUID, tags []string) (domain.Shortens, error)
+	SelectByHealth(ctx context.Context, userID uuid.UUID, status domain.HealthStatus, tags []string) (domain.Shortens, error)
+	SetRules(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.SetRules) (domain.Shorten, error)
+	SetVariants(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.SetVariants) (domain.Shorten, error)
+	GetRedirect(ctx context.Context, shortenID uint64) (domain.Redirect, error)
+	// Trash lists the deleted shortens of a workspace that have not been
+	// purged yet.
+	Trash(ctx context.Context, userID, workspaceID uuid.UUID) (domain.Shortens, error)
+	Restore(ctx context.Context, userID uuid.UUID, shortenID uint64) (domain.Shorten, error)
+	History(ctx context.Context, userID uuid.UUID, shortenID uint64) (domain.Revisions, error)
+	Rollback(ctx context.Context, userID uuid.UUID, shortenID uint64, revisionID int64) (domain.Shorten, error)
 }
 
 type shortenService struct {
-	storage   storage.ShortenStorage
-	domainURL string
+	storage    storage.ShortenStorage
+	revisions  storage.RevisionStorage
+	tx         storage.Transactor
+	audit      AuditService
+	authorizer Authorizer
+	screener   screening.Screener
+	previewer  PreviewService
+	cache      RedirectCache
+	domainURL  string
 }
 
-func NewShortenService(storage storage.ShortenStorage, domainURL string) ShortenService {
-	return &shortenService{storage: storage, domainURL: domainURL}
+// NewShortenService keeps the history of shortens in revisions. With tx, each
+// change and its revision are written in one transaction.
+func NewShortenService(storage storage.ShortenStorage, revisions storage.RevisionStorage, tx storage.Transactor, audit AuditService, authorizer Authorizer, screener screening.Screener, previewer PreviewService, cache RedirectCache, domainURL string) ShortenService {
+	return &shortenService{storage: storage, revisions: revisions, tx: tx, audit: audit, authorizer: authorizer, screener: screener, previewer: previewer, cache: cache, domainURL: domainURL}
 }
 
 func (service *shortenService) Create(ctx context.Context, userID uuid.UUID, request dto.CreateShorten) (shorten domain.Shorten, err error) {
@@ -46,6 +66,18 @@ func (service *shortenService) Create(ctx context.Context, userID uuid.UUID, req
 		return shorten, apperror.BadRequest.WithMessage("invalid url")
 	}
 
+	// Shortens created without an explicit workspace go to the personal one,
+	// which shares its id with the user.
+	workspaceID := request.WorkspaceID
+	if workspaceID == uuid.Nil {
+		workspaceID = userID
+	}
+
+	err = service.authorizer.Authorize(ctx, userID, workspaceID, domain.RoleEditor)
+	if err != nil {
+		return
+	}
+
 	var id uint64
 	if request.Key != "" {
 		id, err = base62.Decode(request.Key)
@@ -54,7 +86,7 @@ func (service *shortenService) Create(ctx context.Context, userID uuid.UUID, req
 		}
 
 		var exists bool
-		exists, err = service.storage.ExistsByID(ctx, userID, id)
+		exists, err = service.storage.ExistsByID(ctx, id)
 		if err != nil {
 			if apperr, ok := apperror.Is(err, apperror.Internal); ok {
 				return shorten, apperr.WithScope("shortenService.Create")
@@ -68,7 +100,7 @@ func (service *shortenService) Create(ctx context.Context, userID uuid.UUID, req
 	} else {
 		for exists := true; exists; {
 			id = uint64(rand.Uint32())
-			exists, err = service.storage.ExistsByID(ctx, userID, id)
+			exists, err = service.storage.ExistsByID(ctx, id)
 			if err != nil {
 				if apperr, ok := apperror.Is(err, apperror.Internal); ok {
 					return shorten, apperr.WithScope("shortenService.ExistsByID")
@@ -88,6 +120,7 @@ func (service *shortenService) Create(ctx context.Context, userID uuid.UUID, req
 
 		return
 	}
+
 	if exists {
 		return shorten, apperror.AlreadyExists.WithMessage("url already exist")
 	}
@@ -100,15 +133,48 @@ func (service *shortenService) Create(ctx context.Context, userID uuid.UUID, req
 	now := time.Now()
 
 	shrtn := model.Shorten{
-		ID:        id,
-		UserID:    userID,
-		Title:     request.Title,
-		URL:       request.URL,
-		Tags:      []string{},
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:          id,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Title:       request.Title,
+		URL:         request.URL,
+		Tags:        []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if request.OpenGraph != nil {
+		shrtn.OpenGraph = model.OpenGraph(*request.OpenGraph)
+	}
+
+	shrtn.RedirectType = request.RedirectType
+	if shrtn.RedirectType == "" {
+		shrtn.RedirectType = domain.RedirectFound
+	}
+	shrtn.PixelURL = request.PixelURL
+
+	if request.DeepLink != nil {
+		shrtn.DeepLink = *request.DeepLink
 	}
-	err = service.storage.Create(ctx, shrtn)
+
+	if request.ExpiresAt != 0 {
+		expiresAt := time.Unix(request.ExpiresAt, 0)
+		shrtn.ExpiresAt = &expiresAt
+	}
+
+	err = rejectPermanent(shrtn)
+	if err != nil {
+		return
+	}
+
+	screen(ctx, service.screener, &shrtn)
+
+	err = inTx(ctx, service.tx, func(ctx context.Context) error {
+		if err := service.storage.Create(ctx, shrtn); err != nil {
+			return err
+		}
+
+		return service.record(ctx, userID, shrtn.ID, domain.RevisionCreate, domain.ShortenState{}, shrtn.State())
+	})
 	if err != nil {
 		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
 			return shorten, apperr.WithScope("shortenService.Create")
@@ -117,10 +183,25 @@ func (service *shortenService) Create(ctx context.Context, userID uuid.UUID, req
 		return
 	}
 
+	// The key may have been cached as unknown.
+	service.invalidate(ctx, shrtn.ID)
+
+	after := shrtn.State()
+	service.auditShorten(ctx, userID, domain.AuditShortenCreate, shrtn.ID, nil, &after)
+
+	if service.previewer != nil {
+		service.previewer.Enqueue(shrtn.ID)
+	}
+
 	return shrtn.Domain(service.domainURL), nil
 }
 
 func (service *shortenService) Update(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.UpdateShorten) (shorten domain.Shorten, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleEditor)
+	if err != nil {
+		return
+	}
+
 	var shrtn model.Shorten
 	shrtn, err = service.storage.GetByID(ctx, shortenID)
 	if err != nil {
@@ -131,25 +212,68 @@ func (service *shortenService) Update(ctx context.Context, userID uuid.UUID, sho
 		return
 	}
 
-	if shrtn.UserID != userID {
-		return shorten, apperror.BadRequest.WithMessage("you don't have access to this shorten")
-	}
+	before := shrtn.State()
 
 	if request.Title != "" {
 		shrtn.Title = request.Title
 	}
 
-	if request.URL != "" {
+	urlChanged := request.URL != "" && request.URL != shrtn.URL
+	if urlChanged {
 		shrtn.URL = request.URL
+		shrtn.Health = model.Health{Status: string(domain.HealthUnknown)}
+		screen(ctx, service.screener, &shrtn)
 	}
 
 	if len(request.Tags) != 0 {
 		shrtn.Tags = request.Tags
 	}
 
+	if request.OpenGraph != nil {
+		shrtn.OpenGraph = model.OpenGraph(*request.OpenGraph)
+	}
+
+	if request.RedirectType != "" {
+		shrtn.RedirectType = request.RedirectType
+	}
+
+	if request.PixelURL != nil {
+		shrtn.PixelURL = *request.PixelURL
+	}
+
+	if request.DeepLink != nil {
+		shrtn.DeepLink = *request.DeepLink
+	}
+
+	if request.ExpiresAt != nil {
+		shrtn.ExpiresAt = nil
+		if *request.ExpiresAt != 0 {
+			expiresAt := time.Unix(*request.ExpiresAt, 0)
+			shrtn.ExpiresAt = &expiresAt
+		}
+	}
+
+	err = rejectPermanent(shrtn)
+	if err != nil {
+		return
+	}
+
 	shrtn.UpdatedAt = time.Now()
 
-	err = service.storage.Update(ctx, shrtn)
+	err = inTx(ctx, service.tx, func(ctx context.Context) error {
+		if err := service.storage.Update(ctx, shrtn); err != nil {
+			return err
+		}
+
+		// The last check was for the old destination.
+		if urlChanged {
+			if err := service.storage.SetHealth(ctx, shortenID, shrtn.Health); err != nil {
+				return err
+			}
+		}
+
+		return service.record(ctx, userID, shortenID, domain.RevisionUpdate, before, shrtn.State())
+	})
 	if err != nil {
 		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
 			return shorten, apperr.WithScope("shortenService.Update")
@@ -158,26 +282,120 @@ func (service *shortenService) Update(ctx context.Context, userID uuid.UUID, sho
 		return
 	}
 
+	service.invalidate(ctx, shortenID)
+
+	after := shrtn.State()
+	service.auditShorten(ctx, userID, domain.AuditShortenUpdate, shortenID, &before, &after)
+
+	if urlChanged && service.previewer != nil {
+		service.previewer.Enqueue(shortenID)
+	}
+
 	return shrtn.Domain(service.domainURL), nil
 }
 
 func (service *shortenService) Delete(ctx context.Context, userID uuid.UUID, shortenID uint64) (err error) {
-	var exists bool
-	exists, err = service.storage.ExistsByID(ctx, userID, shortenID)
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleEditor)
+	if err != nil {
+		return
+	}
+
+	var shrtn model.Shorten
+	shrtn, err = service.storage.GetByID(ctx, shortenID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("shortenService.Delete.GetByID")
+		}
+
+		return
+	}
+
+	err = inTx(ctx, service.tx, func(ctx context.Context) error {
+		if err := service.storage.Delete(ctx, shortenID, time.Now()); err != nil {
+			return err
+		}
+
+		return recordRevision(ctx, service.revisions, &userID, shrtn, domain.RevisionDelete, toggled("deleted", true, ""))
+	})
 	if err != nil {
 		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
 			return apperr.WithScope("shortenService.Delete")
 		}
 
 		return
-	} else if !exists {
-		return apperror.NotFound.WithMessage("shorten with this id does not exist")
 	}
 
-	return service.storage.Delete(ctx, userID, shortenID)
+	service.invalidate(ctx, shortenID)
+
+	before := shrtn.State()
+	service.auditShorten(ctx, userID, domain.AuditShortenDelete, shortenID, &before, nil)
+
+	return
 }
 
-func (service *shortenService) GetByID(ctx context.Context, id uint64) (shorten domain.Shorten, err error) {
+func (service *shortenService) Trash(ctx context.Context, userID, workspaceID uuid.UUID) (shortens domain.Shortens, err error) {
+	err = service.authorizer.Authorize(ctx, userID, workspaceID, domain.RoleViewer)
+	if err != nil {
+		return
+	}
+
+	var shrtns model.Shortens
+	shrtns, err = service.storage.SelectDeleted(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shortens, apperr.WithScope("shortenService.Trash")
+		}
+
+		return
+	}
+
+	return shrtns.Domain(service.domainURL), nil
+}
+
+// Restore takes a shorten out of the trash. It fails while another live
+// shorten of the user has the same destination.
+func (service *shortenService) Restore(ctx context.Context, userID uuid.UUID, shortenID uint64) (shorten domain.Shorten, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleEditor)
+	if err != nil {
+		return
+	}
+
+	var shrtn model.Shorten
+	err = inTx(ctx, service.tx, func(ctx context.Context) (err error) {
+		if err = service.storage.Restore(ctx, shortenID); err != nil {
+			return
+		}
+
+		shrtn, err = service.storage.GetByID(ctx, shortenID)
+		if err != nil {
+			return
+		}
+
+		return recordRevision(ctx, service.revisions, &userID, shrtn, domain.RevisionRestore, toggled("deleted", false, ""))
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.Restore")
+		}
+
+		return
+	}
+
+	// The key has been cached as unknown since it was deleted.
+	service.invalidate(ctx, shortenID)
+
+	after := shrtn.State()
+	service.auditShorten(ctx, userID, domain.AuditShortenRestore, shortenID, nil, &after)
+
+	return shrtn.Domain(service.domainURL), nil
+}
+
+func (service *shortenService) GetByID(ctx context.Context, userID uuid.UUID, id uint64) (shorten domain.Shorten, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, id, domain.RoleViewer)
+	if err != nil {
+		return
+	}
+
 	var shrtn model.Shorten
 	shrtn, err = service.storage.GetByID(ctx, id)
 	if err != nil {
@@ -191,17 +409,22 @@ func (service *shortenService) GetByID(ctx context.Context, id uint64) (shorten
 	return shrtn.Domain(service.domainURL), nil
 }
 
-func (service *shortenService) GetURL(ctx context.Context, shortenID uint64) (url string, err error) {
-	url, err = service.storage.GetURL(ctx, shortenID)
+func (service *shortenService) GetRedirect(ctx context.Context, shortenID uint64) (redirect domain.Redirect, err error) {
+	if service.cache != nil {
+		return service.cache.Get(ctx, shortenID)
+	}
+
+	var rdrct model.Redirect
+	rdrct, err = service.storage.GetRedirect(ctx, shortenID)
 	if err != nil {
 		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
-			return url, apperr.WithScope("shortenService.GetURL")
+			return redirect, apperr.WithScope("shortenService.GetRedirect")
 		}
 
 		return
 	}
 
-	return
+	return rdrct.Domain(), nil
 }
 
 func (service *shortenService) SelectByUser(ctx context.Context, userID uuid.UUID) (shortens domain.Shortens, err error) {
@@ -215,6 +438,15 @@ func (service *shortenService) SelectByUser(ctx context.Context, userID uuid.UUI
 		return
 	}
 
+	shrtns, err = service.accessible(ctx, userID, shrtns)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shortens, apperr.WithScope("shortenService.SelectByUser.accessible")
+		}
+
+		return
+	}
+
 	return shrtns.Domain(service.domainURL), nil
 }
 
@@ -229,5 +461,435 @@ func (service *shortenService) SelectByTags(ctx context.Context, userID uuid.UUI
 		return
 	}
 
+	entities, err = service.accessible(ctx, userID, entities)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shortens, apperr.WithScope("shortenService.SelectByTags.accessible")
+		}
+
+		return
+	}
+
 	return entities.Domain(service.domainURL), nil
 }
+
+func (service *shortenService) SelectByWorkspace(ctx context.Context, userID, workspaceID uuid.UUID, tags []string) (shortens domain.Shortens, err error) {
+	err = service.authorizer.Authorize(ctx, userID, workspaceID, domain.RoleViewer)
+	if err != nil {
+		return
+	}
+
+	var shrtns model.Shortens
+	shrtns, err = service.storage.SelectByWorkspace(ctx, workspaceID, tags)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shortens, apperr.WithScope("shortenService.SelectByWorkspace")
+		}
+
+		return
+	}
+
+	return shrtns.Domain(service.domainURL), nil
+}
+
+func (service *shortenService) SelectByHealth(ctx context.Context, userID uuid.UUID, status domain.HealthStatus, tags []string) (shortens domain.Shortens, err error) {
+	var shrtns model.Shortens
+	shrtns, err = service.storage.SelectByHealth(ctx, userID, string(status), tags)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shortens, apperr.WithScope("shortenService.SelectByHealth")
+		}
+
+		return
+	}
+
+	shrtns, err = service.accessible(ctx, userID, shrtns)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shortens, apperr.WithScope("shortenService.SelectByHealth.accessible")
+		}
+
+		return
+	}
+
+	return shrtns.Domain(service.domainURL), nil
+}
+
+// SetRules replaces the redirect rules. Rule destinations are screened like
+// the shorten's own and flagged ones are rejected.
+func (service *shortenService) SetRules(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.SetRules) (shorten domain.Shorten, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleEditor)
+	if err != nil {
+		return
+	}
+
+	names := make([]string, len(request.Rules))
+	urls := make([]string, len(request.Rules))
+	for i, rule := range request.Rules {
+		names[i], urls[i] = "rule "+rule.Name, rule.URL
+	}
+
+	err = service.rejectUnsafe(ctx, names, urls)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.SetRules.rejectUnsafe")
+		}
+
+		return
+	}
+
+	var shrtn model.Shorten
+	shrtn, err = service.storage.GetByID(ctx, shortenID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.SetRules.GetByID")
+		}
+
+		return
+	}
+
+	changed := shrtn
+	changed.Rules = request.Rules
+	err = rejectPermanent(changed)
+	if err != nil {
+		return
+	}
+
+	before := shrtn.State()
+
+	err = inTx(ctx, service.tx, func(ctx context.Context) (err error) {
+		if err = service.storage.SetRules(ctx, shortenID, request.Rules, time.Now()); err != nil {
+			return
+		}
+
+		shrtn, err = service.storage.GetByID(ctx, shortenID)
+		if err != nil {
+			return
+		}
+
+		return service.record(ctx, userID, shortenID, domain.RevisionRules, before, shrtn.State())
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.SetRules")
+		}
+
+		return
+	}
+
+	service.invalidate(ctx, shortenID)
+
+	after := shrtn.State()
+	service.auditShorten(ctx, userID, domain.AuditShortenRules, shortenID, &before, &after)
+
+	return shrtn.Domain(service.domainURL), nil
+}
+
+// SetVariants replaces the split test variants, an empty list ends the test.
+func (service *shortenService) SetVariants(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.SetVariants) (shorten domain.Shorten, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleEditor)
+	if err != nil {
+		return
+	}
+
+	names := make([]string, len(request.Variants))
+	urls := make([]string, len(request.Variants))
+	for i, variant := range request.Variants {
+		names[i], urls[i] = "variant "+variant.Name, variant.URL
+	}
+
+	err = service.rejectUnsafe(ctx, names, urls)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.SetVariants.rejectUnsafe")
+		}
+
+		return
+	}
+
+	var shrtn model.Shorten
+	shrtn, err = service.storage.GetByID(ctx, shortenID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.SetVariants.GetByID")
+		}
+
+		return
+	}
+
+	changed := shrtn
+	changed.Variants = request.Variants
+	err = rejectPermanent(changed)
+	if err != nil {
+		return
+	}
+
+	before := shrtn.State()
+
+	err = inTx(ctx, service.tx, func(ctx context.Context) (err error) {
+		if err = service.storage.SetVariants(ctx, shortenID, request.Variants, time.Now()); err != nil {
+			return
+		}
+
+		shrtn, err = service.storage.GetByID(ctx, shortenID)
+		if err != nil {
+			return
+		}
+
+		return service.record(ctx, userID, shortenID, domain.RevisionVariants, before, shrtn.State())
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.SetVariants")
+		}
+
+		return
+	}
+
+	service.invalidate(ctx, shortenID)
+
+	after := shrtn.State()
+	service.auditShorten(ctx, userID, domain.AuditShortenVariants, shortenID, &before, &after)
+
+	return shrtn.Domain(service.domainURL), nil
+}
+
+// rejectPermanent refuses 301 and 308 on shortens that choose a destination
+// per visit. Browsers cache permanent redirects and would skip the rules,
+// split test or deep link page on later visits.
+func rejectPermanent(shrtn model.Shorten) error {
+	if !shrtn.RedirectType.Permanent() {
+		return nil
+	}
+
+	switch {
+	case len(shrtn.Rules) > 0:
+		return apperror.BadRequest.WithMessage("permanent redirects cannot be used with rules")
+	case len(shrtn.Variants) > 0:
+		return apperror.BadRequest.WithMessage("permanent redirects cannot be used with a split test")
+	case !shrtn.DeepLink.Empty():
+		return apperror.BadRequest.WithMessage("permanent redirects cannot be used with deep links")
+	}
+
+	return nil
+}
+
+// rejectUnsafe screens extra destinations of a shorten and fails with the
+// name of the first flagged one.
+func (service *shortenService) rejectUnsafe(ctx context.Context, names, urls []string) error {
+	if service.screener == nil || len(urls) == 0 {
+		return nil
+	}
+
+	verdicts, err := service.screener.Screen(ctx, urls)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	for i, verdict := range verdicts {
+		if verdict.Flagged() {
+			return apperror.BadRequest.WithMessage("url of " + names[i] + " has been reported as unsafe")
+		}
+	}
+
+	return nil
+}
+
+// accessible drops the shortens the user created in workspaces they can no
+// longer view, e.g. after leaving them. Each workspace is authorized once.
+func (service *shortenService) accessible(ctx context.Context, userID uuid.UUID, shrtns model.Shortens) (model.Shortens, error) {
+	allowed := make(map[uuid.UUID]bool)
+
+	res := make(model.Shortens, 0, len(shrtns))
+	for _, shrtn := range shrtns {
+		ok, seen := allowed[shrtn.WorkspaceID]
+		if !seen {
+			err := service.authorizer.Authorize(ctx, userID, shrtn.WorkspaceID, domain.RoleViewer)
+			if _, internal := apperror.Is(err, apperror.Internal); internal {
+				return nil, err
+			}
+
+			ok = err == nil
+			allowed[shrtn.WorkspaceID] = ok
+		}
+
+		if ok {
+			res = append(res, shrtn)
+		}
+	}
+
+	return res, nil
+}
+
+func (service *shortenService) invalidate(ctx context.Context, shortenIDs ...uint64) {
+	if service.cache != nil {
+		service.cache.Invalidate(ctx, shortenIDs...)
+	}
+}
+
+func (service *shortenService) History(ctx context.Context, userID uuid.UUID, shortenID uint64) (revisions domain.Revisions, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleViewer)
+	if err != nil {
+		return
+	}
+
+	var rvsns model.Revisions
+	rvsns, err = service.revisions.SelectByShorten(ctx, shortenID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return revisions, apperr.WithScope("shortenService.History")
+		}
+
+		return
+	}
+
+	return rvsns.Domain(), nil
+}
+
+// Rollback restores the state a shorten had after the given revision. The
+// rollback is a revision of its own, so it can be undone the same way.
+func (service *shortenService) Rollback(ctx context.Context, userID uuid.UUID, shortenID uint64, revisionID int64) (shorten domain.Shorten, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleEditor)
+	if err != nil {
+		return
+	}
+
+	var revision model.Revision
+	revision, err = service.revisions.GetByID(ctx, shortenID, revisionID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.Rollback.GetRevision")
+		}
+
+		return
+	}
+
+	var shrtn model.Shorten
+	shrtn, err = service.storage.GetByID(ctx, shortenID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.Rollback.GetByID")
+		}
+
+		return
+	}
+
+	before := shrtn.State()
+
+	urlChanged := revision.State.URL != shrtn.URL
+	shrtn.Restore(revision.State)
+	if urlChanged {
+		shrtn.Health = model.Health{Status: string(domain.HealthUnknown)}
+		screen(ctx, service.screener, &shrtn)
+	}
+
+	err = rejectPermanent(shrtn)
+	if err != nil {
+		return
+	}
+
+	// Rule and variant destinations may have been reported since.
+	var names, urls []string
+	for _, rule := range shrtn.Rules {
+		names, urls = append(names, "rule "+rule.Name), append(urls, rule.URL)
+	}
+	for _, variant := range shrtn.Variants {
+		names, urls = append(names, "variant "+variant.Name), append(urls, variant.URL)
+	}
+
+	err = service.rejectUnsafe(ctx, names, urls)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.Rollback.rejectUnsafe")
+		}
+
+		return
+	}
+
+	shrtn.UpdatedAt = time.Now()
+
+	err = inTx(ctx, service.tx, func(ctx context.Context) error {
+		if err := service.storage.Update(ctx, shrtn); err != nil {
+			return err
+		}
+
+		if err := service.storage.SetRules(ctx, shortenID, shrtn.Rules, shrtn.UpdatedAt); err != nil {
+			return err
+		}
+
+		if err := service.storage.SetVariants(ctx, shortenID, shrtn.Variants, shrtn.UpdatedAt); err != nil {
+			return err
+		}
+
+		if urlChanged {
+			if err := service.storage.SetHealth(ctx, shortenID, shrtn.Health); err != nil {
+				return err
+			}
+		}
+
+		return service.record(ctx, userID, shortenID, domain.RevisionRollback, before, shrtn.State())
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return shorten, apperr.WithScope("shortenService.Rollback")
+		}
+
+		return
+	}
+
+	service.invalidate(ctx, shortenID)
+
+	after := shrtn.State()
+	service.auditShorten(ctx, userID, domain.AuditShortenRollback, shortenID, &before, &after)
+
+	if urlChanged && service.previewer != nil {
+		service.previewer.Enqueue(shortenID)
+	}
+
+	return shrtn.Domain(service.domainURL), nil
+}
+
+// record appends a revision unless nothing changed. Shortens created before
+// history was kept get their first revision on their next change.
+func (service *shortenService) record(ctx context.Context, userID uuid.UUID, shortenID uint64, action domain.RevisionAction, before, after domain.ShortenState) error {
+	if service.revisions == nil {
+		return nil
+	}
+
+	changes := before.Diff(after)
+	if len(changes) == 0 && action != domain.RevisionCreate {
+		return nil
+	}
+
+	return service.revisions.Create(ctx, model.Revision{
+		ShortenID: shortenID,
+		UserID:    &userID,
+		Action:    action,
+		Changes:   changes,
+		State:     after,
+		CreatedAt: time.Now(),
+	})
+}
+
+// auditShorten records a change of a shorten in the audit log. A nil state
+// is stored as null, e.g. before a create.
+func (service *shortenService) auditShorten(ctx context.Context, userID uuid.UUID, action string, shortenID uint64, before, after *domain.ShortenState) {
+	if service.audit == nil {
+		return
+	}
+
+	entry := dto.AuditEntry{
+		ActorID:    &userID,
+		Action:     action,
+		TargetType: domain.TargetShorten,
+		TargetID:   base62.Encode(shortenID),
+	}
+	if before != nil {
+		entry.Before = before
+	}
+	if after != nil {
+		entry.After = after
+	}
+
+	service.audit.Record(ctx, entry)
+}
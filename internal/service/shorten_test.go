@@ -5,20 +5,35 @@ import (
 	"cc/internal/dto"
 	"cc/internal/model"
 	"cc/internal/service"
+	st "cc/internal/storage"
 	"cc/mock/storage"
 	"cc/pkg/apperror"
+	"cc/pkg/base62"
+	"cc/pkg/screening"
 	"context"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var (
 	domainURL = "localhost:8080"
 )
 
+// authorizer grants every role, access control is covered by WorkspaceService.
+type authorizer struct{}
+
+func (authorizer) Authorize(context.Context, uuid.UUID, uuid.UUID, domain.Role) error {
+	return nil
+}
+
+func (authorizer) AuthorizeShorten(context.Context, uuid.UUID, uint64, domain.Role) error {
+	return nil
+}
+
 type Test struct {
 	name        string
 	storage     *storage.ShortenStorageMock
@@ -61,7 +76,7 @@ func TestShortenService_Create(t *testing.T) {
 			name: "default success",
 			storage: &storage.ShortenStorageMock{
 				CreateFunc:      func(ctx context.Context, shorten model.Shorten) error { return nil },
-				ExistsByIDFunc:  func(ctx context.Context, userID uuid.UUID, id uint64) (bool, error) { return false, nil },
+				ExistsByIDFunc:  func(ctx context.Context, id uint64) (bool, error) { return false, nil },
 				ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) { return false, nil },
 			},
 			req: dto.CreateShorten{
@@ -77,7 +92,7 @@ func TestShortenService_Create(t *testing.T) {
 			name: "with key success",
 			storage: &storage.ShortenStorageMock{
 				CreateFunc:      func(ctx context.Context, shorten model.Shorten) error { return nil },
-				ExistsByIDFunc:  func(ctx context.Context, userID uuid.UUID, id uint64) (bool, error) { return false, nil },
+				ExistsByIDFunc:  func(ctx context.Context, id uint64) (bool, error) { return false, nil },
 				ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) { return false, nil },
 			},
 			req: dto.CreateShorten{
@@ -94,7 +109,7 @@ func TestShortenService_Create(t *testing.T) {
 			name: "with title success",
 			storage: &storage.ShortenStorageMock{
 				CreateFunc:      func(ctx context.Context, shorten model.Shorten) error { return nil },
-				ExistsByIDFunc:  func(ctx context.Context, userID uuid.UUID, id uint64) (bool, error) { return false, nil },
+				ExistsByIDFunc:  func(ctx context.Context, id uint64) (bool, error) { return false, nil },
 				ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) { return false, nil },
 			},
 			req: dto.CreateShorten{
@@ -107,11 +122,45 @@ func TestShortenService_Create(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "with open graph success",
+			storage: &storage.ShortenStorageMock{
+				CreateFunc:      func(ctx context.Context, shorten model.Shorten) error { return nil },
+				ExistsByIDFunc:  func(ctx context.Context, id uint64) (bool, error) { return false, nil },
+				ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) { return false, nil },
+			},
+			req: dto.CreateShorten{
+				URL:       "https://www.google.com",
+				OpenGraph: &dto.OpenGraph{Title: "Search", Image: "https://cdn.example.com/card.png"},
+			},
+			want: domain.Shorten{
+				LongURL:   "https://www.google.com",
+				OpenGraph: domain.OpenGraph{Title: "Search", Image: "https://cdn.example.com/card.png"},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "blocklisted url is quarantined",
+			storage: &storage.ShortenStorageMock{
+				CreateFunc:      func(ctx context.Context, shorten model.Shorten) error { return nil },
+				ExistsByIDFunc:  func(ctx context.Context, id uint64) (bool, error) { return false, nil },
+				ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) { return false, nil },
+			},
+			req: dto.CreateShorten{
+				URL: "https://login.evil.example",
+			},
+			want: domain.Shorten{
+				LongURL:          "https://login.evil.example",
+				Quarantined:      true,
+				QuarantineReason: "BLOCKLISTED",
+			},
+			expectedErr: nil,
+		},
 		{
 			name: "id already exists",
 			storage: &storage.ShortenStorageMock{
 				CreateFunc:      func(ctx context.Context, shorten model.Shorten) error { return nil },
-				ExistsByIDFunc:  func(ctx context.Context, userID uuid.UUID, id uint64) (bool, error) { return true, nil },
+				ExistsByIDFunc:  func(ctx context.Context, id uint64) (bool, error) { return true, nil },
 				ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) { return false, nil },
 			},
 			req: dto.CreateShorten{
@@ -125,7 +174,7 @@ func TestShortenService_Create(t *testing.T) {
 			name: "url already exists",
 			storage: &storage.ShortenStorageMock{
 				CreateFunc:      func(ctx context.Context, shorten model.Shorten) error { return nil },
-				ExistsByIDFunc:  func(ctx context.Context, userID uuid.UUID, id uint64) (bool, error) { return false, nil },
+				ExistsByIDFunc:  func(ctx context.Context, id uint64) (bool, error) { return false, nil },
 				ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) { return true, nil },
 			},
 			req: dto.CreateShorten{
@@ -138,7 +187,7 @@ func TestShortenService_Create(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			s := service.NewShortenService(test.storage, domainURL)
+			s := service.NewShortenService(test.storage, nil, nil, nil, authorizer{}, screening.NewBlocklist("evil.example"), nil, nil, domainURL)
 			got, err := s.Create(context.Background(), uuid.New(), test.req)
 			if err != nil && test.expectedErr == nil {
 				t.Errorf("unexpected error: %v", err)
@@ -163,3 +212,155 @@ func TestShortenService_Create(t *testing.T) {
 		})
 	}
 }
+
+// workspaceAuthorizer only lets users into the listed workspaces.
+type workspaceAuthorizer map[uuid.UUID]bool
+
+func (workspaces workspaceAuthorizer) Authorize(_ context.Context, _ uuid.UUID, workspaceID uuid.UUID, _ domain.Role) error {
+	if !workspaces[workspaceID] {
+		return apperror.Forbidden
+	}
+
+	return nil
+}
+
+func (workspaces workspaceAuthorizer) AuthorizeShorten(context.Context, uuid.UUID, uint64, domain.Role) error {
+	return apperror.Forbidden
+}
+
+func TestShortenService_SelectByUser(t *testing.T) {
+	userID, joined, left := uuid.New(), uuid.New(), uuid.New()
+
+	shortenStorage := &storage.ShortenStorageMock{
+		SelectByUserFunc: func(ctx context.Context, id uuid.UUID) (model.Shortens, error) {
+			return model.Shortens{
+				{ID: 1, UserID: id, WorkspaceID: joined},
+				{ID: 2, UserID: id, WorkspaceID: left},
+				{ID: 3, UserID: id, WorkspaceID: joined},
+			}, nil
+		},
+	}
+
+	s := service.NewShortenService(shortenStorage, nil, nil, nil, workspaceAuthorizer{joined: true}, nil, nil, nil, domainURL)
+
+	shortens, err := s.SelectByUser(context.Background(), userID)
+	assert.NoError(t, err)
+	if assert.Len(t, shortens, 2, "shortens in workspaces the user left are hidden") {
+		assert.Equal(t, base62.Encode(1), shortens[0].ID)
+		assert.Equal(t, base62.Encode(3), shortens[1].ID)
+	}
+}
+
+// revisionStorage serves a single revision and fails to create new ones when
+// told to. Other methods are not expected to be called.
+type revisionStorage struct {
+	st.RevisionStorage
+	revision model.Revision
+	fail     bool
+}
+
+func (storage revisionStorage) GetByID(context.Context, uint64, int64) (model.Revision, error) {
+	return storage.revision, nil
+}
+
+func (storage revisionStorage) Create(context.Context, model.Revision) error {
+	if storage.fail {
+		return apperror.Internal.WithError(errors.New("insert failed"))
+	}
+
+	return nil
+}
+
+// transactor remembers whether the last transaction was committed.
+type transactor struct {
+	committed *bool
+}
+
+func (tx transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	*tx.committed = err == nil
+
+	return err
+}
+
+func TestShortenService_UpdateFailedRevision(t *testing.T) {
+	shortenStorage := &storage.ShortenStorageMock{
+		GetByIDFunc: func(ctx context.Context, id uint64) (model.Shorten, error) {
+			return model.Shorten{ID: id, URL: "https://example.com", Tags: []string{}}, nil
+		},
+		UpdateFunc: func(ctx context.Context, shorten model.Shorten) error { return nil },
+	}
+
+	committed := true
+	s := service.NewShortenService(shortenStorage, revisionStorage{fail: true}, transactor{&committed}, nil, authorizer{}, nil, nil, nil, domainURL)
+
+	_, err := s.Update(context.Background(), uuid.New(), 1, dto.UpdateShorten{Title: "Example"})
+	assert.ErrorIs(t, err, apperror.Internal)
+	assert.False(t, committed, "the update is rolled back with its revision")
+}
+
+func TestShortenService_RollbackScreensRules(t *testing.T) {
+	shortenStorage := &storage.ShortenStorageMock{
+		GetByIDFunc: func(ctx context.Context, id uint64) (model.Shorten, error) {
+			return model.Shorten{ID: id, URL: "https://example.com", Tags: []string{}}, nil
+		},
+	}
+
+	revisions := revisionStorage{revision: model.Revision{State: domain.ShortenState{
+		URL:   "https://example.com",
+		Rules: domain.Rules{{Name: "ios", URL: "https://evil.example/app"}},
+	}}}
+
+	s := service.NewShortenService(shortenStorage, revisions, nil, nil, authorizer{}, screening.NewBlocklist("evil.example"), nil, nil, domainURL)
+
+	_, err := s.Rollback(context.Background(), uuid.New(), 1, 1)
+	assert.ErrorIs(t, err, apperror.BadRequest)
+	assert.Empty(t, shortenStorage.UpdateCalls())
+}
+
+func TestShortenService_PermanentRedirect(t *testing.T) {
+	shortenStorage := &storage.ShortenStorageMock{
+		GetByIDFunc: func(ctx context.Context, id uint64) (model.Shorten, error) {
+			return model.Shorten{ID: id, URL: "https://example.com", Tags: []string{}, RedirectType: domain.RedirectPermanent}, nil
+		},
+	}
+
+	s := service.NewShortenService(shortenStorage, nil, nil, nil, authorizer{}, nil, nil, nil, domainURL)
+
+	_, err := s.SetRules(context.Background(), uuid.New(), 1, dto.SetRules{Rules: domain.Rules{{Name: "ios", URL: "https://example.com/ios", OS: []string{"iOS"}}}})
+	assert.ErrorIs(t, err, apperror.BadRequest)
+	assert.Empty(t, shortenStorage.SetRulesCalls())
+
+	_, err = s.SetVariants(context.Background(), uuid.New(), 1, dto.SetVariants{Variants: domain.Variants{{Name: "a", URL: "https://example.com/a", Weight: 1}}})
+	assert.ErrorIs(t, err, apperror.BadRequest)
+	assert.Empty(t, shortenStorage.SetVariantsCalls())
+
+	_, err = s.Update(context.Background(), uuid.New(), 1, dto.UpdateShorten{DeepLink: &domain.DeepLink{IOS: "shop://product/42"}})
+	assert.ErrorIs(t, err, apperror.BadRequest)
+	assert.Empty(t, shortenStorage.UpdateCalls())
+}
+
+func TestShortenService_CreateTrashedKeyOfAnotherUser(t *testing.T) {
+	owner := uuid.New()
+	deletedAt := time.Now()
+	trashed := map[uint64]model.Shorten{}
+
+	id, err := base62.Decode("promo")
+	assert.NoError(t, err)
+	trashed[id] = model.Shorten{ID: id, UserID: owner, DeletedAt: &deletedAt}
+
+	shortenStorage := &storage.ShortenStorageMock{
+		CreateFunc: func(ctx context.Context, shorten model.Shorten) error { return nil },
+		ExistsByIDFunc: func(ctx context.Context, id uint64) (bool, error) {
+			_, ok := trashed[id]
+			return ok, nil
+		},
+		ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) { return false, nil },
+	}
+
+	s := service.NewShortenService(shortenStorage, nil, nil, nil, authorizer{}, nil, nil, nil, domainURL)
+
+	_, err = s.Create(context.Background(), uuid.New(), dto.CreateShorten{Key: "promo", URL: "https://example.com"})
+	assert.ErrorIs(t, err, apperror.AlreadyExists)
+	assert.Empty(t, shortenStorage.CreateCalls())
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"cc/internal/config"
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/oidc"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v9"
+	"github.com/google/uuid"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// SSOStateExpiration is how long a single sign-on flow may take.
+const SSOStateExpiration = 10 * time.Minute
+
+type SSOService interface {
+	// AuthCodeURL also returns a binding, a secret to keep in the browser
+	// that starts the flow. The callback is only accepted with it, so a
+	// victim cannot be made to complete a flow started by someone else.
+	AuthCodeURL(ctx context.Context, linkUserID uuid.UUID) (url, binding string, err error)
+	SignIn(ctx context.Context, request dto.OIDCCallback) (domain.User, error)
+}
+
+type ssoService struct {
+	storage  storage.UserStorage
+	provider *oidc.Provider
+	cache    *redis.Client
+	config   config.OIDC
+}
+
+// pendingSignIn is kept in Redis between the redirect to the identity provider
+// and the callback. UserID is set when a signed-in user links an identity.
+// Only the hash of the binding is kept, so the Redis entry alone is not
+// enough to complete the flow.
+type pendingSignIn struct {
+	Nonce       string    `json:"nonce"`
+	Verifier    string    `json:"verifier"`
+	BindingHash string    `json:"binding_hash"`
+	UserID      uuid.UUID `json:"user_id"`
+}
+
+// NewSSOService returns a service that refuses every request when provider is
+// nil, i.e. single sign-on is not configured.
+func NewSSOService(storage storage.UserStorage, provider *oidc.Provider, cache *redis.Client, config config.OIDC) SSOService {
+	return &ssoService{storage: storage, provider: provider, cache: cache, config: config}
+}
+
+func (service *ssoService) AuthCodeURL(ctx context.Context, linkUserID uuid.UUID) (url, binding string, err error) {
+	if service.provider == nil {
+		return url, binding, apperror.NotFound.WithMessage("single sign-on is not configured")
+	}
+
+	var state, nonce, verifier string
+	for _, value := range []*string{&state, &nonce, &verifier, &binding} {
+		*value, err = oidc.RandomString()
+		if err != nil {
+			return url, binding, apperror.Internal.WithError(err).WithScope("sso auth code url")
+		}
+	}
+
+	var pending []byte
+	pending, err = json.Marshal(pendingSignIn{
+		Nonce:       nonce,
+		Verifier:    verifier,
+		BindingHash: hashBinding(binding),
+		UserID:      linkUserID,
+	})
+	if err != nil {
+		return url, binding, apperror.Internal.WithError(err).WithScope("sso auth code url")
+	}
+
+	err = service.cache.Set(ctx, "oidc:state:"+state, pending, SSOStateExpiration).Err()
+	if err != nil {
+		return url, binding, apperror.Internal.WithError(err).WithScope("sso auth code url")
+	}
+
+	url, err = service.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return url, binding, apperror.Internal.WithError(err).WithScope("sso auth code url")
+	}
+
+	return
+}
+
+func hashBinding(binding string) string {
+	sum := sha256.Sum256([]byte(binding))
+	return hex.EncodeToString(sum[:])
+}
+
+func (service *ssoService) SignIn(ctx context.Context, request dto.OIDCCallback) (user domain.User, err error) {
+	if service.provider == nil {
+		return user, apperror.NotFound.WithMessage("single sign-on is not configured")
+	}
+
+	var data []byte
+	data, err = service.cache.GetDel(ctx, "oidc:state:"+request.State).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return user, apperror.BadRequest.WithMessage("sign in request is invalid or has expired")
+		}
+
+		return user, apperror.Internal.WithError(err).WithScope("sso sign in")
+	}
+
+	var pending pendingSignIn
+	if err = json.Unmarshal(data, &pending); err != nil {
+		return user, apperror.Internal.WithError(err).WithScope("sso sign in")
+	}
+
+	// The state is spent either way, a mismatch means the callback was
+	// opened in another browser than the one that started the flow.
+	if subtle.ConstantTimeCompare([]byte(hashBinding(request.Binding)), []byte(pending.BindingHash)) != 1 {
+		return user, apperror.BadRequest.WithMessage("sign in request was started in another browser")
+	}
+
+	var claims oidc.Claims
+	claims, err = service.provider.Exchange(ctx, request.Code, pending.Verifier, pending.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrDiscovery) {
+			return user, apperror.Internal.WithError(err).WithScope("sso sign in")
+		}
+
+		return user, apperror.Unauthorized.WithError(err).WithMessage("identity provider rejected sign in")
+	}
+
+	var usr model.User
+	usr, err = service.storage.GetByIdentity(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		if pending.UserID != uuid.Nil && pending.UserID != usr.ID {
+			return user, apperror.AlreadyExists.WithMessage("identity is already linked to another account")
+		}
+
+		if usr.BannedAt != nil {
+			return user, apperror.Forbidden.WithMessage("account is banned")
+		}
+
+		return usr.Domain(), nil
+	}
+
+	if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+		return user, apperr.WithScope("sso sign in")
+	}
+
+	switch {
+	case pending.UserID != uuid.Nil:
+		usr, err = service.storage.GetByID(ctx, pending.UserID)
+	case service.config.AutoProvision:
+		usr, err = service.provision(ctx, claims)
+	default:
+		return user, apperror.Unauthorized.WithMessage("no account is linked to this identity")
+	}
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return user, apperr.WithScope("sso sign in")
+		}
+
+		return
+	}
+
+	err = service.storage.CreateIdentity(ctx, model.Identity{
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		UserID:    usr.ID,
+		Email:     claims.Email,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return user, apperr.WithScope("sso sign in")
+		}
+
+		return
+	}
+
+	return usr.Domain(), nil
+}
+
+// provision creates a password-less user named after the identity. Such
+// users can only sign in through the identity provider.
+func (service *ssoService) provision(ctx context.Context, claims oidc.Claims) (usr model.User, err error) {
+	base := usernameOf(claims)
+
+	name := base
+	for attempt := 0; ; attempt++ {
+		var exists bool
+		exists, err = service.storage.ExistsUserByName(ctx, name)
+		if err != nil {
+			return
+		}
+
+		if !exists {
+			break
+		}
+
+		if attempt == 5 {
+			return usr, apperror.AlreadyExists.WithMessage("could not pick a free user name")
+		}
+
+		name = fmt.Sprintf("%s-%04d", base, rand.Intn(10000))
+	}
+
+	usr = model.User{
+		ID:       uuid.New(),
+		Name:     name,
+		Password: []byte{},
+		Role:     string(domain.UserRoleUser),
+	}
+
+	err = service.storage.CreateUser(ctx, usr)
+	if err != nil {
+		return
+	}
+
+	return usr, nil
+}
+
+func usernameOf(claims oidc.Claims) string {
+	candidate := claims.PreferredUsername
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		default:
+			return -1
+		}
+	}, candidate)
+
+	// Leave room for the "-NNNN" suffix within the 20 character limit.
+	if len(name) > 15 {
+		name = name[:15]
+	}
+
+	if len(name) < 3 {
+		name = "user"
+	}
+
+	return name
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"cc/internal/config"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/oidc"
+	"cc/pkg/oidc/oidctest"
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// identityStorage knows a single linked identity. Other methods are not
+// expected to be called.
+type identityStorage struct {
+	storage.UserStorage
+	user model.User
+}
+
+func (storage identityStorage) GetByIdentity(context.Context, string, string) (model.User, error) {
+	return storage.user, nil
+}
+
+func TestSSOService_SignIn(t *testing.T) {
+	idp := oidctest.NewServer("cc", "secret")
+	defer idp.Close()
+
+	cache := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+
+	user := model.User{ID: uuid.New(), Name: "alice"}
+	sso := service.NewSSOService(identityStorage{user: user}, oidc.NewProvider(oidc.Config{
+		Issuer:       idp.Issuer(),
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://localhost/api/auth/oidc/callback",
+		Scopes:       []string{"openid"},
+	}, idp.Client()), cache, config.OIDC{})
+
+	// start begins a flow in one browser and returns the callback the
+	// identity provider sends that browser to.
+	start := func() (dto.OIDCCallback, string) {
+		url, binding, err := sso.AuthCodeURL(context.Background(), uuid.Nil)
+		require.NoError(t, err)
+
+		code, state, err := idp.Authorize(url)
+		require.NoError(t, err)
+
+		return dto.OIDCCallback{Code: code, State: state}, binding
+	}
+
+	t.Run("same browser", func(t *testing.T) {
+		callback, binding := start()
+		callback.Binding = binding
+
+		signedIn, err := sso.SignIn(context.Background(), callback)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, signedIn.ID)
+	})
+
+	t.Run("another browser", func(t *testing.T) {
+		callback, _ := start()
+		_, callback.Binding = start()
+
+		_, err := sso.SignIn(context.Background(), callback)
+		assert.ErrorIs(t, err, apperror.BadRequest)
+	})
+
+	t.Run("no binding", func(t *testing.T) {
+		callback, _ := start()
+
+		_, err := sso.SignIn(context.Background(), callback)
+		assert.ErrorIs(t, err, apperror.BadRequest)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"cc/pkg/base62"
 	"context"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/goware/urlx"
 	"github.com/mileusna/useragent"
 	"github.com/xuri/excelize/v2"
@@ -19,28 +20,39 @@ import (
 
 type StatsService interface {
 	CreateClick(ctx context.Context, request dto.CreateClick) error
-	CreateClickByUserAgent(ctx context.Context, timestamp time.Time, shortenID uint64, userAgent, referer, ip string) error
+	CreateClickByUserAgent(ctx context.Context, visit dto.Visit) error
 	GetClicksSummary(ctx context.Context, shortenID uint64, from, to string) (total int64, err error)
 	SelectClicks(ctx context.Context, shortenID uint64, from, to string) ([]domain.Click, error)
-	GetStats(ctx context.Context, shortenID uint64, request dto.GetShortenStats) (domain.Stats, error)
+	GetStats(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.GetShortenStats) (domain.Stats, error)
 	ExportStats(ctx context.Context, shorten domain.Shorten, request dto.ExportShortenStats) (string, error)
 }
 
 type statsService struct {
-	storage storage.StatsStorage
+	storage    storage.StatsStorage
+	revisions  storage.RevisionStorage
+	authorizer Authorizer
 }
 
-func NewStatsService(storage storage.StatsStorage) StatsService {
-	return &statsService{storage: storage}
+func NewStatsService(storage storage.StatsStorage, revisions storage.RevisionStorage, authorizer Authorizer) StatsService {
+	return &statsService{storage: storage, revisions: revisions, authorizer: authorizer}
 }
 
 func (service *statsService) CreateClick(ctx context.Context, request dto.CreateClick) (err error) {
+	if request.Source == "" {
+		request.Source = domain.SourceDirect
+	}
+
 	clck := model.Click{
 		ShortenID: request.ShortenID,
 		Platform:  request.Platform,
 		OS:        request.OS,
 		Referer:   request.Referer,
 		IP:        request.IP,
+		Source:    request.Source,
+		Rule:      request.Rule,
+		Variant:   request.Variant,
+		Open:      request.Open,
+		BioPageID: request.BioPageID,
 		Timestamp: request.Timestamp,
 	}
 	err = service.storage.CreateClick(ctx, clck)
@@ -55,49 +67,47 @@ func (service *statsService) CreateClick(ctx context.Context, request dto.Create
 	return
 }
 
-func (service *statsService) CreateClickByUserAgent(ctx context.Context, timestamp time.Time, shortenID uint64, ua, referer, ip string) (err error) {
+// NewVisitor classifies a user agent the same way clicks are, so redirect
+// rules and stats agree on platform and OS names.
+func NewVisitor(ua, country string, at time.Time) domain.Visitor {
 	userAgent := useragent.Parse(ua)
 
-	var platform, os string
-	switch {
-	case userAgent.Mobile:
-		platform = "Mobile"
-	case userAgent.Desktop:
-		platform = "Desktop"
-	case userAgent.Tablet:
-		platform = "Tablet"
-	default:
-		platform = "Other"
-	}
-
-	os = userAgent.OS
+	os := userAgent.OS
 	if os == "" {
 		os = "Other"
 	}
 
-	if referer == "" {
-		referer = "Other"
-	} else {
-		referer, _ = urlx.NormalizeString(referer)
-		referer = strings.Replace(referer, "www.", "", 1)
+	return domain.Visitor{
+		OS:       os,
+		Platform: platformOf(userAgent),
+		Country:  country,
+		Bot:      userAgent.Bot,
+		Time:     at,
+	}
+}
 
-		parse, _ := urlx.Parse(referer)
-		parse.RawQuery = ""
+// CreateClickByUserAgent records a click unless it comes from a bot.
+func (service *statsService) CreateClickByUserAgent(ctx context.Context, visit dto.Visit) (err error) {
+	visitor := NewVisitor(visit.UserAgent, "", visit.Timestamp)
 
-		referer = parse.String()
-	}
+	referer := normalizeReferer(visit.Referer)
 
-	if userAgent.Bot || (platform == "Other" && os == "Other") {
+	if visitor.Bot || (visitor.Platform == "Other" && visitor.OS == "Other") {
 		return nil
 	}
 
 	err = service.CreateClick(ctx, dto.CreateClick{
-		ShortenID: shortenID,
-		Platform:  platform,
-		OS:        os,
+		ShortenID: visit.ShortenID,
+		Platform:  visitor.Platform,
+		OS:        visitor.OS,
 		Referer:   referer,
-		IP:        ip,
-		Timestamp: timestamp,
+		IP:        visit.IP,
+		Source:    sourceOf(visit),
+		Rule:      visit.Rule,
+		Variant:   visit.Variant,
+		Open:      visit.Open,
+		BioPageID: visit.BioPageID,
+		Timestamp: visit.Timestamp,
 	})
 	if err != nil {
 		return err
@@ -124,7 +134,12 @@ func (service *statsService) SelectClicks(ctx context.Context, shortenID uint64,
 	return clcks.Domain(), nil
 }
 
-func (service *statsService) GetStats(ctx context.Context, shortenID uint64, request dto.GetShortenStats) (stats domain.Stats, err error) {
+func (service *statsService) GetStats(ctx context.Context, userID uuid.UUID, shortenID uint64, request dto.GetShortenStats) (stats domain.Stats, err error) {
+	err = service.authorizer.AuthorizeShorten(ctx, userID, shortenID, domain.RoleViewer)
+	if err != nil {
+		return
+	}
+
 	var clickMetric model.ClickMetric
 	clickMetric, err = service.storage.SelectClickMetric(ctx, shortenID, request.From, request.To, request.Unit, request.Units)
 	if err != nil {
@@ -169,6 +184,65 @@ func (service *statsService) GetStats(ctx context.Context, shortenID uint64, req
 	}
 	stats.Referer = refererMetrics.Domain()
 
+	var sourceMetrics model.Metrics
+	sourceMetrics, err = service.storage.SelectSourceMetrics(ctx, shortenID, request.From, request.To, request.Unit, request.Units)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return stats, apperr.WithScope("GetStats.SelectSourceMetrics")
+		}
+
+		return
+	}
+	stats.Source = sourceMetrics.Domain()
+
+	var ruleMetrics model.Metrics
+	ruleMetrics, err = service.storage.SelectRuleMetrics(ctx, shortenID, request.From, request.To, request.Unit, request.Units)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return stats, apperr.WithScope("GetStats.SelectRuleMetrics")
+		}
+
+		return
+	}
+	stats.Rule = ruleMetrics.Domain()
+
+	var variantMetrics model.Metrics
+	variantMetrics, err = service.storage.SelectVariantMetrics(ctx, shortenID, request.From, request.To, request.Unit, request.Units)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return stats, apperr.WithScope("GetStats.SelectVariantMetrics")
+		}
+
+		return
+	}
+	stats.Variant = variantMetrics.Domain()
+
+	var openMetrics model.Metrics
+	openMetrics, err = service.storage.SelectOpenMetrics(ctx, shortenID, request.From, request.To, request.Unit, request.Units)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return stats, apperr.WithScope("GetStats.SelectOpenMetrics")
+		}
+
+		return
+	}
+	stats.Open = openMetrics.Domain()
+
+	var changes []model.DestinationChange
+	changes, err = service.revisions.SelectDestinationChanges(ctx, shortenID, request.From, request.To)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return stats, apperr.WithScope("GetStats.SelectDestinationChanges")
+		}
+
+		return
+	}
+
+	stats.Annotations = make([]domain.Annotation, len(changes))
+	for i, change := range changes {
+		stats.Annotations[i] = change.Annotation()
+	}
+
 	return
 }
 
@@ -240,12 +314,13 @@ func (service *statsService) ExportStats(ctx context.Context, shorten domain.Sho
 
 	_, _ = f.NewSheet("Переходы")
 
-	_ = f.SetColWidth("Переходы", "A", "D", 32)
+	_ = f.SetColWidth("Переходы", "A", "E", 32)
 
 	_ = f.SetCellValue("Переходы", "A1", "Дата")
 	_ = f.SetCellValue("Переходы", "B1", "Платформа")
 	_ = f.SetCellValue("Переходы", "C1", "Операционная система")
 	_ = f.SetCellValue("Переходы", "D1", "Источник перехода")
+	_ = f.SetCellValue("Переходы", "E1", "Канал")
 
 	clicks, err := service.SelectClicks(ctx, shortenID, request.From, request.To)
 	if err != nil {
@@ -257,6 +332,7 @@ func (service *statsService) ExportStats(ctx context.Context, shorten domain.Sho
 		_ = f.SetCellValue("Переходы", fmt.Sprintf("B%d", i+2), click.Platform)
 		_ = f.SetCellValue("Переходы", fmt.Sprintf("C%d", i+2), click.OS)
 		_ = f.SetCellValue("Переходы", fmt.Sprintf("D%d", i+2), click.Referer)
+		_ = f.SetCellValue("Переходы", fmt.Sprintf("E%d", i+2), click.Source)
 	}
 
 	path := filepath.Join(
@@ -275,3 +351,45 @@ func (service *statsService) ExportStats(ctx context.Context, shorten domain.Sho
 
 	return path, nil
 }
+
+func sourceOf(visit dto.Visit) string {
+	if visit.BioPageID != nil {
+		return domain.SourceBio
+	}
+
+	if visit.Marker == domain.QRMarker {
+		return domain.SourceQR
+	}
+
+	return domain.SourceDirect
+}
+
+func platformOf(userAgent useragent.UserAgent) string {
+	switch {
+	case userAgent.Mobile:
+		return "Mobile"
+	case userAgent.Desktop:
+		return "Desktop"
+	case userAgent.Tablet:
+		return "Tablet"
+	default:
+		return "Other"
+	}
+}
+
+func normalizeReferer(referer string) string {
+	if referer == "" {
+		return "Other"
+	}
+
+	referer, _ = urlx.NormalizeString(referer)
+	referer = strings.Replace(referer, "www.", "", 1)
+
+	parse, err := urlx.Parse(referer)
+	if err != nil {
+		return "Other"
+	}
+	parse.RawQuery = ""
+
+	return parse.String()
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"cc/internal/config"
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/oidc"
+	"cc/pkg/totp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/go-redis/redis/v9"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	maxChallengeAttempts = 5
+	challengeKeyPrefix   = "2fa:challenge:"
+	challengeAttemptsKey = "2fa:attempts:"
+	codeAttemptsKey      = "2fa:code-attempts:"
+	codeAttemptsWindow   = 15 * time.Minute
+	totpSkew             = 1
+)
+
+type TwoFactorService interface {
+	Status(ctx context.Context, userID uuid.UUID) (domain.TwoFactorStatus, error)
+	Enroll(ctx context.Context, userID uuid.UUID) (domain.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userID uuid.UUID, request dto.TwoFactorCode) (domain.RecoveryCodes, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, request dto.TwoFactorCode) (domain.RecoveryCodes, error)
+	Disable(ctx context.Context, userID uuid.UUID, request dto.TwoFactorCode) error
+
+	Enabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	CreateChallenge(ctx context.Context, userID uuid.UUID) (domain.Challenge, error)
+	VerifyChallenge(ctx context.Context, request dto.TwoFactorSignIn) (uuid.UUID, error)
+}
+
+type twoFactorService struct {
+	storage     storage.TwoFactorStorage
+	userStorage storage.UserStorage
+	cache       *redis.Client
+	config      config.Auth
+}
+
+func NewTwoFactorService(storage storage.TwoFactorStorage, userStorage storage.UserStorage, cache *redis.Client, config config.Auth) TwoFactorService {
+	return &twoFactorService{storage: storage, userStorage: userStorage, cache: cache, config: config}
+}
+
+func (service *twoFactorService) Status(ctx context.Context, userID uuid.UUID) (status domain.TwoFactorStatus, err error) {
+	status.Enabled, err = service.Enabled(ctx, userID)
+	if err != nil || !status.Enabled {
+		return
+	}
+
+	status.RecoveryCodes, err = service.storage.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return status, apperr.WithScope("two-factor status")
+		}
+
+		return
+	}
+
+	return
+}
+
+func (service *twoFactorService) Enroll(ctx context.Context, userID uuid.UUID) (enrollment domain.TwoFactorEnrollment, err error) {
+	var enabled bool
+	enabled, err = service.Enabled(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	if enabled {
+		return enrollment, apperror.AlreadyExists.WithMessage("two-factor authentication is already enabled")
+	}
+
+	var usr model.User
+	usr, err = service.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return enrollment, apperr.WithScope("enroll two-factor")
+		}
+
+		return
+	}
+
+	var secret string
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		return enrollment, apperror.Internal.WithError(err).WithScope("enroll two-factor")
+	}
+
+	err = service.storage.SaveTOTP(ctx, model.TOTP{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return enrollment, apperr.WithScope("enroll two-factor")
+		}
+
+		return
+	}
+
+	return domain.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(service.config.TwoFactorIssuer, usr.Name, secret),
+	}, nil
+}
+
+// Confirm finishes enrollment once the user proves their authenticator
+// produces valid codes, and hands out the initial recovery codes.
+func (service *twoFactorService) Confirm(ctx context.Context, userID uuid.UUID, request dto.TwoFactorCode) (codes domain.RecoveryCodes, err error) {
+	var record model.TOTP
+	record, err = service.storage.GetTOTP(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return codes, apperr.WithScope("confirm two-factor")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return codes, apperror.BadRequest.WithMessage("two-factor enrollment has not been started")
+		}
+
+		return
+	}
+
+	if record.Confirmed() {
+		return codes, apperror.AlreadyExists.WithMessage("two-factor authentication is already enabled")
+	}
+
+	now := time.Now()
+
+	var counter int64
+	err = service.limit(ctx, userID, func() error {
+		var ok bool
+		if counter, ok = totp.Validate(record.Secret, request.Code, now, totpSkew); !ok {
+			return apperror.BadRequest.WithMessage("invalid two-factor code")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	err = service.storage.ConfirmTOTP(ctx, userID, counter, now)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return codes, apperr.WithScope("confirm two-factor")
+		}
+
+		return
+	}
+
+	return service.replaceRecoveryCodes(ctx, userID, now)
+}
+
+func (service *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, request dto.TwoFactorCode) (codes domain.RecoveryCodes, err error) {
+	err = service.limit(ctx, userID, func() error {
+		return service.verify(ctx, userID, request.Code)
+	})
+	if err != nil {
+		return
+	}
+
+	return service.replaceRecoveryCodes(ctx, userID, time.Now())
+}
+
+func (service *twoFactorService) Disable(ctx context.Context, userID uuid.UUID, request dto.TwoFactorCode) (err error) {
+	err = service.limit(ctx, userID, func() error {
+		return service.verify(ctx, userID, request.Code)
+	})
+	if err != nil {
+		return
+	}
+
+	err = service.storage.DeleteTOTP(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("disable two-factor")
+		}
+
+		return
+	}
+
+	return
+}
+
+func (service *twoFactorService) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	record, err := service.storage.GetTOTP(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return false, apperr.WithScope("two-factor enabled")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return record.Confirmed(), nil
+}
+
+func (service *twoFactorService) CreateChallenge(ctx context.Context, userID uuid.UUID) (challenge domain.Challenge, err error) {
+	var token string
+	token, err = oidc.RandomString()
+	if err != nil {
+		return challenge, apperror.Internal.WithError(err).WithScope("create challenge")
+	}
+
+	expiresAt := time.Now().Add(service.config.ChallengeExpirationAt)
+
+	err = service.cache.Set(ctx, challengeKeyPrefix+hashChallenge(token), userID.String(), service.config.ChallengeExpirationAt).Err()
+	if err != nil {
+		return challenge, apperror.Internal.WithError(err).WithScope("create challenge")
+	}
+
+	return domain.Challenge{
+		ChallengeToken: token,
+		ExpiresAt:      expiresAt.Unix(),
+	}, nil
+}
+
+// VerifyChallenge completes a sign in started with a password or single
+// sign-on. A challenge is dropped after too many wrong codes so the second
+// factor cannot be brute-forced within its lifetime.
+func (service *twoFactorService) VerifyChallenge(ctx context.Context, request dto.TwoFactorSignIn) (userID uuid.UUID, err error) {
+	hash := hashChallenge(request.ChallengeToken)
+
+	var value string
+	value, err = service.cache.Get(ctx, challengeKeyPrefix+hash).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return userID, apperror.Unauthorized.WithMessage("challenge is invalid or has expired")
+		}
+
+		return userID, apperror.Internal.WithError(err).WithScope("verify challenge")
+	}
+
+	userID, err = uuid.Parse(value)
+	if err != nil {
+		return userID, apperror.Internal.WithError(err).WithScope("verify challenge")
+	}
+
+	err = service.verify(ctx, userID, request.Code)
+	if err != nil {
+		if !errors.Is(err, apperror.BadRequest) {
+			return
+		}
+
+		var attempts int64
+		attempts, err = service.cache.Incr(ctx, challengeAttemptsKey+hash).Result()
+		if err != nil {
+			return userID, apperror.Internal.WithError(err).WithScope("verify challenge")
+		}
+
+		service.cache.Expire(ctx, challengeAttemptsKey+hash, service.config.ChallengeExpirationAt)
+
+		if attempts >= maxChallengeAttempts {
+			service.cache.Del(ctx, challengeKeyPrefix+hash, challengeAttemptsKey+hash)
+
+			return userID, apperror.Unauthorized.WithMessage("too many invalid codes, sign in again")
+		}
+
+		return userID, apperror.BadRequest.WithMessage("invalid two-factor code")
+	}
+
+	service.cache.Del(ctx, challengeKeyPrefix+hash, challengeAttemptsKey+hash)
+
+	return
+}
+
+// limit runs check, the verification of a code sent by a signed-in user,
+// unless the user already sent maxChallengeAttempts codes within
+// codeAttemptsWindow, so a stolen session cannot brute-force the second
+// factor. A successful check starts the count over.
+func (service *twoFactorService) limit(ctx context.Context, userID uuid.UUID, check func() error) error {
+	key := codeAttemptsKey + userID.String()
+
+	attempts, err := service.cache.Incr(ctx, key).Result()
+	if err != nil {
+		return apperror.Internal.WithError(err).WithScope("verify two-factor")
+	}
+
+	if attempts == 1 {
+		service.cache.Expire(ctx, key, codeAttemptsWindow)
+	}
+
+	if attempts > maxChallengeAttempts {
+		retryAfter, _ := service.cache.TTL(ctx, key).Result()
+
+		return apperror.TooManyRequests.
+			WithMessage("too many invalid codes, try again later").
+			WithRetryAfter(retryAfter)
+	}
+
+	err = check()
+	if err != nil {
+		return err
+	}
+
+	service.cache.Del(ctx, key)
+
+	return nil
+}
+
+// verify accepts either a current TOTP code or an unused recovery code.
+func (service *twoFactorService) verify(ctx context.Context, userID uuid.UUID, code string) error {
+	record, err := service.storage.GetTOTP(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("verify two-factor")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return apperror.BadRequest.WithMessage("two-factor authentication is not enabled")
+		}
+
+		return err
+	}
+
+	if !record.Confirmed() {
+		return apperror.BadRequest.WithMessage("two-factor authentication is not enabled")
+	}
+
+	now := time.Now()
+
+	if counter, ok := totp.Validate(record.Secret, code, now, totpSkew); ok {
+		var fresh bool
+		fresh, err = service.storage.UseCounter(ctx, userID, counter)
+		if err != nil {
+			if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+				return apperr.WithScope("verify two-factor")
+			}
+
+			return err
+		}
+
+		if !fresh {
+			return apperror.BadRequest.WithMessage("two-factor code has already been used")
+		}
+
+		return nil
+	}
+
+	used, err := service.storage.UseRecoveryCode(ctx, userID, hashRecoveryCode(code), now)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("verify two-factor")
+		}
+
+		return err
+	}
+
+	if !used {
+		return apperror.BadRequest.WithMessage("invalid two-factor code")
+	}
+
+	return nil
+}
+
+func (service *twoFactorService) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID, now time.Time) (codes domain.RecoveryCodes, err error) {
+	codes = make(domain.RecoveryCodes, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, apperror.Internal.WithError(err).WithScope("generate recovery codes")
+		}
+
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	err = service.storage.ReplaceRecoveryCodes(ctx, userID, hashes, now)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return nil, apperr.WithScope("generate recovery codes")
+		}
+
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	for i := range b {
+		b[i] = recoveryCodeAlphabet[int(b[i])%len(recoveryCodeAlphabet)]
+	}
+
+	return string(b[:5]) + "-" + string(b[5:]), nil
+}
+
+func hashRecoveryCode(code string) []byte {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+
+	return sum[:]
+}
+
+func hashChallenge(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,312 @@
+package service_test
+
+import (
+	"cc/internal/config"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	st "cc/internal/storage"
+	"cc/pkg/apperror"
+	"cc/pkg/totp"
+	"context"
+	"crypto/sha256"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// twoFactorStorage keeps the secret and recovery codes of a single user.
+type twoFactorStorage struct {
+	st.TwoFactorStorage
+	record   *model.TOTP
+	recovery map[[sha256.Size]byte]bool
+}
+
+func (storage *twoFactorStorage) GetTOTP(context.Context, uuid.UUID) (model.TOTP, error) {
+	if storage.record == nil {
+		return model.TOTP{}, apperror.NotFound
+	}
+
+	return *storage.record, nil
+}
+
+func (storage *twoFactorStorage) SaveTOTP(_ context.Context, record model.TOTP) error {
+	storage.record = &record
+	return nil
+}
+
+func (storage *twoFactorStorage) ConfirmTOTP(_ context.Context, _ uuid.UUID, counter int64, now time.Time) error {
+	storage.record.LastCounter = counter
+	storage.record.ConfirmedAt = &now
+	return nil
+}
+
+func (storage *twoFactorStorage) UseCounter(_ context.Context, _ uuid.UUID, counter int64) (bool, error) {
+	if counter <= storage.record.LastCounter {
+		return false, nil
+	}
+
+	storage.record.LastCounter = counter
+	return true, nil
+}
+
+func (storage *twoFactorStorage) DeleteTOTP(context.Context, uuid.UUID) error {
+	storage.record = nil
+	storage.recovery = nil
+	return nil
+}
+
+func (storage *twoFactorStorage) ReplaceRecoveryCodes(_ context.Context, _ uuid.UUID, hashes [][]byte, _ time.Time) error {
+	storage.recovery = make(map[[sha256.Size]byte]bool)
+	for _, hash := range hashes {
+		storage.recovery[[sha256.Size]byte(hash)] = false
+	}
+	return nil
+}
+
+func (storage *twoFactorStorage) UseRecoveryCode(_ context.Context, _ uuid.UUID, hash []byte, _ time.Time) (bool, error) {
+	used, ok := storage.recovery[[sha256.Size]byte(hash)]
+	if !ok || used {
+		return false, nil
+	}
+
+	storage.recovery[[sha256.Size]byte(hash)] = true
+	return true, nil
+}
+
+func newTwoFactorService(t *testing.T, user model.User) (service.TwoFactorService, *twoFactorStorage, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	storage := &twoFactorStorage{}
+	cfg := config.Auth{TwoFactorIssuer: "cc", ChallengeExpirationAt: 5 * time.Minute}
+
+	return service.NewTwoFactorService(storage, &userStorage{user: user}, client, cfg), storage, server
+}
+
+// totpCode returns the TOTP code of secret shifted by steps from the current one.
+func totpCode(t *testing.T, secret string, steps int64) string {
+	c, err := totp.Code(secret, totp.Counter(time.Now())+steps)
+	assert.NoError(t, err)
+
+	return c
+}
+
+// enableTwoFactor enrolls the user and confirms the enrollment.
+func enableTwoFactor(t *testing.T, twoFactorService service.TwoFactorService, userID uuid.UUID) (string, []string) {
+	ctx := context.Background()
+
+	enrollment, err := twoFactorService.Enroll(ctx, userID)
+	assert.NoError(t, err)
+
+	codes, err := twoFactorService.Confirm(ctx, userID, dto.TwoFactorCode{Code: totpCode(t, enrollment.Secret, 0)})
+	assert.NoError(t, err)
+
+	return enrollment.Secret, codes
+}
+
+func TestTwoFactorService_Enroll(t *testing.T) {
+	ctx := context.Background()
+	user := model.User{ID: uuid.New(), Name: "alice"}
+	twoFactorService, storage, _ := newTwoFactorService(t, user)
+
+	first, err := twoFactorService.Enroll(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.Secret)
+	assert.True(t, strings.HasPrefix(first.URI, "otpauth://totp/"))
+	assert.Contains(t, first.URI, "alice")
+
+	second, err := twoFactorService.Enroll(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Secret, second.Secret, "an unconfirmed enrollment starts over")
+	assert.Equal(t, second.Secret, storage.record.Secret)
+
+	_, err = twoFactorService.Confirm(ctx, user.ID, dto.TwoFactorCode{Code: totpCode(t, second.Secret, 0)})
+	assert.NoError(t, err)
+
+	_, err = twoFactorService.Enroll(ctx, user.ID)
+	_, ok := apperror.Is(err, apperror.AlreadyExists)
+	assert.True(t, ok)
+}
+
+func TestTwoFactorService_Confirm(t *testing.T) {
+	ctx := context.Background()
+	user := model.User{ID: uuid.New(), Name: "alice"}
+
+	t.Run("not enrolled", func(t *testing.T) {
+		twoFactorService, _, _ := newTwoFactorService(t, user)
+
+		_, err := twoFactorService.Confirm(ctx, user.ID, dto.TwoFactorCode{Code: "000000"})
+		_, ok := apperror.Is(err, apperror.BadRequest)
+		assert.True(t, ok)
+	})
+
+	t.Run("valid code", func(t *testing.T) {
+		twoFactorService, storage, _ := newTwoFactorService(t, user)
+
+		_, codes := enableTwoFactor(t, twoFactorService, user.ID)
+		assert.Len(t, codes, 10)
+		assert.Len(t, storage.recovery, 10)
+		assert.True(t, storage.record.Confirmed())
+
+		enabled, err := twoFactorService.Enabled(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.True(t, enabled)
+	})
+
+	t.Run("too many invalid codes", func(t *testing.T) {
+		twoFactorService, storage, server := newTwoFactorService(t, user)
+
+		enrollment, err := twoFactorService.Enroll(ctx, user.ID)
+		assert.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err = twoFactorService.Confirm(ctx, user.ID, dto.TwoFactorCode{Code: "000000"})
+			_, ok := apperror.Is(err, apperror.BadRequest)
+			assert.True(t, ok)
+		}
+
+		_, err = twoFactorService.Confirm(ctx, user.ID, dto.TwoFactorCode{Code: totpCode(t, enrollment.Secret, 0)})
+		apperr, ok := apperror.Is(err, apperror.TooManyRequests)
+		assert.True(t, ok, "a valid code is refused as well")
+		assert.Equal(t, 15*time.Minute, apperr.RetryAfter)
+		assert.False(t, storage.record.Confirmed())
+
+		server.FastForward(15 * time.Minute)
+
+		_, err = twoFactorService.Confirm(ctx, user.ID, dto.TwoFactorCode{Code: totpCode(t, enrollment.Secret, 0)})
+		assert.NoError(t, err)
+	})
+}
+
+func TestTwoFactorService_VerifyChallenge(t *testing.T) {
+	ctx := context.Background()
+	user := model.User{ID: uuid.New(), Name: "alice"}
+
+	t.Run("totp and recovery codes", func(t *testing.T) {
+		twoFactorService, _, _ := newTwoFactorService(t, user)
+		secret, codes := enableTwoFactor(t, twoFactorService, user.ID)
+
+		challenge, err := twoFactorService.CreateChallenge(ctx, user.ID)
+		assert.NoError(t, err)
+
+		_, err = twoFactorService.VerifyChallenge(ctx, dto.TwoFactorSignIn{ChallengeToken: challenge.ChallengeToken, Code: totpCode(t, secret, -1)})
+		_, ok := apperror.Is(err, apperror.BadRequest)
+		assert.True(t, ok, "codes up to the one used to confirm cannot be used")
+
+		userID, err := twoFactorService.VerifyChallenge(ctx, dto.TwoFactorSignIn{ChallengeToken: challenge.ChallengeToken, Code: totpCode(t, secret, 1)})
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+
+		_, err = twoFactorService.VerifyChallenge(ctx, dto.TwoFactorSignIn{ChallengeToken: challenge.ChallengeToken, Code: codes[0]})
+		_, ok = apperror.Is(err, apperror.Unauthorized)
+		assert.True(t, ok, "a challenge is completed once")
+
+		challenge, err = twoFactorService.CreateChallenge(ctx, user.ID)
+		assert.NoError(t, err)
+
+		userID, err = twoFactorService.VerifyChallenge(ctx, dto.TwoFactorSignIn{ChallengeToken: challenge.ChallengeToken, Code: codes[0]})
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+	})
+
+	t.Run("too many invalid codes", func(t *testing.T) {
+		twoFactorService, _, _ := newTwoFactorService(t, user)
+		secret, _ := enableTwoFactor(t, twoFactorService, user.ID)
+
+		challenge, err := twoFactorService.CreateChallenge(ctx, user.ID)
+		assert.NoError(t, err)
+
+		for i := 0; i < 4; i++ {
+			_, err = twoFactorService.VerifyChallenge(ctx, dto.TwoFactorSignIn{ChallengeToken: challenge.ChallengeToken, Code: "000000"})
+			_, ok := apperror.Is(err, apperror.BadRequest)
+			assert.True(t, ok)
+		}
+
+		_, err = twoFactorService.VerifyChallenge(ctx, dto.TwoFactorSignIn{ChallengeToken: challenge.ChallengeToken, Code: "000000"})
+		_, ok := apperror.Is(err, apperror.Unauthorized)
+		assert.True(t, ok)
+
+		_, err = twoFactorService.VerifyChallenge(ctx, dto.TwoFactorSignIn{ChallengeToken: challenge.ChallengeToken, Code: totpCode(t, secret, 1)})
+		_, ok = apperror.Is(err, apperror.Unauthorized)
+		assert.True(t, ok, "the challenge is dropped")
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		twoFactorService, _, server := newTwoFactorService(t, user)
+		secret, _ := enableTwoFactor(t, twoFactorService, user.ID)
+
+		challenge, err := twoFactorService.CreateChallenge(ctx, user.ID)
+		assert.NoError(t, err)
+
+		server.FastForward(5 * time.Minute)
+
+		_, err = twoFactorService.VerifyChallenge(ctx, dto.TwoFactorSignIn{ChallengeToken: challenge.ChallengeToken, Code: totpCode(t, secret, 1)})
+		_, ok := apperror.Is(err, apperror.Unauthorized)
+		assert.True(t, ok)
+	})
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	ctx := context.Background()
+	user := model.User{ID: uuid.New(), Name: "alice"}
+
+	t.Run("recovery code", func(t *testing.T) {
+		twoFactorService, storage, _ := newTwoFactorService(t, user)
+		_, codes := enableTwoFactor(t, twoFactorService, user.ID)
+
+		assert.NoError(t, twoFactorService.Disable(ctx, user.ID, dto.TwoFactorCode{Code: codes[0]}))
+		assert.Nil(t, storage.record)
+
+		err := twoFactorService.Disable(ctx, user.ID, dto.TwoFactorCode{Code: codes[1]})
+		_, ok := apperror.Is(err, apperror.BadRequest)
+		assert.True(t, ok, "not enabled anymore")
+	})
+
+	t.Run("too many invalid codes", func(t *testing.T) {
+		twoFactorService, storage, _ := newTwoFactorService(t, user)
+		secret, codes := enableTwoFactor(t, twoFactorService, user.ID)
+
+		for i := 0; i < 5; i++ {
+			err := twoFactorService.Disable(ctx, user.ID, dto.TwoFactorCode{Code: "000000"})
+			_, ok := apperror.Is(err, apperror.BadRequest)
+			assert.True(t, ok)
+		}
+
+		err := twoFactorService.Disable(ctx, user.ID, dto.TwoFactorCode{Code: totpCode(t, secret, 1)})
+		_, ok := apperror.Is(err, apperror.TooManyRequests)
+		assert.True(t, ok)
+
+		_, err = twoFactorService.RegenerateRecoveryCodes(ctx, user.ID, dto.TwoFactorCode{Code: codes[0]})
+		_, ok = apperror.Is(err, apperror.TooManyRequests)
+		assert.True(t, ok, "the limit is shared with recovery codes")
+		assert.NotNil(t, storage.record)
+	})
+
+	t.Run("valid code starts the count over", func(t *testing.T) {
+		twoFactorService, _, _ := newTwoFactorService(t, user)
+		secret, _ := enableTwoFactor(t, twoFactorService, user.ID)
+
+		for i := 0; i < 4; i++ {
+			err := twoFactorService.Disable(ctx, user.ID, dto.TwoFactorCode{Code: "000000"})
+			_, ok := apperror.Is(err, apperror.BadRequest)
+			assert.True(t, ok)
+		}
+
+		codes, err := twoFactorService.RegenerateRecoveryCodes(ctx, user.ID, dto.TwoFactorCode{Code: totpCode(t, secret, 1)})
+		assert.NoError(t, err)
+
+		for i := 0; i < 4; i++ {
+			err = twoFactorService.Disable(ctx, user.ID, dto.TwoFactorCode{Code: "000000"})
+			_, ok := apperror.Is(err, apperror.BadRequest)
+			assert.True(t, ok)
+		}
+
+		assert.NoError(t, twoFactorService.Disable(ctx, user.ID, dto.TwoFactorCode{Code: codes[0]}))
+	})
+}
@@ -10,21 +10,38 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"time"
 )
 
+// reauthWindow is how long after signing in a user without a password may
+// still delete their account; anyone else has to confirm with the password.
+const reauthWindow = 5 * time.Minute
+
 type UserService interface {
 	SignIn(ctx context.Context, request dto.Credentials) (domain.User, error)
 	SignUp(ctx context.Context, request dto.Credentials) (domain.User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (domain.User, error)
 	GetUserByName(ctx context.Context, name string) (domain.User, error)
+	ChangeName(ctx context.Context, id uuid.UUID, request dto.ChangeName) (domain.User, error)
+	// ChangePassword sets a new password and revokes every session of the
+	// user except sessionID.
+	ChangePassword(ctx context.Context, id, sessionID uuid.UUID, request dto.ChangePassword) error
+	// Delete removes the user. Users signed in with single sign-on, who have
+	// no password, must have started sessionID within the last few minutes.
+	// Delete removes the user and returns the ids of the shortens moved to the
+	// trash with the workspaces left without members.
+	Delete(ctx context.Context, id, sessionID uuid.UUID, request dto.DeleteUser) ([]uint64, error)
 }
 
 type userService struct {
-	storage storage.UserStorage
+	storage  storage.UserStorage
+	sessions storage.AuthStorage
+	tx       storage.Transactor
+	audit    AuditService
 }
 
-func NewUserService(storage storage.UserStorage) UserService {
-	return &userService{storage: storage}
+func NewUserService(storage storage.UserStorage, sessions storage.AuthStorage, tx storage.Transactor, audit AuditService) UserService {
+	return &userService{storage: storage, sessions: sessions, tx: tx, audit: audit}
 }
 
 func (service *userService) SignIn(ctx context.Context, request dto.Credentials) (user domain.User, err error) {
@@ -47,6 +64,10 @@ func (service *userService) SignIn(ctx context.Context, request dto.Credentials)
 		return user, apperror.BadRequest.WithMessage("invalid name or password")
 	}
 
+	if usr.BannedAt != nil {
+		return user, apperror.Forbidden.WithMessage("account is banned")
+	}
+
 	return usr.Domain(), nil
 }
 
@@ -73,6 +94,7 @@ func (service *userService) SignUp(ctx context.Context, request dto.Credentials)
 		ID:       uuid.New(),
 		Name:     request.Name,
 		Password: password,
+		Role:     string(domain.UserRoleUser),
 	}
 	err = service.storage.CreateUser(ctx, usr)
 	if err != nil {
@@ -83,6 +105,8 @@ func (service *userService) SignUp(ctx context.Context, request dto.Credentials)
 		return
 	}
 
+	service.auditUser(ctx, domain.AuditSignUp, usr.ID, nil, map[string]string{"name": usr.Name, "role": usr.Role})
+
 	return usr.Domain(), nil
 }
 
@@ -113,3 +137,171 @@ func (service *userService) GetUserByName(ctx context.Context, name string) (use
 
 	return usr.Domain(), nil
 }
+
+func (service *userService) ChangeName(ctx context.Context, id uuid.UUID, request dto.ChangeName) (user domain.User, err error) {
+	var usr model.User
+	usr, err = service.storage.GetByID(ctx, id)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return user, apperr.WithScope("change name")
+		}
+
+		return
+	}
+
+	if usr.Name == request.Name {
+		return usr.Domain(), nil
+	}
+
+	var exists bool
+	exists, err = service.storage.ExistsUserByName(ctx, request.Name)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return user, apperr.WithScope("change name")
+		}
+
+		return
+	} else if exists {
+		return user, apperror.AlreadyExists.WithMessage("name is already exists")
+	}
+
+	err = service.storage.UpdateName(ctx, id, request.Name)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return user, apperr.WithScope("change name")
+		}
+
+		return
+	}
+
+	service.auditUser(ctx, domain.AuditUserRename, id, map[string]string{"name": usr.Name}, map[string]string{"name": request.Name})
+
+	usr.Name = request.Name
+
+	return usr.Domain(), nil
+}
+
+func (service *userService) ChangePassword(ctx context.Context, id, sessionID uuid.UUID, request dto.ChangePassword) (err error) {
+	var usr model.User
+	usr, err = service.storage.GetByID(ctx, id)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("change password")
+		}
+
+		return
+	}
+
+	err = bcrypt.CompareHashAndPassword(usr.Password, []byte(request.OldPassword))
+	if err != nil {
+		return apperror.BadRequest.WithMessage("invalid password")
+	}
+
+	var password []byte
+	password, err = bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return
+	}
+
+	err = inTx(ctx, service.tx, func(ctx context.Context) error {
+		if err := service.storage.UpdatePassword(ctx, id, password); err != nil {
+			return err
+		}
+
+		_, err := service.sessions.RevokeSessions(ctx, id, sessionID, time.Now())
+		return err
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("change password")
+		}
+
+		return
+	}
+
+	// The password is left out, the record only tells that it was changed.
+	service.auditUser(ctx, domain.AuditUserPassword, id, nil, nil)
+
+	return
+}
+
+func (service *userService) Delete(ctx context.Context, id, sessionID uuid.UUID, request dto.DeleteUser) (shortenIDs []uint64, err error) {
+	var usr model.User
+	usr, err = service.storage.GetByID(ctx, id)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return nil, apperr.WithScope("delete user")
+		}
+
+		return
+	}
+
+	err = service.reauthenticate(ctx, usr, sessionID, request.Password)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return nil, apperr.WithScope("delete user")
+		}
+
+		return
+	}
+
+	shortenIDs, err = service.storage.Delete(ctx, id, time.Now())
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return nil, apperr.WithScope("delete user")
+		}
+
+		return
+	}
+
+	service.auditUser(ctx, domain.AuditUserDelete, id, map[string]string{"name": usr.Name, "role": usr.Role}, nil)
+
+	return
+}
+
+// reauthenticate checks that the request comes from the owner of usr: by the
+// password, or for users without one by a session that was just started.
+func (service *userService) reauthenticate(ctx context.Context, usr model.User, sessionID uuid.UUID, password string) error {
+	if len(usr.Password) > 0 {
+		if password == "" {
+			return apperror.BadRequest.WithMessage("password is required")
+		}
+
+		if err := bcrypt.CompareHashAndPassword(usr.Password, []byte(password)); err != nil {
+			return apperror.BadRequest.WithMessage("invalid password")
+		}
+
+		return nil
+	}
+
+	session, err := service.sessions.GetSession(ctx, usr.ID, sessionID)
+	if err != nil {
+		if _, ok := apperror.Is(err, apperror.NotFound); ok {
+			return apperror.Unauthorized.WithMessage("sign in again to confirm")
+		}
+
+		return err
+	}
+
+	if session.RevokedAt != nil || time.Since(session.CreatedAt) > reauthWindow {
+		return apperror.Unauthorized.WithMessage("sign in again to confirm")
+	}
+
+	return nil
+}
+
+// auditUser records a change the user made to their own account.
+func (service *userService) auditUser(ctx context.Context, action string, id uuid.UUID, before, after any) {
+	if service.audit == nil {
+		return
+	}
+
+	service.audit.Record(ctx, dto.AuditEntry{
+		ActorID:    &id,
+		Action:     action,
+		TargetType: domain.TargetUser,
+		TargetID:   id.String(),
+		Before:     before,
+		After:      after,
+	})
+}
@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	st "cc/internal/storage"
+	"cc/pkg/apperror"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+	"time"
+)
+
+type userStorage struct {
+	st.UserStorage
+	user     model.User
+	password []byte
+	deleted  bool
+	trashed  []uint64
+}
+
+func (storage *userStorage) GetByID(context.Context, uuid.UUID) (model.User, error) {
+	return storage.user, nil
+}
+
+func (storage *userStorage) UpdatePassword(_ context.Context, _ uuid.UUID, password []byte) error {
+	storage.password = password
+	return nil
+}
+
+func (storage *userStorage) Delete(context.Context, uuid.UUID, time.Time) ([]uint64, error) {
+	storage.deleted = true
+	return storage.trashed, nil
+}
+
+type sessionStorage struct {
+	st.AuthStorage
+	session model.Session
+	except  uuid.UUID
+	fail    bool
+}
+
+func (storage *sessionStorage) GetSession(_ context.Context, _ uuid.UUID, sessionID uuid.UUID) (model.Session, error) {
+	if storage.session.ID != sessionID {
+		return model.Session{}, apperror.NotFound
+	}
+
+	return storage.session, nil
+}
+
+func (storage *sessionStorage) RevokeSessions(_ context.Context, _ uuid.UUID, except uuid.UUID, _ time.Time) ([]uuid.UUID, error) {
+	if storage.fail {
+		return nil, apperror.Internal
+	}
+
+	storage.except = except
+	return nil, nil
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	password, err := bcrypt.GenerateFromPassword([]byte("old password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	user := model.User{ID: uuid.New(), Password: password}
+	sessionID := uuid.New()
+	request := dto.ChangePassword{OldPassword: "old password", NewPassword: "new password"}
+
+	t.Run("revokes other sessions", func(t *testing.T) {
+		users := &userStorage{user: user}
+		sessions := &sessionStorage{}
+		audit := &auditStorage{}
+		committed := false
+
+		userService := service.NewUserService(users, sessions, transactor{committed: &committed}, service.NewAuditService(audit))
+
+		err := userService.ChangePassword(context.Background(), user.ID, sessionID, request)
+		assert.NoError(t, err)
+		assert.True(t, committed)
+		assert.Equal(t, sessionID, sessions.except)
+		assert.NoError(t, bcrypt.CompareHashAndPassword(users.password, []byte("new password")))
+
+		if assert.Len(t, audit.records, 1) {
+			assert.Equal(t, domain.AuditUserPassword, audit.records[0].Action)
+			assert.Equal(t, &user.ID, audit.records[0].ActorID)
+			assert.Nil(t, audit.records[0].After)
+		}
+	})
+
+	t.Run("failed revocation", func(t *testing.T) {
+		committed := false
+
+		userService := service.NewUserService(&userStorage{user: user}, &sessionStorage{fail: true}, transactor{committed: &committed}, nil)
+
+		err := userService.ChangePassword(context.Background(), user.ID, sessionID, request)
+		_, ok := apperror.Is(err, apperror.Internal)
+		assert.True(t, ok)
+		assert.False(t, committed)
+	})
+}
+
+func TestUserService_Delete(t *testing.T) {
+	password, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	sessionID := uuid.New()
+
+	tests := []struct {
+		name     string
+		user     model.User
+		session  model.Session
+		password string
+		err      apperror.Error
+	}{
+		{
+			name:     "password",
+			user:     model.User{ID: uuid.New(), Password: password},
+			password: "password",
+		},
+		{
+			name:     "wrong password",
+			user:     model.User{ID: uuid.New(), Password: password},
+			password: "wrong",
+			err:      apperror.BadRequest,
+		},
+		{
+			name: "no password",
+			user: model.User{ID: uuid.New(), Password: password},
+			err:  apperror.BadRequest,
+		},
+		{
+			name:    "single sign-on with fresh session",
+			user:    model.User{ID: uuid.New()},
+			session: model.Session{ID: sessionID, CreatedAt: time.Now().Add(-time.Minute)},
+		},
+		{
+			name:    "single sign-on with old session",
+			user:    model.User{ID: uuid.New()},
+			session: model.Session{ID: sessionID, CreatedAt: time.Now().Add(-time.Hour)},
+			err:     apperror.Unauthorized,
+		},
+		{
+			name:    "single sign-on with unknown session",
+			user:    model.User{ID: uuid.New()},
+			session: model.Session{ID: uuid.New(), CreatedAt: time.Now()},
+			err:     apperror.Unauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &userStorage{user: tt.user, trashed: []uint64{1, 2}}
+
+			userService := service.NewUserService(users, &sessionStorage{session: tt.session}, nil, nil)
+
+			shortenIDs, err := userService.Delete(context.Background(), tt.user.ID, sessionID, dto.DeleteUser{Password: tt.password})
+			if tt.err.Code == 0 {
+				assert.NoError(t, err)
+				assert.True(t, users.deleted)
+				assert.Equal(t, []uint64{1, 2}, shortenIDs)
+				return
+			}
+
+			_, ok := apperror.Is(err, tt.err)
+			assert.True(t, ok, err)
+			assert.False(t, users.deleted)
+			assert.Empty(t, shortenIDs)
+		})
+	}
+}
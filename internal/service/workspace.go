@@ -0,0 +1,627 @@
+package service
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/storage"
+	"cc/pkg/apperror"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	defaultInvitationExpiration = 7 * 24 * time.Hour
+	maxInvitationExpiration     = 30 * 24 * time.Hour
+)
+
+// Authorizer checks workspace roles. It is the only part of WorkspaceService
+// that ShortenService and StatsService depend on.
+type Authorizer interface {
+	Authorize(ctx context.Context, userID, workspaceID uuid.UUID, role domain.Role) error
+	AuthorizeShorten(ctx context.Context, userID uuid.UUID, shortenID uint64, role domain.Role) error
+}
+
+type WorkspaceService interface {
+	Authorizer
+
+	Create(ctx context.Context, userID uuid.UUID, request dto.CreateWorkspace) (domain.Workspace, error)
+	Update(ctx context.Context, userID, workspaceID uuid.UUID, request dto.UpdateWorkspace) (domain.Workspace, error)
+	// Delete removes the workspace and returns the ids of the shortens it
+	// moved to the trash, so they can be evicted from the redirect cache.
+	Delete(ctx context.Context, userID, workspaceID uuid.UUID) ([]uint64, error)
+	GetByID(ctx context.Context, userID, workspaceID uuid.UUID) (domain.Workspace, error)
+	SelectByUser(ctx context.Context, userID uuid.UUID) (domain.Workspaces, error)
+
+	SelectMembers(ctx context.Context, userID, workspaceID uuid.UUID) (domain.Members, error)
+	UpdateMember(ctx context.Context, userID, workspaceID, memberID uuid.UUID, request dto.UpdateMember) error
+	RemoveMember(ctx context.Context, userID, workspaceID, memberID uuid.UUID) error
+
+	CreateInvitation(ctx context.Context, userID, workspaceID uuid.UUID, request dto.CreateInvitation) (domain.Invitation, error)
+	SelectInvitations(ctx context.Context, userID, workspaceID uuid.UUID) (domain.Invitations, error)
+	DeleteInvitation(ctx context.Context, userID, workspaceID, invitationID uuid.UUID) error
+	AcceptInvitation(ctx context.Context, userID uuid.UUID, request dto.AcceptInvitation) (domain.Workspace, error)
+
+	EnsureCanLeave(ctx context.Context, userID uuid.UUID) error
+}
+
+type workspaceService struct {
+	storage          storage.WorkspaceStorage
+	audit            AuditService
+	requireTwoFactor bool
+}
+
+// NewWorkspaceService returns a service that, when requireTwoFactor is set,
+// denies access to every workspace until the user enables two-factor
+// authentication.
+func NewWorkspaceService(storage storage.WorkspaceStorage, audit AuditService, requireTwoFactor bool) WorkspaceService {
+	return &workspaceService{storage: storage, audit: audit, requireTwoFactor: requireTwoFactor}
+}
+
+func (service *workspaceService) Authorize(ctx context.Context, userID, workspaceID uuid.UUID, role domain.Role) (err error) {
+	_, err = service.authorize(ctx, userID, workspaceID, role)
+
+	return
+}
+
+func (service *workspaceService) authorize(ctx context.Context, userID, workspaceID uuid.UUID, required domain.Role) (role domain.Role, err error) {
+	var access model.Access
+	access, err = service.storage.GetAccess(ctx, workspaceID, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return role, apperr.WithScope("authorize")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return role, apperror.NotFound.WithMessage("workspace with this id does not exist")
+		}
+
+		return
+	}
+
+	role = domain.Role(access.Role)
+	if !role.Allows(required) {
+		return role, apperror.Forbidden.WithMessage("you don't have access to this workspace")
+	}
+
+	return role, service.checkTwoFactor(access)
+}
+
+func (service *workspaceService) AuthorizeShorten(ctx context.Context, userID uuid.UUID, shortenID uint64, required domain.Role) (err error) {
+	var access model.Access
+	access, err = service.storage.GetAccessByShorten(ctx, shortenID, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("authorize shorten")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return apperror.NotFound.WithMessage("shorten with this id does not exist")
+		}
+
+		return
+	}
+
+	// Shortens of workspaces the user does not belong to are reported as
+	// missing so their keys are not disclosed.
+	if access.Role == "" {
+		return apperror.NotFound.WithMessage("shorten with this id does not exist")
+	}
+
+	if !domain.Role(access.Role).Allows(required) {
+		return apperror.Forbidden.WithMessage("you don't have access to this shorten")
+	}
+
+	return service.checkTwoFactor(access)
+}
+
+func (service *workspaceService) checkTwoFactor(access model.Access) error {
+	if (service.requireTwoFactor || access.RequireTwoFactor) && !access.TwoFactor {
+		return apperror.Forbidden.WithMessage("two-factor authentication is required")
+	}
+
+	return nil
+}
+
+func (service *workspaceService) Create(ctx context.Context, userID uuid.UUID, request dto.CreateWorkspace) (workspace domain.Workspace, err error) {
+	now := time.Now()
+
+	wrkspc := model.Workspace{
+		ID:        uuid.New(),
+		Name:      request.Name,
+		Role:      string(domain.RoleOwner),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = service.storage.Create(ctx, wrkspc, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return workspace, apperr.WithScope("create workspace")
+		}
+
+		return
+	}
+
+	service.auditWorkspace(ctx, domain.AuditWorkspaceCreate, wrkspc.ID, nil, workspaceState(wrkspc))
+
+	return wrkspc.Domain(), nil
+}
+
+func (service *workspaceService) Update(ctx context.Context, userID, workspaceID uuid.UUID, request dto.UpdateWorkspace) (workspace domain.Workspace, err error) {
+	var role domain.Role
+	role, err = service.authorize(ctx, userID, workspaceID, domain.RoleOwner)
+	if err != nil {
+		return
+	}
+
+	var wrkspc model.Workspace
+	wrkspc, err = service.storage.GetByID(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return workspace, apperr.WithScope("update workspace")
+		}
+
+		return
+	}
+
+	before := workspaceState(wrkspc)
+
+	if request.Name != "" {
+		wrkspc.Name = request.Name
+	}
+
+	if request.RequireTwoFactor != nil {
+		wrkspc.RequireTwoFactor = *request.RequireTwoFactor
+	}
+
+	if request.Branding != nil {
+		wrkspc.Branding = *request.Branding
+	}
+
+	wrkspc.Role = string(role)
+	wrkspc.UpdatedAt = time.Now()
+
+	err = service.storage.Update(ctx, wrkspc)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return workspace, apperr.WithScope("update workspace")
+		}
+
+		return
+	}
+
+	service.auditWorkspace(ctx, domain.AuditWorkspaceUpdate, workspaceID, before, workspaceState(wrkspc))
+
+	return wrkspc.Domain(), nil
+}
+
+func (service *workspaceService) Delete(ctx context.Context, userID, workspaceID uuid.UUID) (shortenIDs []uint64, err error) {
+	err = service.Authorize(ctx, userID, workspaceID, domain.RoleOwner)
+	if err != nil {
+		return
+	}
+
+	var wrkspc model.Workspace
+	wrkspc, err = service.storage.GetByID(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return nil, apperr.WithScope("delete workspace")
+		}
+
+		return
+	}
+
+	if wrkspc.Personal {
+		return nil, apperror.BadRequest.WithMessage("personal workspace cannot be deleted")
+	}
+
+	shortenIDs, err = service.storage.Delete(ctx, workspaceID, time.Now())
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return nil, apperr.WithScope("delete workspace")
+		}
+
+		return
+	}
+
+	service.auditWorkspace(ctx, domain.AuditWorkspaceDelete, workspaceID, workspaceState(wrkspc), nil)
+
+	return
+}
+
+func (service *workspaceService) GetByID(ctx context.Context, userID, workspaceID uuid.UUID) (workspace domain.Workspace, err error) {
+	var role domain.Role
+	role, err = service.authorize(ctx, userID, workspaceID, domain.RoleViewer)
+	if err != nil {
+		return
+	}
+
+	var wrkspc model.Workspace
+	wrkspc, err = service.storage.GetByID(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return workspace, apperr.WithScope("get workspace")
+		}
+
+		return
+	}
+
+	wrkspc.Role = string(role)
+
+	return wrkspc.Domain(), nil
+}
+
+func (service *workspaceService) SelectByUser(ctx context.Context, userID uuid.UUID) (workspaces domain.Workspaces, err error) {
+	var wrkspcs model.Workspaces
+	wrkspcs, err = service.storage.SelectByUser(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return workspaces, apperr.WithScope("select workspaces")
+		}
+
+		return
+	}
+
+	return wrkspcs.Domain(), nil
+}
+
+func (service *workspaceService) SelectMembers(ctx context.Context, userID, workspaceID uuid.UUID) (members domain.Members, err error) {
+	err = service.Authorize(ctx, userID, workspaceID, domain.RoleViewer)
+	if err != nil {
+		return
+	}
+
+	var mmbrs model.Members
+	mmbrs, err = service.storage.SelectMembers(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return members, apperr.WithScope("select members")
+		}
+
+		return
+	}
+
+	return mmbrs.Domain(), nil
+}
+
+func (service *workspaceService) UpdateMember(ctx context.Context, userID, workspaceID, memberID uuid.UUID, request dto.UpdateMember) (err error) {
+	err = service.Authorize(ctx, userID, workspaceID, domain.RoleOwner)
+	if err != nil {
+		return
+	}
+
+	var current string
+	current, err = service.storage.GetRole(ctx, workspaceID, memberID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("update member")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return apperror.NotFound.WithMessage("member with this id does not exist")
+		}
+
+		return
+	}
+
+	if domain.Role(current) == domain.RoleOwner && request.Role != domain.RoleOwner {
+		err = service.ensureAnotherOwner(ctx, workspaceID)
+		if err != nil {
+			return
+		}
+	}
+
+	err = service.storage.UpdateMember(ctx, model.Member{
+		WorkspaceID: workspaceID,
+		UserID:      memberID,
+		Role:        string(request.Role),
+	})
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("update member")
+		}
+
+		return
+	}
+
+	service.auditWorkspace(ctx, domain.AuditMemberUpdate, workspaceID,
+		map[string]string{"user_id": memberID.String(), "role": current},
+		map[string]string{"user_id": memberID.String(), "role": string(request.Role)},
+	)
+
+	return
+}
+
+func (service *workspaceService) RemoveMember(ctx context.Context, userID, workspaceID, memberID uuid.UUID) (err error) {
+	// Any member may leave, only owners may remove someone else.
+	required := domain.RoleOwner
+	if userID == memberID {
+		required = domain.RoleViewer
+	}
+
+	err = service.Authorize(ctx, userID, workspaceID, required)
+	if err != nil {
+		return
+	}
+
+	var wrkspc model.Workspace
+	wrkspc, err = service.storage.GetByID(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("remove member")
+		}
+
+		return
+	}
+
+	if wrkspc.Personal {
+		return apperror.BadRequest.WithMessage("members cannot be removed from a personal workspace")
+	}
+
+	var current string
+	current, err = service.storage.GetRole(ctx, workspaceID, memberID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("remove member")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return apperror.NotFound.WithMessage("member with this id does not exist")
+		}
+
+		return
+	}
+
+	if domain.Role(current) == domain.RoleOwner {
+		err = service.ensureAnotherOwner(ctx, workspaceID)
+		if err != nil {
+			return
+		}
+	}
+
+	err = service.storage.DeleteMember(ctx, workspaceID, memberID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("remove member")
+		}
+
+		return
+	}
+
+	service.auditWorkspace(ctx, domain.AuditMemberRemove, workspaceID, map[string]string{"user_id": memberID.String(), "role": current}, nil)
+
+	return
+}
+
+func (service *workspaceService) ensureAnotherOwner(ctx context.Context, workspaceID uuid.UUID) error {
+	owners, err := service.storage.CountOwners(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("count owners")
+		}
+
+		return err
+	}
+
+	if owners < 2 {
+		return apperror.BadRequest.WithMessage("workspace must have at least one owner")
+	}
+
+	return nil
+}
+
+func (service *workspaceService) CreateInvitation(ctx context.Context, userID, workspaceID uuid.UUID, request dto.CreateInvitation) (invitation domain.Invitation, err error) {
+	err = service.Authorize(ctx, userID, workspaceID, domain.RoleOwner)
+	if err != nil {
+		return
+	}
+
+	var wrkspc model.Workspace
+	wrkspc, err = service.storage.GetByID(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return invitation, apperr.WithScope("create invitation")
+		}
+
+		return
+	}
+
+	if wrkspc.Personal {
+		return invitation, apperror.BadRequest.WithMessage("personal workspace cannot be shared")
+	}
+
+	expiration := defaultInvitationExpiration
+	if request.ExpiresIn > 0 {
+		expiration = time.Duration(request.ExpiresIn) * time.Second
+	}
+
+	if expiration > maxInvitationExpiration {
+		expiration = maxInvitationExpiration
+	}
+
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return invitation, apperror.Internal.WithError(err).WithScope("create invitation")
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	now := time.Now()
+
+	invtn := model.Invitation{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		TokenHash:   hashInvitationToken(token),
+		Role:        string(request.Role),
+		CreatedBy:   &userID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(expiration),
+	}
+
+	err = service.storage.CreateInvitation(ctx, invtn)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return invitation, apperr.WithScope("create invitation")
+		}
+
+		return
+	}
+
+	service.auditWorkspace(ctx, domain.AuditInvitationCreate, workspaceID, nil, map[string]any{
+		"invitation_id": invtn.ID,
+		"role":          invtn.Role,
+		"expires_at":    invtn.ExpiresAt,
+	})
+
+	// The token is only ever returned here, the database keeps its hash.
+	invitation = invtn.Domain()
+	invitation.Token = token
+
+	return
+}
+
+func (service *workspaceService) SelectInvitations(ctx context.Context, userID, workspaceID uuid.UUID) (invitations domain.Invitations, err error) {
+	err = service.Authorize(ctx, userID, workspaceID, domain.RoleOwner)
+	if err != nil {
+		return
+	}
+
+	var invtns model.Invitations
+	invtns, err = service.storage.SelectInvitations(ctx, workspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return invitations, apperr.WithScope("select invitations")
+		}
+
+		return
+	}
+
+	return invtns.Domain(), nil
+}
+
+func (service *workspaceService) DeleteInvitation(ctx context.Context, userID, workspaceID, invitationID uuid.UUID) (err error) {
+	err = service.Authorize(ctx, userID, workspaceID, domain.RoleOwner)
+	if err != nil {
+		return
+	}
+
+	var deleted bool
+	deleted, err = service.storage.DeleteInvitation(ctx, workspaceID, invitationID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("delete invitation")
+		}
+
+		return
+	}
+
+	if !deleted {
+		return apperror.NotFound.WithMessage("invitation with this id does not exist")
+	}
+
+	service.auditWorkspace(ctx, domain.AuditInvitationDelete, workspaceID, map[string]string{"invitation_id": invitationID.String()}, nil)
+
+	return
+}
+
+func (service *workspaceService) AcceptInvitation(ctx context.Context, userID uuid.UUID, request dto.AcceptInvitation) (workspace domain.Workspace, err error) {
+	var invtn model.Invitation
+	invtn, err = service.storage.AcceptInvitation(ctx, hashInvitationToken(request.Token), userID, time.Now())
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return workspace, apperr.WithScope("accept invitation")
+		}
+
+		if errors.Is(err, apperror.NotFound) {
+			return workspace, apperror.BadRequest.WithMessage("invitation is invalid or has expired")
+		}
+
+		return
+	}
+
+	// The workspace may require two-factor authentication the user has not
+	// enabled yet, so it is loaded without going through authorize.
+	var wrkspc model.Workspace
+	wrkspc, err = service.storage.GetByID(ctx, invtn.WorkspaceID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return workspace, apperr.WithScope("accept invitation")
+		}
+
+		return
+	}
+
+	var role string
+	role, err = service.storage.GetRole(ctx, invtn.WorkspaceID, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return workspace, apperr.WithScope("accept invitation")
+		}
+
+		return
+	}
+
+	wrkspc.Role = role
+
+	service.auditWorkspace(ctx, domain.AuditInvitationAccept, wrkspc.ID, nil, map[string]string{
+		"invitation_id": invtn.ID.String(),
+		"user_id":       userID.String(),
+		"role":          role,
+	})
+
+	return wrkspc.Domain(), nil
+}
+
+// EnsureCanLeave refuses to let a user go while they are the only owner of a
+// shared workspace, which would otherwise be left without anyone to manage it.
+func (service *workspaceService) EnsureCanLeave(ctx context.Context, userID uuid.UUID) (err error) {
+	var wrkspcs model.Workspaces
+	wrkspcs, err = service.storage.SelectSoleOwned(ctx, userID)
+	if err != nil {
+		if apperr, ok := apperror.Is(err, apperror.Internal); ok {
+			return apperr.WithScope("ensure can leave")
+		}
+
+		return
+	}
+
+	if len(wrkspcs) > 0 {
+		return apperror.BadRequest.WithMessage("transfer ownership of workspace " + wrkspcs[0].Name + " first")
+	}
+
+	return
+}
+
+// auditWorkspace records a change to a workspace or its members.
+func (service *workspaceService) auditWorkspace(ctx context.Context, action string, workspaceID uuid.UUID, before, after any) {
+	if service.audit == nil {
+		return
+	}
+
+	service.audit.Record(ctx, dto.AuditEntry{
+		Action:     action,
+		TargetType: domain.TargetWorkspace,
+		TargetID:   workspaceID.String(),
+		Before:     before,
+		After:      after,
+	})
+}
+
+func workspaceState(wrkspc model.Workspace) map[string]any {
+	return map[string]any{
+		"name":               wrkspc.Name,
+		"require_two_factor": wrkspc.RequireTwoFactor,
+		"branding":           wrkspc.Branding,
+	}
+}
+
+func hashInvitationToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+
+	return sum[:]
+}
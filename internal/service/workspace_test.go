@@ -0,0 +1,460 @@
+package service_test
+
+import (
+	"bytes"
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/model"
+	"cc/internal/service"
+	"cc/mock/storage"
+	"cc/pkg/apperror"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWorkspaceService_Authorize(t *testing.T) {
+	tests := []struct {
+		name             string
+		access           model.Access
+		accessErr        error
+		required         domain.Role
+		requireTwoFactor bool
+		err              apperror.Error
+	}{
+		{
+			name:     "owner manages",
+			access:   model.Access{Role: string(domain.RoleOwner)},
+			required: domain.RoleOwner,
+		},
+		{
+			name:     "editor edits",
+			access:   model.Access{Role: string(domain.RoleEditor)},
+			required: domain.RoleEditor,
+		},
+		{
+			name:     "editor manages",
+			access:   model.Access{Role: string(domain.RoleEditor)},
+			required: domain.RoleOwner,
+			err:      apperror.Forbidden,
+		},
+		{
+			name:     "viewer views",
+			access:   model.Access{Role: string(domain.RoleViewer)},
+			required: domain.RoleViewer,
+		},
+		{
+			name:     "viewer edits",
+			access:   model.Access{Role: string(domain.RoleViewer)},
+			required: domain.RoleEditor,
+			err:      apperror.Forbidden,
+		},
+		{
+			name:      "not a member",
+			accessErr: apperror.NotFound,
+			required:  domain.RoleViewer,
+			err:       apperror.NotFound,
+		},
+		{
+			name:     "workspace requires two-factor",
+			access:   model.Access{Role: string(domain.RoleOwner), RequireTwoFactor: true},
+			required: domain.RoleViewer,
+			err:      apperror.Forbidden,
+		},
+		{
+			name:     "workspace requires two-factor and it is enabled",
+			access:   model.Access{Role: string(domain.RoleOwner), RequireTwoFactor: true, TwoFactor: true},
+			required: domain.RoleViewer,
+		},
+		{
+			name:             "two-factor required everywhere",
+			access:           model.Access{Role: string(domain.RoleOwner)},
+			required:         domain.RoleViewer,
+			requireTwoFactor: true,
+			err:              apperror.Forbidden,
+		},
+		{
+			name:             "two-factor required everywhere and it is enabled",
+			access:           model.Access{Role: string(domain.RoleOwner), TwoFactor: true},
+			required:         domain.RoleViewer,
+			requireTwoFactor: true,
+		},
+		{
+			name:      "storage failure",
+			accessErr: apperror.Internal,
+			required:  domain.RoleViewer,
+			err:       apperror.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaces := &storage.WorkspaceStorageMock{
+				GetAccessFunc: func(context.Context, uuid.UUID, uuid.UUID) (model.Access, error) {
+					return tt.access, tt.accessErr
+				},
+			}
+
+			workspaceService := service.NewWorkspaceService(workspaces, nil, tt.requireTwoFactor)
+
+			err := workspaceService.Authorize(context.Background(), uuid.New(), uuid.New(), tt.required)
+			if tt.err.Code == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			_, ok := apperror.Is(err, tt.err)
+			assert.True(t, ok, err)
+		})
+	}
+}
+
+func TestWorkspaceService_AuthorizeShorten(t *testing.T) {
+	tests := []struct {
+		name     string
+		access   model.Access
+		required domain.Role
+		err      apperror.Error
+	}{
+		{
+			name:     "editor edits",
+			access:   model.Access{Role: string(domain.RoleEditor)},
+			required: domain.RoleEditor,
+		},
+		{
+			name:     "viewer edits",
+			access:   model.Access{Role: string(domain.RoleViewer)},
+			required: domain.RoleEditor,
+			err:      apperror.Forbidden,
+		},
+		{
+			name:     "shorten of another workspace",
+			access:   model.Access{},
+			required: domain.RoleViewer,
+			err:      apperror.NotFound,
+		},
+		{
+			name:     "workspace requires two-factor",
+			access:   model.Access{Role: string(domain.RoleEditor), RequireTwoFactor: true},
+			required: domain.RoleViewer,
+			err:      apperror.Forbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaces := &storage.WorkspaceStorageMock{
+				GetAccessByShortenFunc: func(context.Context, uint64, uuid.UUID) (model.Access, error) {
+					return tt.access, nil
+				},
+			}
+
+			workspaceService := service.NewWorkspaceService(workspaces, nil, false)
+
+			err := workspaceService.AuthorizeShorten(context.Background(), uuid.New(), 1, tt.required)
+			if tt.err.Code == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			_, ok := apperror.Is(err, tt.err)
+			assert.True(t, ok, err)
+		})
+	}
+}
+
+func TestWorkspaceService_AcceptInvitation(t *testing.T) {
+	ctx := context.Background()
+	ownerID, userID := uuid.New(), uuid.New()
+	wrkspc := model.Workspace{ID: uuid.New(), Name: "team"}
+
+	tests := []struct {
+		name     string
+		request  dto.CreateInvitation
+		acceptAt time.Duration
+		accepted bool
+		err      apperror.Error
+	}{
+		{
+			name:    "accepted",
+			request: dto.CreateInvitation{Role: domain.RoleEditor},
+		},
+		{
+			name:     "already accepted",
+			request:  dto.CreateInvitation{Role: domain.RoleEditor},
+			accepted: true,
+			err:      apperror.BadRequest,
+		},
+		{
+			name:     "expired",
+			request:  dto.CreateInvitation{Role: domain.RoleEditor, ExpiresIn: 60},
+			acceptAt: 2 * time.Minute,
+			err:      apperror.BadRequest,
+		},
+		{
+			name:     "expiration is capped",
+			request:  dto.CreateInvitation{Role: domain.RoleViewer, ExpiresIn: int64(365 * 24 * time.Hour / time.Second)},
+			acceptAt: 31 * 24 * time.Hour,
+			err:      apperror.BadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var invitation model.Invitation
+			roles := map[uuid.UUID]string{ownerID: string(domain.RoleOwner)}
+
+			workspaces := &storage.WorkspaceStorageMock{
+				GetAccessFunc: func(_ context.Context, _ uuid.UUID, userID uuid.UUID) (model.Access, error) {
+					return model.Access{Role: roles[userID]}, nil
+				},
+				GetByIDFunc: func(context.Context, uuid.UUID) (model.Workspace, error) {
+					return wrkspc, nil
+				},
+				GetRoleFunc: func(_ context.Context, _ uuid.UUID, userID uuid.UUID) (string, error) {
+					return roles[userID], nil
+				},
+				CreateInvitationFunc: func(_ context.Context, invtn model.Invitation) error {
+					invitation = invtn
+					return nil
+				},
+				AcceptInvitationFunc: func(_ context.Context, tokenHash []byte, userID uuid.UUID, now time.Time) (model.Invitation, error) {
+					now = now.Add(tt.acceptAt)
+
+					if !bytes.Equal(tokenHash, invitation.TokenHash) || invitation.AcceptedAt != nil || !now.Before(invitation.ExpiresAt) {
+						return model.Invitation{}, apperror.NotFound
+					}
+
+					invitation.AcceptedBy, invitation.AcceptedAt = &userID, &now
+					roles[userID] = invitation.Role
+
+					return invitation, nil
+				},
+			}
+
+			workspaceService := service.NewWorkspaceService(workspaces, nil, false)
+
+			created, err := workspaceService.CreateInvitation(ctx, ownerID, wrkspc.ID, tt.request)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, created.Token)
+			assert.NotEqual(t, []byte(created.Token), invitation.TokenHash, "only the hash is stored")
+
+			if tt.accepted {
+				_, err = workspaceService.AcceptInvitation(ctx, uuid.New(), dto.AcceptInvitation{Token: created.Token})
+				assert.NoError(t, err)
+			}
+
+			workspace, err := workspaceService.AcceptInvitation(ctx, userID, dto.AcceptInvitation{Token: created.Token})
+			if tt.err.Code == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, wrkspc.ID, workspace.ID)
+				assert.Equal(t, tt.request.Role, workspace.Role)
+				return
+			}
+
+			_, ok := apperror.Is(err, tt.err)
+			assert.True(t, ok, err)
+			assert.Empty(t, roles[userID])
+		})
+	}
+
+	t.Run("personal workspace", func(t *testing.T) {
+		workspaces := &storage.WorkspaceStorageMock{
+			GetAccessFunc: func(context.Context, uuid.UUID, uuid.UUID) (model.Access, error) {
+				return model.Access{Role: string(domain.RoleOwner)}, nil
+			},
+			GetByIDFunc: func(context.Context, uuid.UUID) (model.Workspace, error) {
+				return model.Workspace{ID: ownerID, Personal: true}, nil
+			},
+		}
+
+		workspaceService := service.NewWorkspaceService(workspaces, nil, false)
+
+		_, err := workspaceService.CreateInvitation(ctx, ownerID, ownerID, dto.CreateInvitation{Role: domain.RoleEditor})
+		_, ok := apperror.Is(err, apperror.BadRequest)
+		assert.True(t, ok)
+		assert.Empty(t, workspaces.CreateInvitationCalls())
+	})
+}
+
+func TestWorkspaceService_LastOwner(t *testing.T) {
+	ctx := context.Background()
+	ownerID, memberID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name   string
+		roles  map[uuid.UUID]domain.Role
+		action func(service.WorkspaceService, uuid.UUID) error
+		err    apperror.Error
+	}{
+		{
+			name:  "sole owner steps down",
+			roles: map[uuid.UUID]domain.Role{ownerID: domain.RoleOwner, memberID: domain.RoleEditor},
+			action: func(workspaceService service.WorkspaceService, workspaceID uuid.UUID) error {
+				return workspaceService.UpdateMember(ctx, ownerID, workspaceID, ownerID, dto.UpdateMember{Role: domain.RoleEditor})
+			},
+			err: apperror.BadRequest,
+		},
+		{
+			name:  "one of two owners steps down",
+			roles: map[uuid.UUID]domain.Role{ownerID: domain.RoleOwner, memberID: domain.RoleOwner},
+			action: func(workspaceService service.WorkspaceService, workspaceID uuid.UUID) error {
+				return workspaceService.UpdateMember(ctx, ownerID, workspaceID, ownerID, dto.UpdateMember{Role: domain.RoleEditor})
+			},
+		},
+		{
+			name:  "sole owner leaves",
+			roles: map[uuid.UUID]domain.Role{ownerID: domain.RoleOwner, memberID: domain.RoleEditor},
+			action: func(workspaceService service.WorkspaceService, workspaceID uuid.UUID) error {
+				return workspaceService.RemoveMember(ctx, ownerID, workspaceID, ownerID)
+			},
+			err: apperror.BadRequest,
+		},
+		{
+			name:  "one of two owners leaves",
+			roles: map[uuid.UUID]domain.Role{ownerID: domain.RoleOwner, memberID: domain.RoleOwner},
+			action: func(workspaceService service.WorkspaceService, workspaceID uuid.UUID) error {
+				return workspaceService.RemoveMember(ctx, ownerID, workspaceID, ownerID)
+			},
+		},
+		{
+			name:  "editor leaves",
+			roles: map[uuid.UUID]domain.Role{ownerID: domain.RoleOwner, memberID: domain.RoleEditor},
+			action: func(workspaceService service.WorkspaceService, workspaceID uuid.UUID) error {
+				return workspaceService.RemoveMember(ctx, memberID, workspaceID, memberID)
+			},
+		},
+		{
+			name:  "editor removes the owner",
+			roles: map[uuid.UUID]domain.Role{ownerID: domain.RoleOwner, memberID: domain.RoleEditor},
+			action: func(workspaceService service.WorkspaceService, workspaceID uuid.UUID) error {
+				return workspaceService.RemoveMember(ctx, memberID, workspaceID, ownerID)
+			},
+			err: apperror.Forbidden,
+		},
+		{
+			name:  "sole owner deletes the account",
+			roles: map[uuid.UUID]domain.Role{ownerID: domain.RoleOwner, memberID: domain.RoleEditor},
+			action: func(workspaceService service.WorkspaceService, _ uuid.UUID) error {
+				return workspaceService.EnsureCanLeave(ctx, ownerID)
+			},
+			err: apperror.BadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrkspc := model.Workspace{ID: uuid.New(), Name: "team"}
+
+			owners := func() (count int) {
+				for _, role := range tt.roles {
+					if role == domain.RoleOwner {
+						count++
+					}
+				}
+				return
+			}
+
+			workspaces := &storage.WorkspaceStorageMock{
+				GetAccessFunc: func(_ context.Context, _ uuid.UUID, userID uuid.UUID) (model.Access, error) {
+					return model.Access{Role: string(tt.roles[userID])}, nil
+				},
+				GetByIDFunc: func(context.Context, uuid.UUID) (model.Workspace, error) {
+					return wrkspc, nil
+				},
+				GetRoleFunc: func(_ context.Context, _ uuid.UUID, userID uuid.UUID) (string, error) {
+					return string(tt.roles[userID]), nil
+				},
+				CountOwnersFunc: func(context.Context, uuid.UUID) (int, error) {
+					return owners(), nil
+				},
+				UpdateMemberFunc: func(context.Context, model.Member) error {
+					return nil
+				},
+				DeleteMemberFunc: func(context.Context, uuid.UUID, uuid.UUID) error {
+					return nil
+				},
+				SelectSoleOwnedFunc: func(_ context.Context, userID uuid.UUID) (model.Workspaces, error) {
+					if tt.roles[userID] == domain.RoleOwner && owners() == 1 {
+						return model.Workspaces{wrkspc}, nil
+					}
+					return nil, nil
+				},
+			}
+
+			workspaceService := service.NewWorkspaceService(workspaces, nil, false)
+
+			err := tt.action(workspaceService, wrkspc.ID)
+			if tt.err.Code == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, len(workspaces.UpdateMemberCalls())+len(workspaces.DeleteMemberCalls()))
+				return
+			}
+
+			_, ok := apperror.Is(err, tt.err)
+			assert.True(t, ok, err)
+			assert.Empty(t, workspaces.UpdateMemberCalls())
+			assert.Empty(t, workspaces.DeleteMemberCalls())
+		})
+	}
+}
+
+func TestWorkspaceService_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		role      domain.Role
+		workspace model.Workspace
+		err       apperror.Error
+	}{
+		{
+			name:      "owner",
+			role:      domain.RoleOwner,
+			workspace: model.Workspace{ID: uuid.New(), Name: "team"},
+		},
+		{
+			name:      "editor",
+			role:      domain.RoleEditor,
+			workspace: model.Workspace{ID: uuid.New(), Name: "team"},
+			err:       apperror.Forbidden,
+		},
+		{
+			name:      "personal workspace",
+			role:      domain.RoleOwner,
+			workspace: model.Workspace{ID: uuid.New(), Name: "alice", Personal: true},
+			err:       apperror.BadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaces := &storage.WorkspaceStorageMock{
+				GetAccessFunc: func(context.Context, uuid.UUID, uuid.UUID) (model.Access, error) {
+					return model.Access{Role: string(tt.role)}, nil
+				},
+				GetByIDFunc: func(context.Context, uuid.UUID) (model.Workspace, error) {
+					return tt.workspace, nil
+				},
+				DeleteFunc: func(context.Context, uuid.UUID, time.Time) ([]uint64, error) {
+					return []uint64{1, 2}, nil
+				},
+			}
+
+			workspaceService := service.NewWorkspaceService(workspaces, nil, false)
+
+			shortenIDs, err := workspaceService.Delete(ctx, uuid.New(), tt.workspace.ID)
+			if tt.err.Code == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, []uint64{1, 2}, shortenIDs, "trashed shortens are returned for eviction")
+				return
+			}
+
+			_, ok := apperror.Is(err, tt.err)
+			assert.True(t, ok, err)
+			assert.Empty(t, workspaces.DeleteCalls())
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"cc/internal/model"
+	"cc/pkg/apperror"
+	"cc/pkg/postgres"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// AuditStorage only ever appends records.
+type AuditStorage interface {
+	Create(ctx context.Context, record model.AuditRecord) error
+	Select(ctx context.Context, filter model.AuditFilter) (model.AuditRecords, error)
+}
+
+type auditStorage struct {
+	client postgres.Client
+}
+
+func NewAuditStorage(client postgres.Client) AuditStorage {
+	return &auditStorage{client: client}
+}
+
+func (storage *auditStorage) Create(ctx context.Context, record model.AuditRecord) error {
+	q := `
+INSERT INTO
+    audit_log (actor_id, ip, user_agent, action, target_type, target_id, before, after, created_at)
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		record.ActorID,
+		record.IP,
+		record.UserAgent,
+		record.Action,
+		record.TargetType,
+		record.TargetID,
+		record.Before,
+		record.After,
+		record.CreatedAt,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *auditStorage) Select(ctx context.Context, filter model.AuditFilter) (model.AuditRecords, error) {
+	q := `
+SELECT id,
+       actor_id,
+       ip,
+       user_agent,
+       action,
+       target_type,
+       target_id,
+       before,
+       after,
+       created_at
+FROM audit_log
+WHERE ($1::UUID IS NULL OR actor_id = $1)
+  AND ($2 = '' OR action = $2)
+  AND ($3 = '' OR target_type = $3)
+  AND ($4 = '' OR target_id = $4)
+  AND ($5::TIMESTAMPTZ IS NULL OR created_at >= $5)
+  AND ($6::TIMESTAMPTZ IS NULL OR created_at < $6)
+  AND ($7 = 0 OR id < $7)
+ORDER BY id DESC
+LIMIT $8 OFFSET $9
+`
+
+	var records model.AuditRecords
+	err := storage.client.Select(ctx, &records, q,
+		filter.ActorID,
+		filter.Action,
+		filter.TargetType,
+		filter.TargetID,
+		filter.From,
+		filter.To,
+		filter.BeforeID,
+		filter.Limit,
+		filter.Offset,
+	)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return records, apperror.Internal.WithError(err)
+	}
+
+	return records, nil
+}
@@ -8,12 +8,25 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"time"
 )
 
 type AuthStorage interface {
 	CreateSession(ctx context.Context, session model.Session) error
 	UpdateSession(ctx context.Context, session model.Session) error
+	RotateSession(ctx context.Context, session model.Session, previousRefreshToken uuid.UUID) error
+	GetSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (model.Session, error)
+	// IsSessionActive reports whether the session is neither revoked nor
+	// expired and its user is not banned.
+	IsSessionActive(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, now time.Time) (bool, error)
 	GetSessionByRefreshToken(ctx context.Context, refreshToken uuid.UUID) (model.Session, error)
+	GetSessionByUsedRefreshToken(ctx context.Context, refreshToken uuid.UUID) (model.Session, error)
+	SelectActiveSessions(ctx context.Context, userID uuid.UUID, now time.Time) (model.Sessions, error)
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, now time.Time) (bool, error)
+	// RevokeSessions revokes every session of the user but except and
+	// returns the ids of the revoked ones.
+	RevokeSessions(ctx context.Context, userID uuid.UUID, except uuid.UUID, now time.Time) ([]uuid.UUID, error)
+	DeleteExpiredSessions(ctx context.Context, userID uuid.UUID, now time.Time) error
 }
 
 type authStorage struct {
@@ -26,10 +39,10 @@ func NewAuthStorage(client postgres.Client) AuthStorage {
 
 func (storage *authStorage) CreateSession(ctx context.Context, session model.Session) error {
 	q := `
-INSERT INTO 
-    sessions (id, user_id, refresh_token, ip, created_at, updated_at) 
-VALUES 
-    ($1, $2, $3, $4, $5, $6)
+INSERT INTO
+    sessions (id, user_id, refresh_token, ip, user_agent, created_at, updated_at, expires_at)
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8)
 `
 
 	_, err := storage.client.Exec(ctx, q,
@@ -37,8 +50,10 @@ VALUES
 		session.UserID,
 		session.RefreshToken,
 		session.IP,
+		session.UserAgent,
 		session.CreatedAt,
 		session.UpdatedAt,
+		session.ExpiresAt,
 	)
 	if err != nil {
 		return apperror.Internal.WithError(err)
@@ -49,15 +64,18 @@ VALUES
 
 func (storage *authStorage) UpdateSession(ctx context.Context, session model.Session) error {
 	q := `
-UPDATE 
-    sessions 
-SET 
+UPDATE
+    sessions
+SET
     user_id = $2,
 	refresh_token = $3,
 	ip = $4,
-	created_at = $5,
-	updated_at = $6
-WHERE 
+	user_agent = $5,
+	created_at = $6,
+	updated_at = $7,
+	expires_at = $8,
+	revoked_at = $9
+WHERE
     id = $1
 `
 
@@ -66,21 +84,119 @@ WHERE
 		session.UserID,
 		session.RefreshToken,
 		session.IP,
+		session.UserAgent,
 		session.CreatedAt,
 		session.UpdatedAt,
+		session.ExpiresAt,
+		session.RevokedAt,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *authStorage) RotateSession(ctx context.Context, session model.Session, previousRefreshToken uuid.UUID) error {
+	q := `
+WITH used AS (
+    INSERT INTO
+        session_refresh_tokens (refresh_token, session_id, used_at)
+    VALUES
+        ($7, $1, $5)
+    ON CONFLICT DO NOTHING
+)
+UPDATE
+    sessions
+SET
+	refresh_token = $2,
+	ip = $3,
+	user_agent = $4,
+	updated_at = $5,
+	expires_at = $6
+WHERE
+    id = $1 AND
+    refresh_token = $7 AND
+    revoked_at IS NULL AND
+    expires_at > $5
+`
+
+	tag, err := storage.client.Exec(ctx, q,
+		session.ID,
+		session.RefreshToken,
+		session.IP,
+		session.UserAgent,
+		session.UpdatedAt,
+		session.ExpiresAt,
+		previousRefreshToken,
 	)
 	if err != nil {
 		return apperror.Internal.WithError(err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return apperror.NotFound
+	}
+
 	return nil
 }
 
+func (storage *authStorage) GetSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (model.Session, error) {
+	q := `
+SELECT
+    id, user_id, refresh_token, ip, user_agent, created_at, updated_at, expires_at, revoked_at
+FROM
+    sessions
+WHERE
+	user_id = $1 AND
+	id = $2
+`
+
+	var session model.Session
+	err := storage.client.Get(ctx, &session, q, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return session, apperror.NotFound.WithError(err)
+		}
+
+		return session, apperror.Internal.WithError(err)
+	}
+
+	return session, nil
+}
+
+func (storage *authStorage) IsSessionActive(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, now time.Time) (bool, error) {
+	q := `
+SELECT EXISTS (
+    SELECT
+        1
+    FROM
+        sessions
+    JOIN
+        users ON users.id = sessions.user_id
+    WHERE
+        sessions.user_id = $1 AND
+        sessions.id = $2 AND
+        sessions.revoked_at IS NULL AND
+        sessions.expires_at > $3 AND
+        users.banned_at IS NULL
+)
+`
+
+	var active bool
+	err := storage.client.Get(ctx, &active, q, userID, sessionID, now)
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	return active, nil
+}
+
 func (storage *authStorage) GetSessionByRefreshToken(ctx context.Context, refreshToken uuid.UUID) (model.Session, error) {
 	q := `
-SELECT 
-    id, user_id, refresh_token, ip, created_at, updated_at 
-FROM 
+SELECT
+    id, user_id, refresh_token, ip, user_agent, created_at, updated_at, expires_at, revoked_at
+FROM
     sessions
 WHERE
 	refresh_token = $1
@@ -98,3 +214,117 @@ WHERE
 
 	return session, nil
 }
+
+func (storage *authStorage) GetSessionByUsedRefreshToken(ctx context.Context, refreshToken uuid.UUID) (model.Session, error) {
+	q := `
+SELECT
+    sessions.id,
+    sessions.user_id,
+    sessions.refresh_token,
+    sessions.ip,
+    sessions.user_agent,
+    sessions.created_at,
+    sessions.updated_at,
+    sessions.expires_at,
+    sessions.revoked_at
+FROM
+    session_refresh_tokens
+	    JOIN sessions ON sessions.id = session_refresh_tokens.session_id
+WHERE
+	session_refresh_tokens.refresh_token = $1
+`
+
+	var session model.Session
+	err := storage.client.Get(ctx, &session, q, refreshToken)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return session, apperror.NotFound.WithError(err)
+		}
+
+		return session, apperror.Internal.WithError(err)
+	}
+
+	return session, nil
+}
+
+func (storage *authStorage) SelectActiveSessions(ctx context.Context, userID uuid.UUID, now time.Time) (model.Sessions, error) {
+	q := `
+SELECT
+    id, user_id, refresh_token, ip, user_agent, created_at, updated_at, expires_at, revoked_at
+FROM
+    sessions
+WHERE
+	user_id = $1 AND
+	revoked_at IS NULL AND
+	expires_at > $2
+ORDER BY
+    updated_at DESC
+`
+
+	var sessions model.Sessions
+	err := storage.client.Select(ctx, &sessions, q, userID, now)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return sessions, apperror.Internal.WithError(err)
+	}
+
+	return sessions, nil
+}
+
+func (storage *authStorage) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, now time.Time) (bool, error) {
+	q := `
+UPDATE
+    sessions
+SET
+    revoked_at = $3
+WHERE
+    user_id = $1 AND
+    id = $2 AND
+    revoked_at IS NULL
+`
+
+	tag, err := storage.client.Exec(ctx, q, userID, sessionID, now)
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (storage *authStorage) RevokeSessions(ctx context.Context, userID uuid.UUID, except uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	q := `
+UPDATE
+    sessions
+SET
+    revoked_at = $3
+WHERE
+    user_id = $1 AND
+    id <> $2 AND
+    revoked_at IS NULL
+RETURNING id
+`
+
+	var ids []uuid.UUID
+	err := storage.client.Select(ctx, &ids, q, userID, except, now)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ids, apperror.Internal.WithError(err)
+	}
+
+	return ids, nil
+}
+
+func (storage *authStorage) DeleteExpiredSessions(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	q := `
+DELETE FROM
+	sessions
+WHERE
+	user_id = $1 AND
+    expires_at < $2
+`
+
+	_, err := storage.client.Exec(ctx, q, userID, now)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"cc/internal/model"
+	"cc/pkg/apperror"
+	"cc/pkg/postgres"
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+type BioStorage interface {
+	Save(ctx context.Context, page model.BioPage) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+
+	GetByUser(ctx context.Context, userID uuid.UUID) (model.BioPage, error)
+	GetByName(ctx context.Context, name string) (model.BioPage, error)
+
+	SelectLinkStats(ctx context.Context, pageID uuid.UUID, from, to string) ([]model.BioLinkStats, error)
+}
+
+type bioStorage struct {
+	client postgres.Client
+}
+
+func NewBioStorage(client postgres.Client) BioStorage {
+	return &bioStorage{client: client}
+}
+
+const bioPageColumns = `
+       id,
+       user_id,
+       name,
+       title,
+       avatar_url,
+       theme,
+       shorten_ids,
+       created_at,
+       updated_at`
+
+// Save creates the user's page or replaces it; a user has at most one.
+func (storage *bioStorage) Save(ctx context.Context, page model.BioPage) error {
+	q := `
+INSERT INTO
+    bio_pages (id, user_id, name, title, avatar_url, theme, shorten_ids, created_at, updated_at)
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (user_id) DO UPDATE
+SET name        = excluded.name,
+    title       = excluded.title,
+    avatar_url  = excluded.avatar_url,
+    theme       = excluded.theme,
+    shorten_ids = excluded.shorten_ids,
+    updated_at  = excluded.updated_at
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		page.ID,
+		page.UserID,
+		page.Name,
+		page.Title,
+		page.AvatarURL,
+		page.Theme,
+		page.ShortenIDs,
+		page.CreatedAt,
+		page.UpdatedAt,
+	)
+	if err != nil {
+		// Another user took the name since the service checked it.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return apperror.AlreadyExists.WithMessage("name is already taken")
+		}
+
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *bioStorage) Delete(ctx context.Context, userID uuid.UUID) error {
+	q := `
+DELETE FROM
+    bio_pages
+WHERE
+    user_id = $1
+`
+
+	tag, err := storage.client.Exec(ctx, q, userID)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperror.NotFound.WithMessage("you have no bio page")
+	}
+
+	return nil
+}
+
+func (storage *bioStorage) GetByUser(ctx context.Context, userID uuid.UUID) (model.BioPage, error) {
+	return storage.getBy(ctx, "user_id", userID)
+}
+
+func (storage *bioStorage) GetByName(ctx context.Context, name string) (model.BioPage, error) {
+	return storage.getBy(ctx, "name", name)
+}
+
+func (storage *bioStorage) getBy(ctx context.Context, column string, value any) (model.BioPage, error) {
+	q := `
+SELECT ` + bioPageColumns + `
+FROM bio_pages
+WHERE ` + column + ` = $1`
+
+	var page model.BioPage
+	err := storage.client.Get(ctx, &page, q, value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return page, apperror.NotFound.WithError(err)
+		}
+
+		return page, apperror.Internal.WithError(err)
+	}
+
+	return page, nil
+}
+
+func (storage *bioStorage) SelectLinkStats(ctx context.Context, pageID uuid.UUID, from, to string) ([]model.BioLinkStats, error) {
+	q := `
+SELECT shorten_id,
+       COUNT(*) AS clicks
+FROM clicks
+WHERE bio_page_id = $1
+  AND timestamp BETWEEN $2::TIMESTAMPTZ AND $3::TIMESTAMPTZ + INTERVAL '23 hour 59 minute'
+GROUP BY shorten_id
+ORDER BY clicks DESC
+`
+
+	var stats []model.BioLinkStats
+	err := storage.client.Select(ctx, &stats, q, pageID, from, to)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return stats, apperror.Internal.WithError(err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"cc/internal/model"
+	"cc/pkg/apperror"
+	"cc/pkg/postgres"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// RevisionStorage keeps the history of shortens. Revisions are never updated
+// or deleted, except along with their shorten.
+type RevisionStorage interface {
+	Create(ctx context.Context, revision model.Revision) error
+
+	GetByID(ctx context.Context, shortenID uint64, id int64) (model.Revision, error)
+	SelectByShorten(ctx context.Context, shortenID uint64) (model.Revisions, error)
+
+	SelectDestinationChanges(ctx context.Context, shortenID uint64, from, to string) ([]model.DestinationChange, error)
+}
+
+type revisionStorage struct {
+	client postgres.Client
+}
+
+func NewRevisionStorage(client postgres.Client) RevisionStorage {
+	return &revisionStorage{client: client}
+}
+
+const revisionColumns = `
+       id,
+       shorten_id,
+       user_id,
+       action,
+       changes,
+       state,
+       created_at`
+
+func (storage *revisionStorage) Create(ctx context.Context, revision model.Revision) error {
+	q := `
+INSERT INTO
+    shorten_revisions (shorten_id, user_id, action, changes, state, created_at)
+VALUES
+    ($1, $2, $3, $4, $5, $6)
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		revision.ShortenID,
+		revision.UserID,
+		revision.Action,
+		revision.Changes,
+		revision.State,
+		revision.CreatedAt,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *revisionStorage) GetByID(ctx context.Context, shortenID uint64, id int64) (model.Revision, error) {
+	q := `
+SELECT ` + revisionColumns + `
+FROM shorten_revisions
+WHERE shorten_id = $1
+  AND id = $2`
+
+	var revision model.Revision
+	err := storage.client.Get(ctx, &revision, q, shortenID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return revision, apperror.NotFound.WithMessage("revision not found")
+		}
+
+		return revision, apperror.Internal.WithError(err)
+	}
+
+	return revision, nil
+}
+
+// SelectByShorten returns the newest revisions first.
+func (storage *revisionStorage) SelectByShorten(ctx context.Context, shortenID uint64) (model.Revisions, error) {
+	q := `
+SELECT ` + revisionColumns + `
+FROM shorten_revisions
+WHERE shorten_id = $1
+ORDER BY id DESC`
+
+	var revisions model.Revisions
+	err := storage.client.Select(ctx, &revisions, q, shortenID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return revisions, apperror.Internal.WithError(err)
+	}
+
+	return revisions, nil
+}
+
+func (storage *revisionStorage) SelectDestinationChanges(ctx context.Context, shortenID uint64, from, to string) ([]model.DestinationChange, error) {
+	q := `
+SELECT changes -> 'url' ->> 'from' AS "from",
+       changes -> 'url' ->> 'to'   AS "to",
+       created_at                  AS timestamp
+FROM shorten_revisions
+WHERE shorten_id = $1
+  AND action <> 'create'
+  AND changes -> 'url' IS NOT NULL
+  AND created_at BETWEEN $2::TIMESTAMPTZ AND $3::TIMESTAMPTZ + INTERVAL '23 hour 59 minute'
+ORDER BY created_at
+`
+
+	var changes []model.DestinationChange
+	err := storage.client.Select(ctx, &changes, q, shortenID, from, to)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return changes, apperror.Internal.WithError(err)
+	}
+
+	return changes, nil
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"cc/internal/domain"
 	"cc/internal/model"
 	"cc/pkg/apperror"
 	"cc/pkg/postgres"
@@ -8,12 +9,19 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"time"
 )
 
+const uniqueViolation = "23505"
+
 //go:generate moq -out shorten_mock.go . ShortenStorage
 type ShortenStorage interface {
 	Create(ctx context.Context, shorten model.Shorten) error
-	Delete(ctx context.Context, userID uuid.UUID, shortenID uint64) error
+	Delete(ctx context.Context, shortenID uint64, deletedAt time.Time) error
+	Restore(ctx context.Context, shortenID uint64) error
+	SelectDeleted(ctx context.Context, workspaceID uuid.UUID) (model.Shortens, error)
+	Purge(ctx context.Context, before time.Time) ([]uint64, error)
 
 	Update(ctx context.Context, shorten model.Shorten) error
 
@@ -22,13 +30,72 @@ type ShortenStorage interface {
 
 	SelectByUser(ctx context.Context, userID uuid.UUID) (model.Shortens, error)
 	SelectByTags(ctx context.Context, userID uuid.UUID, tags []string) (model.Shortens, error)
+	SelectByWorkspace(ctx context.Context, workspaceID uuid.UUID, tags []string) (model.Shortens, error)
+	SelectByHealth(ctx context.Context, userID uuid.UUID, status string, tags []string) (model.Shortens, error)
+
+	GetRedirect(ctx context.Context, shortenID uint64) (model.Redirect, error)
+
+	Search(ctx context.Context, url, domain string, limit, offset int) (model.Shortens, error)
+	SetDisabled(ctx context.Context, shortenID uint64, reason string, disabledAt *time.Time) error
+	DisableByUser(ctx context.Context, userID uuid.UUID, reason string, now time.Time) ([]uint64, error)
+	Count(ctx context.Context) (total int64, disabled int64, err error)
+
+	SelectForScreening(ctx context.Context, before time.Time, limit int) (model.Shortens, error)
+	SetScreening(ctx context.Context, shortenID uint64, quarantinedAt *time.Time, reason string, screenedAt time.Time) error
 
-	GetURL(ctx context.Context, shortenID uint64) (string, error)
+	SelectForHealthCheck(ctx context.Context, before time.Time, limit int) (model.Shortens, error)
+	SetHealth(ctx context.Context, shortenID uint64, health model.Health) error
 
-	ExistsByID(ctx context.Context, userID uuid.UUID, id uint64) (bool, error)
+	SetPreview(ctx context.Context, shortenID uint64, preview model.Preview, title, placeholder string) error
+
+	SetRules(ctx context.Context, shortenID uint64, rules domain.Rules, updatedAt time.Time) error
+	SetVariants(ctx context.Context, shortenID uint64, variants domain.Variants, updatedAt time.Time) error
+
+	SelectByIDs(ctx context.Context, ids []uint64) (model.Shortens, error)
+
+	ExistsByID(ctx context.Context, id uint64) (bool, error)
 	ExistsByURL(ctx context.Context, userID uuid.UUID, url string) (bool, error)
 }
 
+const shortenColumns = `
+       shortens.id,
+       shortens.url,
+       shortens.user_id,
+       shortens.workspace_id,
+       shortens.title,
+       shortens.tags,
+       shortens.rules,
+       shortens.variants,
+       shortens.redirect_type,
+       shortens.pixel_url,
+       shortens.deep_link,
+       shortens.expires_at,
+       shortens.deleted_at,
+       shortens.disabled_at,
+       shortens.disabled_reason,
+       shortens.quarantined_at,
+       shortens.quarantine_reason,
+       shortens.screened_at,
+       shortens.health_status,
+       shortens.health_status_code,
+       shortens.health_latency_ms,
+       shortens.health_final_url,
+       shortens.health_error,
+       shortens.health_failures,
+       shortens.health_checked_at,
+       shortens.preview_title,
+       shortens.preview_description,
+       shortens.preview_og_title,
+       shortens.preview_og_image,
+       shortens.preview_favicon,
+       shortens.preview_error,
+       shortens.preview_fetched_at,
+       shortens.og_title,
+       shortens.og_description,
+       shortens.og_image,
+       shortens.created_at,
+       shortens.updated_at`
+
 type shortenStorage struct {
 	client postgres.Client
 }
@@ -40,21 +107,43 @@ func NewShortenStorage(client postgres.Client) ShortenStorage {
 func (storage *shortenStorage) Create(ctx context.Context, shorten model.Shorten) error {
 	q := `
 INSERT INTO 
-    shortens (id, url, user_id, title, created_at, updated_at, tags) 
+    shortens (id, url, user_id, workspace_id, title, created_at, updated_at, tags, quarantined_at, quarantine_reason, screened_at, og_title, og_description, og_image, redirect_type, pixel_url, expires_at, deep_link) 
 VALUES 
-    ($1, $2, $3, $4, $5, $6, $7)
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 `
 
 	_, err := storage.client.Exec(ctx, q,
 		shorten.ID,
 		shorten.URL,
 		shorten.UserID,
+		shorten.WorkspaceID,
 		shorten.Title,
 		shorten.CreatedAt,
 		shorten.UpdatedAt,
 		shorten.Tags,
+		shorten.QuarantinedAt,
+		shorten.QuarantineReason,
+		shorten.ScreenedAt,
+		shorten.OpenGraph.Title,
+		shorten.OpenGraph.Description,
+		shorten.OpenGraph.Image,
+		shorten.RedirectType,
+		shorten.PixelURL,
+		shorten.ExpiresAt,
+		shorten.DeepLink,
 	)
 	if err != nil {
+		// ExistsByID and ExistsByURL are checked first, so this is only
+		// reached by a concurrent create.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			if pgErr.ConstraintName == "shortens_pkey" {
+				return apperror.AlreadyExists.WithMessage("key already exist")
+			}
+
+			return apperror.AlreadyExists.WithMessage("url already exist")
+		}
+
 		return apperror.Internal.WithError(err)
 	}
 
@@ -65,13 +154,22 @@ func (storage *shortenStorage) Update(ctx context.Context, shorten model.Shorten
 	q := `
 UPDATE
     shortens
-SET url        = $1,
-    title      = $2,
-    created_at = $3,
-    updated_at = $4,
-    tags       = $5
-WHERE id = $6
-  AND user_id = $7;
+SET url               = $1,
+    title             = $2,
+    created_at        = $3,
+    updated_at        = $4,
+    tags              = $5,
+    quarantined_at    = $6,
+    quarantine_reason = $7,
+    screened_at       = $8,
+    og_title          = $9,
+    og_description    = $10,
+    og_image          = $11,
+    redirect_type     = $12,
+    pixel_url         = $13,
+    expires_at        = $14,
+    deep_link         = $15
+WHERE id = $16;
 `
 
 	_, err := storage.client.Exec(ctx, q,
@@ -80,8 +178,17 @@ WHERE id = $6
 		shorten.CreatedAt,
 		shorten.UpdatedAt,
 		shorten.Tags,
+		shorten.QuarantinedAt,
+		shorten.QuarantineReason,
+		shorten.ScreenedAt,
+		shorten.OpenGraph.Title,
+		shorten.OpenGraph.Description,
+		shorten.OpenGraph.Image,
+		shorten.RedirectType,
+		shorten.PixelURL,
+		shorten.ExpiresAt,
+		shorten.DeepLink,
 		shorten.ID,
-		shorten.UserID,
 	)
 	if err != nil {
 		return apperror.Internal.WithError(err)
@@ -90,23 +197,89 @@ WHERE id = $6
 	return nil
 }
 
-func (storage *shortenStorage) Delete(ctx context.Context, userID uuid.UUID, shortenID uint64) error {
+// Delete moves a shorten to the trash. It keeps its key and clicks until
+// purged.
+func (storage *shortenStorage) Delete(ctx context.Context, shortenID uint64, deletedAt time.Time) error {
 	q := `
-DELETE FROM 
-	shortens
-WHERE
-	user_id = $1 AND 
-    id = $2
+UPDATE
+    shortens
+SET deleted_at = $2
+WHERE id = $1
+  AND deleted_at IS NULL
+`
+
+	_, err := storage.client.Exec(ctx, q, shortenID, deletedAt)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *shortenStorage) Restore(ctx context.Context, shortenID uint64) error {
+	q := `
+UPDATE
+    shortens
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
 `
 
-	_, err := storage.client.Exec(ctx, q, userID, shortenID)
+	tag, err := storage.client.Exec(ctx, q, shortenID)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return apperror.AlreadyExists.WithMessage("url already exist")
+		}
+
 		return apperror.Internal.WithError(err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return apperror.NotFound.WithMessage("shorten is not in the trash")
+	}
+
 	return nil
 }
 
+// SelectDeleted lists the trash of a workspace, most recently deleted first.
+func (storage *shortenStorage) SelectDeleted(ctx context.Context, workspaceID uuid.UUID) (model.Shortens, error) {
+	q := `
+SELECT ` + shortenColumns + `
+FROM shortens
+WHERE workspace_id = $1
+  AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+	var shortens model.Shortens
+	err := storage.client.Select(ctx, &shortens, q, workspaceID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return shortens, apperror.Internal.WithError(err)
+	}
+
+	return shortens, nil
+}
+
+// Purge deletes shortens that were moved to the trash before the given time
+// along with their clicks.
+func (storage *shortenStorage) Purge(ctx context.Context, before time.Time) ([]uint64, error) {
+	q := `
+DELETE FROM
+    shortens
+WHERE deleted_at < $1
+RETURNING id
+`
+
+	var ids []uint64
+	err := storage.client.Select(ctx, &ids, q, before)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ids, apperror.Internal.WithError(err)
+	}
+
+	return ids, nil
+}
+
 func (storage *shortenStorage) GetByID(ctx context.Context, id uint64) (model.Shorten, error) {
 	return storage.getBy(ctx, "id", id)
 }
@@ -115,27 +288,40 @@ func (storage *shortenStorage) GetByURL(ctx context.Context, url string) (model.
 	return storage.getBy(ctx, "url", url)
 }
 
-func (storage *shortenStorage) GetURL(ctx context.Context, shortenID uint64) (string, error) {
+func (storage *shortenStorage) GetRedirect(ctx context.Context, shortenID uint64) (model.Redirect, error) {
 	q := `
-SELECT 
-    url 
-FROM 
-    shortens 
-WHERE 
-    id = $1
+SELECT shortens.url,
+       shortens.disabled_at,
+       shortens.quarantined_at,
+       shortens.quarantine_reason,
+       shortens.og_title,
+       shortens.og_description,
+       shortens.og_image,
+       shortens.rules,
+       shortens.variants,
+       shortens.title,
+       shortens.redirect_type,
+       shortens.pixel_url,
+       shortens.expires_at,
+       shortens.deep_link,
+       COALESCE(workspaces.branding, '{}') AS branding
+FROM shortens
+         LEFT JOIN workspaces ON workspaces.id = shortens.workspace_id
+WHERE shortens.id = $1
+  AND shortens.deleted_at IS NULL
 `
 
-	var url string
-	err := storage.client.Get(ctx, &url, q, shortenID)
+	var redirect model.Redirect
+	err := storage.client.Get(ctx, &redirect, q, shortenID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return url, apperror.NotFound
+			return redirect, apperror.NotFound
 		}
 
-		return url, apperror.Internal.WithError(err)
+		return redirect, apperror.Internal.WithError(err)
 	}
 
-	return url, nil
+	return redirect, nil
 }
 
 func (storage *shortenStorage) SelectByUser(ctx context.Context, id uuid.UUID) (model.Shortens, error) {
@@ -144,16 +330,11 @@ func (storage *shortenStorage) SelectByUser(ctx context.Context, id uuid.UUID) (
 
 func (storage *shortenStorage) SelectByTags(ctx context.Context, userID uuid.UUID, tags []string) (model.Shortens, error) {
 	q := `
-SELECT id,
-       url,
-       user_id,
-       title,
-       tags,
-       created_at,
-       updated_at
+SELECT ` + shortenColumns + `
 FROM shortens
 WHERE user_id = $1
   AND tags @> $2
+  AND deleted_at IS NULL
 `
 
 	var shortens model.Shortens
@@ -165,25 +346,87 @@ WHERE user_id = $1
 	return shortens, nil
 }
 
-func (storage *shortenStorage) ExistsByID(ctx context.Context, userID uuid.UUID, id uint64) (bool, error) {
-	return storage.existsBy(ctx, userID, "id", id)
+func (storage *shortenStorage) SelectByWorkspace(ctx context.Context, workspaceID uuid.UUID, tags []string) (model.Shortens, error) {
+	q := `
+SELECT ` + shortenColumns + `
+FROM shortens
+WHERE workspace_id = $1
+  AND tags @> $2
+  AND deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var shortens model.Shortens
+	err := storage.client.Select(ctx, &shortens, q, workspaceID, tags)
+	if err != nil && errors.Is(err, pgx.ErrNoRows) == false {
+		return shortens, apperror.Internal.WithError(err)
+	}
+
+	return shortens, nil
+}
+
+func (storage *shortenStorage) SelectByHealth(ctx context.Context, userID uuid.UUID, status string, tags []string) (model.Shortens, error) {
+	q := `
+SELECT ` + shortenColumns + `
+FROM shortens
+WHERE user_id = $1
+  AND health_status = $2
+  AND tags @> $3
+  AND deleted_at IS NULL
+ORDER BY health_checked_at DESC
+`
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var shortens model.Shortens
+	err := storage.client.Select(ctx, &shortens, q, userID, status, tags)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return shortens, apperror.Internal.WithError(err)
+	}
+
+	return shortens, nil
+}
+
+// ExistsByID checks the key across all users and also counts shortens in the
+// trash, whose keys stay reserved until they are purged.
+func (storage *shortenStorage) ExistsByID(ctx context.Context, id uint64) (bool, error) {
+	q := `
+SELECT
+    EXISTS (
+		SELECT
+			1
+		FROM
+			shortens
+		WHERE
+			id = $1
+	)
+`
+
+	var exists bool
+	err := storage.client.Get(ctx, &exists, q, id)
+	if err != nil {
+		return exists, apperror.Internal.WithError(err)
+	}
+
+	return exists, nil
 }
 
 func (storage *shortenStorage) ExistsByURL(ctx context.Context, userID uuid.UUID, url string) (bool, error) {
-	return storage.existsBy(ctx, userID, "url", url)
+	return storage.existsBy(ctx, userID, "url", url, "deleted_at IS NULL AND")
 }
 
 func (storage *shortenStorage) getBy(ctx context.Context, column string, value any) (model.Shorten, error) {
 	q := `
-SELECT id,
-       url,
-       user_id,
-       title,
-       tags,
-       created_at,
-       updated_at
+SELECT ` + shortenColumns + `
 FROM shortens
-WHERE ` + column + ` = $1`
+WHERE ` + column + ` = $1
+  AND deleted_at IS NULL`
 
 	var shorten model.Shorten
 	err := storage.client.Get(ctx, &shorten, q, value)
@@ -198,17 +441,33 @@ WHERE ` + column + ` = $1`
 	return shorten, nil
 }
 
+func (storage *shortenStorage) SelectByIDs(ctx context.Context, ids []uint64) (model.Shortens, error) {
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		values[i] = int64(id)
+	}
+
+	q := `
+SELECT ` + shortenColumns + `
+FROM shortens
+WHERE id = ANY($1)
+  AND deleted_at IS NULL`
+
+	var shortens model.Shortens
+	err := storage.client.Select(ctx, &shortens, q, values)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return shortens, apperror.Internal.WithError(err)
+	}
+
+	return shortens, nil
+}
+
 func (storage *shortenStorage) selectBy(ctx context.Context, column string, value any) (model.Shortens, error) {
 	q := `
-SELECT id,
-       url,
-       user_id,
-       title,
-       tags,
-       created_at,
-       updated_at
+SELECT ` + shortenColumns + `
 FROM shortens
-WHERE ` + column + ` = $1`
+WHERE ` + column + ` = $1
+  AND deleted_at IS NULL`
 
 	var shortens model.Shortens
 	err := storage.client.Select(ctx, &shortens, q, value)
@@ -223,7 +482,7 @@ WHERE ` + column + ` = $1`
 	return shortens, nil
 }
 
-func (storage *shortenStorage) existsBy(ctx context.Context, userID uuid.UUID, column string, value any) (bool, error) {
+func (storage *shortenStorage) existsBy(ctx context.Context, userID uuid.UUID, column string, value any, filter string) (bool, error) {
 	q := `
 SELECT 
     EXISTS (
@@ -232,6 +491,7 @@ SELECT
 		FROM 
 			shortens 
 		WHERE 
+			` + filter + `
 			` + column + ` = $1 AND 
 			user_id = $2
 	)
@@ -245,3 +505,277 @@ SELECT
 
 	return exists, nil
 }
+
+// Search matches url as a case-insensitive substring and domain against the
+// destination host or any of its subdomains. Empty filters match everything.
+func (storage *shortenStorage) Search(ctx context.Context, url, domain string, limit, offset int) (model.Shortens, error) {
+	q := `
+SELECT ` + shortenColumns + `
+FROM shortens
+WHERE deleted_at IS NULL
+  AND ($1 = '' OR url ILIKE '%' || $1 || '%')
+  AND ($2 = '' OR
+       LOWER(SUBSTRING(url FROM '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/]*@)?([^/:?#]+)')) = LOWER($2) OR
+       LOWER(SUBSTRING(url FROM '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/]*@)?([^/:?#]+)')) LIKE '%.' || LOWER($2))
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4
+`
+
+	var shortens model.Shortens
+	err := storage.client.Select(ctx, &shortens, q, url, domain, limit, offset)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return shortens, apperror.Internal.WithError(err)
+	}
+
+	return shortens, nil
+}
+
+func (storage *shortenStorage) SetDisabled(ctx context.Context, shortenID uint64, reason string, disabledAt *time.Time) error {
+	q := `
+UPDATE
+    shortens
+SET
+    disabled_at = $2,
+    disabled_reason = $3
+WHERE
+    id = $1
+`
+
+	tag, err := storage.client.Exec(ctx, q, shortenID, disabledAt, reason)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperror.NotFound.WithMessage("shorten with this id does not exist")
+	}
+
+	return nil
+}
+
+func (storage *shortenStorage) DisableByUser(ctx context.Context, userID uuid.UUID, reason string, now time.Time) ([]uint64, error) {
+	q := `
+UPDATE
+    shortens
+SET
+    disabled_at = $3,
+    disabled_reason = $2
+WHERE
+    user_id = $1 AND
+    disabled_at IS NULL
+RETURNING id
+`
+
+	var ids []uint64
+	err := storage.client.Select(ctx, &ids, q, userID, reason, now)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ids, apperror.Internal.WithError(err)
+	}
+
+	return ids, nil
+}
+
+func (storage *shortenStorage) Count(ctx context.Context) (total int64, disabled int64, err error) {
+	q := `
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE disabled_at IS NOT NULL) AS disabled
+FROM
+    shortens
+WHERE
+    deleted_at IS NULL
+`
+
+	var counts struct {
+		Total    int64 `db:"total"`
+		Disabled int64 `db:"disabled"`
+	}
+
+	err = storage.client.Get(ctx, &counts, q)
+	if err != nil {
+		return 0, 0, apperror.Internal.WithError(err)
+	}
+
+	return counts.Total, counts.Disabled, nil
+}
+
+// SelectForScreening returns shortens that were never screened or were last
+// screened before the given time, oldest first.
+func (storage *shortenStorage) SelectForScreening(ctx context.Context, before time.Time, limit int) (model.Shortens, error) {
+	q := `
+SELECT ` + shortenColumns + `
+FROM shortens
+WHERE deleted_at IS NULL
+  AND (screened_at IS NULL OR screened_at < $1)
+ORDER BY screened_at NULLS FIRST
+LIMIT $2
+`
+
+	var shortens model.Shortens
+	err := storage.client.Select(ctx, &shortens, q, before, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return shortens, apperror.Internal.WithError(err)
+	}
+
+	return shortens, nil
+}
+
+func (storage *shortenStorage) SetScreening(ctx context.Context, shortenID uint64, quarantinedAt *time.Time, reason string, screenedAt time.Time) error {
+	q := `
+UPDATE
+    shortens
+SET
+    quarantined_at = $2,
+    quarantine_reason = $3,
+    screened_at = $4
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q, shortenID, quarantinedAt, reason, screenedAt)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+// SelectForHealthCheck returns enabled shortens that were not checked since
+// before, least recently checked first.
+func (storage *shortenStorage) SelectForHealthCheck(ctx context.Context, before time.Time, limit int) (model.Shortens, error) {
+	q := `
+SELECT ` + shortenColumns + `
+FROM shortens
+WHERE disabled_at IS NULL
+  AND deleted_at IS NULL
+  AND (health_checked_at IS NULL OR health_checked_at < $1)
+ORDER BY health_checked_at NULLS FIRST
+LIMIT $2
+`
+
+	var shortens model.Shortens
+	err := storage.client.Select(ctx, &shortens, q, before, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return shortens, apperror.Internal.WithError(err)
+	}
+
+	return shortens, nil
+}
+
+func (storage *shortenStorage) SetHealth(ctx context.Context, shortenID uint64, health model.Health) error {
+	q := `
+UPDATE
+    shortens
+SET
+    health_status = $2,
+    health_status_code = $3,
+    health_latency_ms = $4,
+    health_final_url = $5,
+    health_error = $6,
+    health_failures = $7,
+    health_checked_at = $8
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		shortenID,
+		health.Status,
+		health.StatusCode,
+		health.LatencyMS,
+		health.FinalURL,
+		health.Error,
+		health.Failures,
+		health.CheckedAt,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+// SetPreview stores fetched page metadata. The title is only replaced with
+// title while it still equals placeholder, the host name used until the page
+// was fetched, so titles set by users are kept.
+func (storage *shortenStorage) SetPreview(ctx context.Context, shortenID uint64, preview model.Preview, title, placeholder string) error {
+	q := `
+UPDATE
+    shortens
+SET
+    preview_title = $2,
+    preview_description = $3,
+    preview_og_title = $4,
+    preview_og_image = $5,
+    preview_favicon = $6,
+    preview_error = $7,
+    preview_fetched_at = $8,
+    title = CASE WHEN $9 <> '' AND title = $10 THEN $9 ELSE title END
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		shortenID,
+		preview.Title,
+		preview.Description,
+		preview.OGTitle,
+		preview.OGImage,
+		preview.Favicon,
+		preview.Error,
+		preview.FetchedAt,
+		title,
+		placeholder,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *shortenStorage) SetRules(ctx context.Context, shortenID uint64, rules domain.Rules, updatedAt time.Time) error {
+	q := `
+UPDATE
+    shortens
+SET
+    rules = $2,
+    updated_at = $3
+WHERE
+    id = $1
+`
+
+	if rules == nil {
+		rules = domain.Rules{}
+	}
+
+	_, err := storage.client.Exec(ctx, q, shortenID, rules, updatedAt)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *shortenStorage) SetVariants(ctx context.Context, shortenID uint64, variants domain.Variants, updatedAt time.Time) error {
+	q := `
+UPDATE
+    shortens
+SET
+    variants = $2,
+    updated_at = $3
+WHERE
+    id = $1
+`
+
+	if variants == nil {
+		variants = domain.Variants{}
+	}
+
+	_, err := storage.client.Exec(ctx, q, shortenID, variants, updatedAt)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
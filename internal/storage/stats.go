@@ -8,18 +8,24 @@ import (
 	"context"
 	"github.com/jackc/pgx/v5"
 	"github.com/pkg/errors"
+	"time"
 )
 
 const (
 	PlatformColumn = "platform"
 	OSColumn       = "os"
 	RefererColumn  = "referer"
+	SourceColumn   = "source"
+	RuleColumn     = "rule"
+	VariantColumn  = "variant"
+	OpenColumn     = "open"
 )
 
 type StatsStorage interface {
 	CreateClick(ctx context.Context, click model.Click) error
 
 	GetClicksSummary(ctx context.Context, shortenID uint64, from, to string) (int64, error)
+	CountClicks(ctx context.Context, since time.Time) (total int64, recent int64, err error)
 	SelectClicks(ctx context.Context, shortenID uint64, from, to string) ([]model.Click, error)
 
 	SelectClickMetric(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) (model.ClickMetric, error)
@@ -28,6 +34,15 @@ type StatsStorage interface {
 	SelectPlatformMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error)
 	SelectOSMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error)
 	SelectRefererMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error)
+	SelectSourceMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error)
+	SelectRuleMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error)
+	SelectVariantMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error)
+	SelectOpenMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error)
+
+	// AddNotFoundHits adds the hits to the counts already stored.
+	AddNotFoundHits(ctx context.Context, hits []model.NotFoundHit) error
+	PurgeNotFoundHits(ctx context.Context, before time.Time) (int64, error)
+	SelectMissedKeys(ctx context.Context, since time.Time, limit int) (model.MissedKeys, error)
 }
 
 type statsStorage struct {
@@ -41,9 +56,9 @@ func NewStatsStorage(client postgres.Client) StatsStorage {
 func (storage *statsStorage) CreateClick(ctx context.Context, click model.Click) error {
 	q := `
 INSERT INTO 
-    clicks (shorten_id, platform, os, referer, ip, timestamp) 
+    clicks (shorten_id, platform, os, referer, ip, timestamp, source, rule, variant, bio_page_id, open) 
 VALUES 
-    ($1, $2, $3, $4, $5, $6)
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 `
 
 	_, err := storage.client.Exec(ctx, q,
@@ -53,6 +68,11 @@ VALUES
 		click.Referer,
 		click.IP,
 		click.Timestamp,
+		click.Source,
+		click.Rule,
+		click.Variant,
+		click.BioPageID,
+		click.Open,
 	)
 	if err != nil {
 		return apperror.Internal.WithError(err)
@@ -79,6 +99,27 @@ WHERE shorten_id = $1
 	return total, nil
 }
 
+func (storage *statsStorage) CountClicks(ctx context.Context, since time.Time) (total int64, recent int64, err error) {
+	q := `
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE timestamp >= $1) AS recent
+FROM clicks
+`
+
+	var counts struct {
+		Total  int64 `db:"total"`
+		Recent int64 `db:"recent"`
+	}
+
+	err = storage.client.Get(ctx, &counts, q, since)
+	if err != nil {
+		return 0, 0, apperror.Internal.WithError(err)
+	}
+
+	return counts.Total, counts.Recent, nil
+}
+
 func (storage *statsStorage) SelectClicks(ctx context.Context, shortenID uint64, from, to string) ([]model.Click, error) {
 	q := `
 SELECT shorten_id,
@@ -86,6 +127,10 @@ SELECT shorten_id,
        os,
        referer,
        ip,
+       source,
+       rule,
+       variant,
+       open,
        timestamp
 FROM clicks
 WHERE shorten_id = $1
@@ -208,3 +253,106 @@ func (storage *statsStorage) SelectOSMetrics(ctx context.Context, shortenID uint
 func (storage *statsStorage) SelectRefererMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error) {
 	return storage.SelectMetrics(ctx, shortenID, RefererColumn, from, to, unit, units)
 }
+
+func (storage *statsStorage) SelectSourceMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error) {
+	return storage.SelectMetrics(ctx, shortenID, SourceColumn, from, to, unit, units)
+}
+
+func (storage *statsStorage) SelectRuleMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error) {
+	return storage.SelectMetrics(ctx, shortenID, RuleColumn, from, to, unit, units)
+}
+
+// SelectVariantMetrics leaves out clicks that were not split, such as those
+// recorded before the split test was set up, which have no variant.
+func (storage *statsStorage) SelectVariantMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error) {
+	metrics, err := storage.SelectMetrics(ctx, shortenID, VariantColumn, from, to, unit, units)
+	if err != nil {
+		return metrics, err
+	}
+
+	variants := metrics[:0]
+	for _, metric := range metrics {
+		if metric.Name != "" {
+			variants = append(variants, metric)
+		}
+	}
+
+	return variants, nil
+}
+
+func (storage *statsStorage) SelectOpenMetrics(ctx context.Context, shortenID uint64, from, to string, unit domain.Unit, units int) ([]model.Metric, error) {
+	return storage.SelectMetrics(ctx, shortenID, OpenColumn, from, to, unit, units)
+}
+
+func (storage *statsStorage) AddNotFoundHits(ctx context.Context, hits []model.NotFoundHit) error {
+	q := `
+INSERT INTO
+    not_found_counts (key, referer, day, hits, last_seen_at)
+SELECT *
+FROM UNNEST($1::TEXT[], $2::TEXT[], $3::DATE[], $4::BIGINT[], $5::TIMESTAMPTZ[])
+ON CONFLICT (key, referer, day) DO UPDATE
+    SET hits         = not_found_counts.hits + excluded.hits,
+        last_seen_at = GREATEST(not_found_counts.last_seen_at, excluded.last_seen_at)
+`
+
+	keys := make([]string, len(hits))
+	referers := make([]string, len(hits))
+	days := make([]time.Time, len(hits))
+	counts := make([]int64, len(hits))
+	lastSeen := make([]time.Time, len(hits))
+	for i, hit := range hits {
+		keys[i], referers[i], days[i], counts[i], lastSeen[i] = hit.Key, hit.Referer, hit.Day, hit.Hits, hit.LastSeenAt
+	}
+
+	_, err := storage.client.Exec(ctx, q, keys, referers, days, counts, lastSeen)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *statsStorage) PurgeNotFoundHits(ctx context.Context, before time.Time) (int64, error) {
+	q := `
+DELETE
+FROM not_found_counts
+WHERE day < $1::DATE
+`
+
+	tag, err := storage.client.Exec(ctx, q, before)
+	if err != nil {
+		return 0, apperror.Internal.WithError(err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (storage *statsStorage) SelectMissedKeys(ctx context.Context, since time.Time, limit int) (model.MissedKeys, error) {
+	q := `
+WITH referers AS (
+    SELECT key,
+           referer,
+           SUM(hits)         AS hits,
+           MAX(last_seen_at) AS last_seen_at
+    FROM not_found_counts
+    WHERE day >= $1::DATE
+    GROUP BY key, referer
+)
+SELECT key,
+       SUM(hits)::BIGINT                                   AS hits,
+       (ARRAY_AGG(referer ORDER BY hits DESC, referer))[1] AS top_referer,
+       MAX(last_seen_at)                                   AS last_seen_at
+FROM referers
+GROUP BY key
+ORDER BY hits DESC, last_seen_at DESC
+LIMIT $2
+`
+
+	var keys model.MissedKeys
+	err := storage.client.Select(ctx, &keys, q, since, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return keys, apperror.Internal.WithError(err)
+	}
+
+	return keys, nil
+}
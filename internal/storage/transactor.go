@@ -0,0 +1,11 @@
+package storage
+
+import (
+	"context"
+)
+
+// Transactor runs fn in a transaction. Storages called with the context
+// passed to fn take part in it. postgres.Client is a Transactor.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"cc/internal/model"
+	"cc/pkg/apperror"
+	"cc/pkg/postgres"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type TwoFactorStorage interface {
+	GetTOTP(ctx context.Context, userID uuid.UUID) (model.TOTP, error)
+	SaveTOTP(ctx context.Context, totp model.TOTP) error
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, counter int64, now time.Time) error
+	UseCounter(ctx context.Context, userID uuid.UUID, counter int64) (bool, error)
+	DeleteTOTP(ctx context.Context, userID uuid.UUID) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes [][]byte, now time.Time) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte, now time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+type twoFactorStorage struct {
+	client postgres.Client
+}
+
+func NewTwoFactorStorage(client postgres.Client) TwoFactorStorage {
+	return &twoFactorStorage{client: client}
+}
+
+func (storage *twoFactorStorage) GetTOTP(ctx context.Context, userID uuid.UUID) (model.TOTP, error) {
+	q := `
+SELECT
+    user_id, secret, last_counter, created_at, confirmed_at
+FROM
+    user_totp
+WHERE
+    user_id = $1
+`
+
+	var totp model.TOTP
+	err := storage.client.Get(ctx, &totp, q, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return totp, apperror.NotFound.WithError(err)
+		}
+
+		return totp, apperror.Internal.WithError(err)
+	}
+
+	return totp, nil
+}
+
+// SaveTOTP stores a pending enrollment. A confirmed secret is never
+// overwritten.
+func (storage *twoFactorStorage) SaveTOTP(ctx context.Context, totp model.TOTP) error {
+	q := `
+INSERT INTO
+    user_totp (user_id, secret, last_counter, created_at)
+VALUES
+    ($1, $2, 0, $3)
+ON CONFLICT (user_id) DO UPDATE SET
+    secret = excluded.secret,
+    last_counter = 0,
+    created_at = excluded.created_at
+WHERE
+    user_totp.confirmed_at IS NULL
+`
+
+	_, err := storage.client.Exec(ctx, q, totp.UserID, totp.Secret, totp.CreatedAt)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *twoFactorStorage) ConfirmTOTP(ctx context.Context, userID uuid.UUID, counter int64, now time.Time) error {
+	q := `
+UPDATE
+    user_totp
+SET
+    confirmed_at = $3,
+    last_counter = $2
+WHERE
+    user_id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q, userID, counter, now)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+// UseCounter records counter as the last accepted time step and reports false
+// if it, or a later one, has already been used.
+func (storage *twoFactorStorage) UseCounter(ctx context.Context, userID uuid.UUID, counter int64) (bool, error) {
+	q := `
+UPDATE
+    user_totp
+SET
+    last_counter = $2
+WHERE
+    user_id = $1 AND
+    last_counter < $2
+`
+
+	tag, err := storage.client.Exec(ctx, q, userID, counter)
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (storage *twoFactorStorage) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	q := `
+WITH codes AS (
+    DELETE FROM
+        user_recovery_codes
+    WHERE
+        user_id = $1
+)
+DELETE FROM
+    user_totp
+WHERE
+    user_id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q, userID)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *twoFactorStorage) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes [][]byte, now time.Time) error {
+	q := `
+WITH previous AS (
+    DELETE FROM
+        user_recovery_codes
+    WHERE
+        user_id = $1
+)
+INSERT INTO
+    user_recovery_codes (user_id, code_hash, created_at)
+SELECT
+    $1, code_hash, $3
+FROM
+    UNNEST($2::BYTEA[]) AS code_hash
+`
+
+	_, err := storage.client.Exec(ctx, q, userID, hashes, now)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *twoFactorStorage) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte, now time.Time) (bool, error) {
+	q := `
+UPDATE
+    user_recovery_codes
+SET
+    used_at = $3
+WHERE
+    user_id = $1 AND
+    code_hash = $2 AND
+    used_at IS NULL
+`
+
+	tag, err := storage.client.Exec(ctx, q, userID, hash, now)
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (storage *twoFactorStorage) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	q := `
+SELECT
+    COUNT(*)
+FROM
+    user_recovery_codes
+WHERE
+    user_id = $1 AND
+    used_at IS NULL
+`
+
+	var count int
+	err := storage.client.Get(ctx, &count, q, userID)
+	if err != nil {
+		return count, apperror.Internal.WithError(err)
+	}
+
+	return count, nil
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"time"
 )
 
 type UserStorage interface {
@@ -15,6 +16,22 @@ type UserStorage interface {
 	GetByID(ctx context.Context, id uuid.UUID) (model.User, error)
 	GetByName(ctx context.Context, name string) (model.User, error)
 	ExistsUserByName(ctx context.Context, name string) (bool, error)
+	UpdateName(ctx context.Context, id uuid.UUID, name string) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, password []byte) error
+	// Delete removes the user along with the workspaces nobody else is a
+	// member of, moves their shortens to the trash and returns the ids of
+	// those that were live until now.
+	Delete(ctx context.Context, id uuid.UUID, deletedAt time.Time) ([]uint64, error)
+
+	GetByIdentity(ctx context.Context, issuer, subject string) (model.User, error)
+	CreateIdentity(ctx context.Context, identity model.Identity) error
+
+	UpdateRole(ctx context.Context, id uuid.UUID, role string) error
+	// PromoteByNames returns the names of the users whose role was changed.
+	PromoteByNames(ctx context.Context, names []string, role string) ([]string, error)
+	Ban(ctx context.Context, id uuid.UUID, reason string, now time.Time) error
+	Unban(ctx context.Context, id uuid.UUID) error
+	Count(ctx context.Context) (total int64, banned int64, err error)
 }
 
 type userStorage struct {
@@ -27,13 +44,24 @@ func NewUserStorage(client postgres.Client) UserStorage {
 
 func (storage *userStorage) CreateUser(ctx context.Context, user model.User) error {
 	q := `
-INSERT INTO 
-    users (id, name, password) 
-VALUES 
-	($1, $2, $3)
+WITH usr AS (
+    INSERT INTO
+        users (id, name, password, role)
+    VALUES
+        ($1, $2, $3, $4)
+), workspace AS (
+    INSERT INTO
+        workspaces (id, name, personal, created_at, updated_at)
+    VALUES
+        ($1, $2, TRUE, NOW(), NOW())
+)
+INSERT INTO
+    workspace_members (workspace_id, user_id, role, created_at)
+VALUES
+    ($1, $1, 'owner', NOW())
 `
 
-	_, err := storage.client.Exec(ctx, q, user.ID, user.Name, user.Password)
+	_, err := storage.client.Exec(ctx, q, user.ID, user.Name, user.Password, user.Role)
 	if err != nil {
 		return apperror.Internal.WithError(err)
 	}
@@ -44,7 +72,7 @@ VALUES
 func (storage *userStorage) GetByID(ctx context.Context, id uuid.UUID) (model.User, error) {
 	q := `
 SELECT 
-    id, name, password 
+    id, name, password, role, banned_at, ban_reason 
 FROM 
     users 
 WHERE 
@@ -67,7 +95,7 @@ WHERE
 func (storage *userStorage) GetByName(ctx context.Context, name string) (model.User, error) {
 	q := `
 SELECT 
-    id, name, password 
+    id, name, password, role, banned_at, ban_reason 
 FROM 
     users 
 WHERE 
@@ -108,3 +136,220 @@ SELECT
 
 	return exists, nil
 }
+
+func (storage *userStorage) UpdateName(ctx context.Context, id uuid.UUID, name string) error {
+	q := `
+UPDATE
+    users
+SET
+    name = $2
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q, id, name)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *userStorage) UpdatePassword(ctx context.Context, id uuid.UUID, password []byte) error {
+	q := `
+UPDATE
+    users
+SET
+    password = $2
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q, id, password)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *userStorage) Delete(ctx context.Context, id uuid.UUID, deletedAt time.Time) ([]uint64, error) {
+	q := `
+WITH abandoned AS (
+    DELETE FROM
+        workspaces
+    WHERE
+        id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1) AND
+        NOT EXISTS(SELECT 1 FROM workspace_members WHERE workspace_id = workspaces.id AND user_id <> $1)
+    RETURNING id
+), trashed AS (
+    UPDATE
+        shortens
+    SET deleted_at = $2
+    WHERE workspace_id IN (SELECT id FROM abandoned)
+      AND deleted_at IS NULL
+    RETURNING id
+), deleted AS (
+    DELETE FROM
+        users
+    WHERE
+        id = $1
+)
+SELECT id FROM trashed
+`
+
+	var ids []uint64
+	err := storage.client.Select(ctx, &ids, q, id, deletedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ids, apperror.Internal.WithError(err)
+	}
+
+	return ids, nil
+}
+
+func (storage *userStorage) GetByIdentity(ctx context.Context, issuer, subject string) (model.User, error) {
+	q := `
+SELECT
+    users.id, users.name, users.password, users.role, users.banned_at, users.ban_reason
+FROM
+    users
+        JOIN user_identities ON user_identities.user_id = users.id
+WHERE
+    user_identities.issuer = $1 AND
+    user_identities.subject = $2
+`
+
+	var user model.User
+	err := storage.client.Get(ctx, &user, q, issuer, subject)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, apperror.NotFound
+		}
+
+		return user, apperror.Internal.WithError(err)
+	}
+
+	return user, nil
+}
+
+func (storage *userStorage) CreateIdentity(ctx context.Context, identity model.Identity) error {
+	q := `
+INSERT INTO
+    user_identities (issuer, subject, user_id, email, created_at)
+VALUES
+    ($1, $2, $3, $4, $5)
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		identity.Issuer,
+		identity.Subject,
+		identity.UserID,
+		identity.Email,
+		identity.CreatedAt,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *userStorage) UpdateRole(ctx context.Context, id uuid.UUID, role string) error {
+	q := `
+UPDATE
+    users
+SET
+    role = $2
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q, id, role)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *userStorage) PromoteByNames(ctx context.Context, names []string, role string) ([]string, error) {
+	q := `
+UPDATE
+    users
+SET
+    role = $2
+WHERE
+    name = ANY ($1) AND
+    role <> $2
+RETURNING
+    name
+`
+
+	var promoted []string
+	err := storage.client.Select(ctx, &promoted, q, names, role)
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	return promoted, nil
+}
+
+func (storage *userStorage) Ban(ctx context.Context, id uuid.UUID, reason string, now time.Time) error {
+	q := `
+UPDATE
+    users
+SET
+    banned_at = $3,
+    ban_reason = $2
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q, id, reason, now)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *userStorage) Unban(ctx context.Context, id uuid.UUID) error {
+	q := `
+UPDATE
+    users
+SET
+    banned_at = NULL,
+    ban_reason = ''
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q, id)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *userStorage) Count(ctx context.Context) (total int64, banned int64, err error) {
+	q := `
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE banned_at IS NOT NULL) AS banned
+FROM
+    users
+`
+
+	var counts struct {
+		Total  int64 `db:"total"`
+		Banned int64 `db:"banned"`
+	}
+
+	err = storage.client.Get(ctx, &counts, q)
+	if err != nil {
+		return 0, 0, apperror.Internal.WithError(err)
+	}
+
+	return counts.Total, counts.Banned, nil
+}
@@ -0,0 +1,483 @@
+package storage
+
+import (
+	"cc/internal/model"
+	"cc/pkg/apperror"
+	"cc/pkg/postgres"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+//go:generate moq -out workspace_mock.go . WorkspaceStorage
+type WorkspaceStorage interface {
+	Create(ctx context.Context, workspace model.Workspace, ownerID uuid.UUID) error
+	Update(ctx context.Context, workspace model.Workspace) error
+	// Delete removes the workspace and moves its shortens to the trash,
+	// returning the ids of the shortens that were live until now.
+	Delete(ctx context.Context, id uuid.UUID, deletedAt time.Time) ([]uint64, error)
+
+	GetByID(ctx context.Context, id uuid.UUID) (model.Workspace, error)
+	SelectByUser(ctx context.Context, userID uuid.UUID) (model.Workspaces, error)
+	SelectSoleOwned(ctx context.Context, userID uuid.UUID) (model.Workspaces, error)
+
+	GetRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error)
+	GetAccess(ctx context.Context, workspaceID, userID uuid.UUID) (model.Access, error)
+	GetAccessByShorten(ctx context.Context, shortenID uint64, userID uuid.UUID) (model.Access, error)
+
+	SelectMembers(ctx context.Context, workspaceID uuid.UUID) (model.Members, error)
+	UpdateMember(ctx context.Context, member model.Member) error
+	DeleteMember(ctx context.Context, workspaceID, userID uuid.UUID) error
+	CountOwners(ctx context.Context, workspaceID uuid.UUID) (int, error)
+
+	CreateInvitation(ctx context.Context, invitation model.Invitation) error
+	SelectInvitations(ctx context.Context, workspaceID uuid.UUID) (model.Invitations, error)
+	DeleteInvitation(ctx context.Context, workspaceID, id uuid.UUID) (bool, error)
+	AcceptInvitation(ctx context.Context, tokenHash []byte, userID uuid.UUID, now time.Time) (model.Invitation, error)
+}
+
+type workspaceStorage struct {
+	client postgres.Client
+}
+
+func NewWorkspaceStorage(client postgres.Client) WorkspaceStorage {
+	return &workspaceStorage{client: client}
+}
+
+func (storage *workspaceStorage) Create(ctx context.Context, workspace model.Workspace, ownerID uuid.UUID) error {
+	q := `
+WITH workspace AS (
+    INSERT INTO
+        workspaces (id, name, personal, created_at, updated_at)
+    VALUES
+        ($1, $2, $3, $4, $5)
+)
+INSERT INTO
+    workspace_members (workspace_id, user_id, role, created_at)
+VALUES
+    ($1, $6, 'owner', $4)
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		workspace.ID,
+		workspace.Name,
+		workspace.Personal,
+		workspace.CreatedAt,
+		workspace.UpdatedAt,
+		ownerID,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *workspaceStorage) Update(ctx context.Context, workspace model.Workspace) error {
+	q := `
+UPDATE
+    workspaces
+SET
+    name = $2,
+    require_two_factor = $3,
+    branding = $4,
+    updated_at = $5
+WHERE
+    id = $1
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		workspace.ID,
+		workspace.Name,
+		workspace.RequireTwoFactor,
+		workspace.Branding,
+		workspace.UpdatedAt,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *workspaceStorage) Delete(ctx context.Context, id uuid.UUID, deletedAt time.Time) ([]uint64, error) {
+	q := `
+WITH trashed AS (
+    UPDATE
+        shortens
+    SET deleted_at = $2
+    WHERE workspace_id = $1
+      AND deleted_at IS NULL
+    RETURNING id
+), deleted AS (
+    DELETE FROM
+        workspaces
+    WHERE
+        id = $1
+)
+SELECT id FROM trashed
+`
+
+	var ids []uint64
+	err := storage.client.Select(ctx, &ids, q, id, deletedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ids, apperror.Internal.WithError(err)
+	}
+
+	return ids, nil
+}
+
+func (storage *workspaceStorage) GetByID(ctx context.Context, id uuid.UUID) (model.Workspace, error) {
+	q := `
+SELECT
+    id, name, personal, require_two_factor, branding, created_at, updated_at
+FROM
+    workspaces
+WHERE
+    id = $1
+`
+
+	var workspace model.Workspace
+	err := storage.client.Get(ctx, &workspace, q, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return workspace, apperror.NotFound.WithError(err)
+		}
+
+		return workspace, apperror.Internal.WithError(err)
+	}
+
+	return workspace, nil
+}
+
+func (storage *workspaceStorage) SelectByUser(ctx context.Context, userID uuid.UUID) (model.Workspaces, error) {
+	q := `
+SELECT workspaces.id,
+       workspaces.name,
+       workspaces.personal,
+       workspaces.require_two_factor,
+       workspaces.branding,
+       workspace_members.role,
+       workspaces.created_at,
+       workspaces.updated_at
+FROM workspaces
+         JOIN workspace_members ON workspace_members.workspace_id = workspaces.id
+WHERE workspace_members.user_id = $1
+ORDER BY workspaces.personal DESC, workspaces.created_at
+`
+
+	var workspaces model.Workspaces
+	err := storage.client.Select(ctx, &workspaces, q, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return workspaces, apperror.Internal.WithError(err)
+	}
+
+	return workspaces, nil
+}
+
+func (storage *workspaceStorage) SelectSoleOwned(ctx context.Context, userID uuid.UUID) (model.Workspaces, error) {
+	q := `
+SELECT workspaces.id,
+       workspaces.name,
+       workspaces.personal,
+       workspaces.require_two_factor,
+       workspaces.branding,
+       workspace_members.role,
+       workspaces.created_at,
+       workspaces.updated_at
+FROM workspaces
+         JOIN workspace_members ON workspace_members.workspace_id = workspaces.id
+WHERE workspace_members.user_id = $1
+  AND workspace_members.role = 'owner'
+  AND NOT workspaces.personal
+  AND NOT EXISTS(SELECT 1
+                 FROM workspace_members owners
+                 WHERE owners.workspace_id = workspaces.id
+                   AND owners.role = 'owner'
+                   AND owners.user_id <> $1)
+  AND EXISTS(SELECT 1
+             FROM workspace_members others
+             WHERE others.workspace_id = workspaces.id
+               AND others.user_id <> $1)
+`
+
+	var workspaces model.Workspaces
+	err := storage.client.Select(ctx, &workspaces, q, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return workspaces, apperror.Internal.WithError(err)
+	}
+
+	return workspaces, nil
+}
+
+func (storage *workspaceStorage) GetRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error) {
+	q := `
+SELECT
+    role
+FROM
+    workspace_members
+WHERE
+    workspace_id = $1 AND
+    user_id = $2
+`
+
+	var role string
+	err := storage.client.Get(ctx, &role, q, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return role, apperror.NotFound.WithError(err)
+		}
+
+		return role, apperror.Internal.WithError(err)
+	}
+
+	return role, nil
+}
+
+func (storage *workspaceStorage) GetAccess(ctx context.Context, workspaceID, userID uuid.UUID) (model.Access, error) {
+	q := `
+SELECT workspace_members.role,
+       workspaces.require_two_factor,
+       EXISTS(SELECT 1
+              FROM user_totp
+              WHERE user_totp.user_id = $2
+                AND user_totp.confirmed_at IS NOT NULL) AS two_factor
+FROM workspace_members
+         JOIN workspaces ON workspaces.id = workspace_members.workspace_id
+WHERE workspace_members.workspace_id = $1
+  AND workspace_members.user_id = $2
+`
+
+	var access model.Access
+	err := storage.client.Get(ctx, &access, q, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return access, apperror.NotFound.WithError(err)
+		}
+
+		return access, apperror.Internal.WithError(err)
+	}
+
+	return access, nil
+}
+
+// GetAccessByShorten resolves access through the shorten's workspace. Role is
+// empty when the user is not a member of it.
+func (storage *workspaceStorage) GetAccessByShorten(ctx context.Context, shortenID uint64, userID uuid.UUID) (model.Access, error) {
+	q := `
+SELECT COALESCE(workspace_members.role, '') AS role,
+       workspaces.require_two_factor,
+       EXISTS(SELECT 1
+              FROM user_totp
+              WHERE user_totp.user_id = $2
+                AND user_totp.confirmed_at IS NOT NULL) AS two_factor
+FROM shortens
+         JOIN workspaces ON workspaces.id = shortens.workspace_id
+         LEFT JOIN workspace_members ON workspace_members.workspace_id = shortens.workspace_id AND
+                                       workspace_members.user_id = $2
+WHERE shortens.id = $1
+`
+
+	var access model.Access
+	err := storage.client.Get(ctx, &access, q, shortenID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return access, apperror.NotFound.WithError(err)
+		}
+
+		return access, apperror.Internal.WithError(err)
+	}
+
+	return access, nil
+}
+
+func (storage *workspaceStorage) SelectMembers(ctx context.Context, workspaceID uuid.UUID) (model.Members, error) {
+	q := `
+SELECT workspace_members.workspace_id,
+       workspace_members.user_id,
+       users.name,
+       workspace_members.role,
+       workspace_members.created_at
+FROM workspace_members
+         JOIN users ON users.id = workspace_members.user_id
+WHERE workspace_members.workspace_id = $1
+ORDER BY workspace_members.created_at
+`
+
+	var members model.Members
+	err := storage.client.Select(ctx, &members, q, workspaceID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return members, apperror.Internal.WithError(err)
+	}
+
+	return members, nil
+}
+
+func (storage *workspaceStorage) UpdateMember(ctx context.Context, member model.Member) error {
+	q := `
+UPDATE
+    workspace_members
+SET
+    role = $3
+WHERE
+    workspace_id = $1 AND
+    user_id = $2
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		member.WorkspaceID,
+		member.UserID,
+		member.Role,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *workspaceStorage) DeleteMember(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	q := `
+DELETE FROM
+	workspace_members
+WHERE
+	workspace_id = $1 AND
+	user_id = $2
+`
+
+	_, err := storage.client.Exec(ctx, q, workspaceID, userID)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *workspaceStorage) CountOwners(ctx context.Context, workspaceID uuid.UUID) (int, error) {
+	q := `
+SELECT
+    COUNT(*)
+FROM
+    workspace_members
+WHERE
+    workspace_id = $1 AND
+    role = 'owner'
+`
+
+	var count int
+	err := storage.client.Get(ctx, &count, q, workspaceID)
+	if err != nil {
+		return count, apperror.Internal.WithError(err)
+	}
+
+	return count, nil
+}
+
+func (storage *workspaceStorage) CreateInvitation(ctx context.Context, invitation model.Invitation) error {
+	q := `
+INSERT INTO
+    workspace_invitations (id, workspace_id, token_hash, role, created_by, created_at, expires_at)
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7)
+`
+
+	_, err := storage.client.Exec(ctx, q,
+		invitation.ID,
+		invitation.WorkspaceID,
+		invitation.TokenHash,
+		invitation.Role,
+		invitation.CreatedBy,
+		invitation.CreatedAt,
+		invitation.ExpiresAt,
+	)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *workspaceStorage) SelectInvitations(ctx context.Context, workspaceID uuid.UUID) (model.Invitations, error) {
+	q := `
+SELECT id,
+       workspace_id,
+       token_hash,
+       role,
+       created_by,
+       created_at,
+       expires_at,
+       accepted_by,
+       accepted_at
+FROM workspace_invitations
+WHERE workspace_id = $1
+ORDER BY created_at DESC
+`
+
+	var invitations model.Invitations
+	err := storage.client.Select(ctx, &invitations, q, workspaceID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return invitations, apperror.Internal.WithError(err)
+	}
+
+	return invitations, nil
+}
+
+func (storage *workspaceStorage) DeleteInvitation(ctx context.Context, workspaceID, id uuid.UUID) (bool, error) {
+	q := `
+DELETE FROM
+	workspace_invitations
+WHERE
+	workspace_id = $1 AND
+	id = $2
+`
+
+	tag, err := storage.client.Exec(ctx, q, workspaceID, id)
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (storage *workspaceStorage) AcceptInvitation(ctx context.Context, tokenHash []byte, userID uuid.UUID, now time.Time) (model.Invitation, error) {
+	q := `
+WITH invitation AS (
+    UPDATE
+        workspace_invitations
+    SET
+        accepted_by = $2,
+        accepted_at = $3
+    WHERE
+        token_hash = $1 AND
+        accepted_at IS NULL AND
+        expires_at > $3
+    RETURNING id, workspace_id, token_hash, role, created_by, created_at, expires_at, accepted_by, accepted_at
+), member AS (
+    INSERT INTO
+        workspace_members (workspace_id, user_id, role, created_at)
+    SELECT
+        workspace_id, $2, role, $3
+    FROM
+        invitation
+    ON CONFLICT DO NOTHING
+)
+SELECT
+    id, workspace_id, token_hash, role, created_by, created_at, expires_at, accepted_by, accepted_at
+FROM
+    invitation
+`
+
+	var invitation model.Invitation
+	err := storage.client.Get(ctx, &invitation, q, tokenHash, userID, now)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return invitation, apperror.NotFound.WithError(err)
+		}
+
+		return invitation, apperror.Internal.WithError(err)
+	}
+
+	return invitation, nil
+}
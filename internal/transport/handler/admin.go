@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"cc/internal/dto"
+	"cc/internal/service"
+	"cc/pkg/base62"
+	"cc/pkg/ginutils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log"
+	"net/http"
+	"time"
+)
+
+type AdminHandler struct {
+	adminService service.AdminService
+	authService  service.AuthService
+	auditService service.AuditService
+	cache        service.RedirectCache
+}
+
+func NewAdminHandler(adminService service.AdminService, authService service.AuthService, auditService service.AuditService, cache service.RedirectCache) *AdminHandler {
+	return &AdminHandler{adminService: adminService, authService: authService, auditService: auditService, cache: cache}
+}
+
+func (handler *AdminHandler) Register(group *gin.RouterGroup) {
+	group.GET("/shortens", handler.SearchShortens)
+	group.POST("/shortens/:key/disable", handler.DisableShorten)
+	group.POST("/shortens/:key/enable", handler.EnableShorten)
+	group.POST("/users/:id/ban", handler.BanUser)
+	group.DELETE("/users/:id/ban", handler.UnbanUser)
+	group.PUT("/users/:id/role", handler.SetUserRole)
+	group.GET("/stats", handler.GetTotals)
+	group.GET("/not-found", handler.SelectMissedKeys)
+	group.GET("/audit", handler.SelectAudit)
+	group.GET("/audit/export", handler.ExportAudit)
+}
+
+func (handler *AdminHandler) SearchShortens(c *gin.Context) {
+	var request dto.SearchShortens
+	if err := c.BindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	shortens, err := handler.adminService.SearchShortens(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": shortens,
+	})
+}
+
+func (handler *AdminHandler) DisableShorten(c *gin.Context) {
+	var request dto.DisableShorten
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = handler.adminService.DisableShorten(c, shortenID, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	handler.cache.Invalidate(c, shortenID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *AdminHandler) EnableShorten(c *gin.Context) {
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = handler.adminService.EnableShorten(c, shortenID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	handler.cache.Invalidate(c, shortenID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *AdminHandler) BanUser(c *gin.Context) {
+	var request dto.BanUser
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	disabled, err := handler.adminService.BanUser(c,
+		ginutils.GetUUID(c, "user_id"),
+		userID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = handler.authService.RevokeSessions(c, userID, uuid.Nil)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	handler.cache.Invalidate(c, disabled...)
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": len(disabled),
+	})
+}
+
+func (handler *AdminHandler) UnbanUser(c *gin.Context) {
+	userID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = handler.adminService.UnbanUser(c, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *AdminHandler) SetUserRole(c *gin.Context) {
+	var request dto.SetUserRole
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = handler.adminService.SetUserRole(c,
+		ginutils.GetUUID(c, "user_id"),
+		userID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *AdminHandler) GetTotals(c *gin.Context) {
+	totals, err := handler.adminService.GetTotals(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": totals,
+	})
+}
+
+func (handler *AdminHandler) SelectMissedKeys(c *gin.Context) {
+	var request dto.SelectMissedKeys
+	if err := c.BindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	keys, err := handler.adminService.SelectMissedKeys(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": keys,
+	})
+}
+
+func (handler *AdminHandler) SelectAudit(c *gin.Context) {
+	var request dto.SelectAudit
+	if err := c.BindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	records, err := handler.auditService.Select(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": records,
+	})
+}
+
+// ExportAudit streams every matching record as JSON Lines. Once the first
+// record is written the status is sent, so later errors only cut the
+// download short.
+func (handler *AdminHandler) ExportAudit(c *gin.Context) {
+	var request dto.SelectAudit
+	if err := c.BindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// An export can take longer than the server's write timeout, which is
+	// meant for ordinary responses.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Println(err)
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+
+	if err := handler.auditService.Export(c, request, c.Writer); err != nil {
+		if c.Writer.Written() {
+			log.Println(err)
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		_ = c.Error(err)
+		return
+	}
+}
@@ -4,23 +4,39 @@ import (
 	"cc/internal/domain"
 	"cc/internal/dto"
 	service2 "cc/internal/service"
+	"cc/internal/transport/middleware"
+	"cc/pkg/apperror"
+	"cc/pkg/ginutils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"net/http"
+	"path"
 )
 
+const oidcBindingCookie = "oidc_binding"
+
 type AuthHandler struct {
-	authService service2.AuthService
-	userService service2.UserService
+	authService      service2.AuthService
+	userService      service2.UserService
+	guardService     service2.GuardService
+	ssoService       service2.SSOService
+	twoFactorService service2.TwoFactorService
 }
 
-func NewAuthHandler(authService service2.AuthService, userService service2.UserService) *AuthHandler {
-	return &AuthHandler{authService: authService, userService: userService}
+func NewAuthHandler(authService service2.AuthService, userService service2.UserService, guardService service2.GuardService, ssoService service2.SSOService, twoFactorService service2.TwoFactorService) *AuthHandler {
+	return &AuthHandler{authService: authService, userService: userService, guardService: guardService, ssoService: ssoService, twoFactorService: twoFactorService}
 }
 
 func (handler *AuthHandler) Register(group *gin.RouterGroup) {
 	group.POST("/signin", handler.SignIn)
 	group.POST("/signup", handler.SignUp)
 	group.POST("/refresh", handler.Refresh)
+	group.POST("/logout", handler.Logout)
+	group.POST("/2fa", handler.SignInTwoFactor)
+	group.GET("/oidc", handler.AuthorizeOIDC)
+	group.POST("/oidc/link", middleware.Auth(handler.authService), handler.LinkOIDC)
+	group.GET("/oidc/callback", handler.CallbackOIDC)
 }
 
 func (handler *AuthHandler) SignIn(c *gin.Context) {
@@ -35,9 +51,51 @@ func (handler *AuthHandler) SignIn(c *gin.Context) {
 		return
 	}
 
+	if err := handler.guardService.Check(c, c.ClientIP(), request.Name); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
 	user, err := handler.userService.SignIn(c,
 		request,
 	)
+	if err != nil {
+		if errors.Is(err, apperror.BadRequest) {
+			if err := handler.guardService.Fail(c, request.Name); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+
+		_ = c.Error(err)
+		return
+	}
+
+	handler.guardService.Succeed(c, request.Name)
+
+	handler.completeSignIn(c, user.ID)
+}
+
+func (handler *AuthHandler) SignUp(c *gin.Context) {
+	var request dto.Credentials
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := handler.guardService.Check(c, c.ClientIP(), ""); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := handler.userService.SignUp(c,
+		request,
+	)
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -47,6 +105,7 @@ func (handler *AuthHandler) SignIn(c *gin.Context) {
 	session, err = handler.authService.CreateSession(c,
 		user.ID,
 		c.ClientIP(),
+		c.Request.UserAgent(),
 	)
 	if err != nil {
 		_ = c.Error(err)
@@ -58,19 +117,125 @@ func (handler *AuthHandler) SignIn(c *gin.Context) {
 	})
 }
 
-func (handler *AuthHandler) SignUp(c *gin.Context) {
-	var request dto.Credentials
+func (handler *AuthHandler) Refresh(c *gin.Context) {
+	var request dto.Refresh
 	if err := c.BindJSON(&request); err != nil {
 		_ = c.Error(err)
 		return
 	}
 
+	session, err := handler.authService.UpdateSession(c,
+		request,
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": session,
+	})
+}
+
+func (handler *AuthHandler) Logout(c *gin.Context) {
+	var request dto.Refresh
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err := handler.authService.RevokeSession(c,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *AuthHandler) AuthorizeOIDC(c *gin.Context) {
+	url, binding, err := handler.ssoService.AuthCodeURL(c, uuid.Nil)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setOIDCBinding(c, binding, c.FullPath())
+
+	c.Redirect(http.StatusFound, url)
+}
+
+func (handler *AuthHandler) LinkOIDC(c *gin.Context) {
+	userID := ginutils.GetUUID(c, "user_id")
+
+	url, binding, err := handler.ssoService.AuthCodeURL(c, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setOIDCBinding(c, binding, path.Dir(c.FullPath()))
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": url,
+	})
+}
+
+func (handler *AuthHandler) CallbackOIDC(c *gin.Context) {
+	var request dto.OIDCCallback
+	if err := c.BindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
 	if err := request.Validate(); err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	user, err := handler.userService.SignUp(c,
+	if err := handler.guardService.Check(c, c.ClientIP(), ""); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	request.Binding, _ = c.Cookie(oidcBindingCookie)
+	setOIDCBinding(c, "", path.Dir(c.FullPath()))
+
+	user, err := handler.ssoService.SignIn(c,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	handler.completeSignIn(c, user.ID)
+}
+
+func (handler *AuthHandler) SignInTwoFactor(c *gin.Context) {
+	var request dto.TwoFactorSignIn
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := handler.guardService.Check(c, c.ClientIP(), ""); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID, err := handler.twoFactorService.VerifyChallenge(c,
 		request,
 	)
 	if err != nil {
@@ -80,8 +245,9 @@ func (handler *AuthHandler) SignUp(c *gin.Context) {
 
 	var session domain.Session
 	session, err = handler.authService.CreateSession(c,
-		user.ID,
+		userID,
 		c.ClientIP(),
+		c.Request.UserAgent(),
 	)
 	if err != nil {
 		_ = c.Error(err)
@@ -93,15 +259,34 @@ func (handler *AuthHandler) SignUp(c *gin.Context) {
 	})
 }
 
-func (handler *AuthHandler) Refresh(c *gin.Context) {
-	var request dto.Refresh
-	if err := c.BindJSON(&request); err != nil {
+// completeSignIn responds with a session, or with a challenge to be answered
+// at /2fa when the user has two-factor authentication enabled.
+func (handler *AuthHandler) completeSignIn(c *gin.Context, userID uuid.UUID) {
+	enabled, err := handler.twoFactorService.Enabled(c, userID)
+	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	session, err := handler.authService.UpdateSession(c,
-		request,
+	if enabled {
+		var challenge domain.Challenge
+		challenge, err = handler.twoFactorService.CreateChallenge(c, userID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"response": challenge,
+		})
+		return
+	}
+
+	var session domain.Session
+	session, err = handler.authService.CreateSession(c,
+		userID,
+		c.ClientIP(),
+		c.Request.UserAgent(),
 	)
 	if err != nil {
 		_ = c.Error(err)
@@ -112,3 +297,23 @@ func (handler *AuthHandler) Refresh(c *gin.Context) {
 		"response": session,
 	})
 }
+
+// setOIDCBinding keeps the binding of a single sign-on flow in the browser
+// that started it, an empty binding removes it. The cookie has to be Lax to be
+// sent on the redirect back from the identity provider.
+func setOIDCBinding(c *gin.Context, binding, cookiePath string) {
+	cookie := &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    binding,
+		Path:     cookiePath,
+		MaxAge:   int(service2.SSOStateExpiration.Seconds()),
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if binding == "" {
+		cookie.MaxAge = -1
+	}
+
+	http.SetCookie(c.Writer, cookie)
+}
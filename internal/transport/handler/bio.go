@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/service"
+	"cc/pkg/apperror"
+	"cc/pkg/ginutils"
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+type BioHandler struct {
+	bioService service.BioService
+}
+
+func NewBioHandler(bioService service.BioService) *BioHandler {
+	return &BioHandler{bioService: bioService}
+}
+
+func (handler *BioHandler) Register(group *gin.RouterGroup) {
+	group.GET("", handler.GetBioPage)
+	group.PUT("", handler.SaveBioPage)
+	group.DELETE("", handler.DeleteBioPage)
+	group.GET("/stats", handler.GetBioStats)
+}
+
+// RegisterPublic adds the page visitors see. Its links are served by the
+// redirect handler.
+func (handler *BioHandler) RegisterPublic(group *gin.RouterGroup) {
+	group.GET("/@:name", handler.ShowBioPage)
+}
+
+func (handler *BioHandler) GetBioPage(c *gin.Context) {
+	userID := ginutils.GetUUID(c, "user_id")
+
+	page, err := handler.bioService.Get(c, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": page,
+	})
+}
+
+func (handler *BioHandler) SaveBioPage(c *gin.Context) {
+	var request dto.SaveBioPage
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	page, err := handler.bioService.Save(c, userID, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": page,
+	})
+}
+
+func (handler *BioHandler) DeleteBioPage(c *gin.Context) {
+	userID := ginutils.GetUUID(c, "user_id")
+
+	err := handler.bioService.Delete(c, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": "ok",
+	})
+}
+
+func (handler *BioHandler) GetBioStats(c *gin.Context) {
+	var request dto.GetBioStats
+	if err := c.BindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	stats, err := handler.bioService.GetStats(c, userID, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": stats,
+	})
+}
+
+func (handler *BioHandler) ShowBioPage(c *gin.Context) {
+	page, err := handler.bioService.GetPublic(c, c.Param("name"))
+	if err != nil {
+		if _, ok := apperror.Is(err, apperror.NotFound); ok {
+			renderPage(c, http.StatusNotFound, pageNotFound)
+			return
+		}
+
+		log.Println(err)
+		c.Header("Retry-After", "30")
+		renderPage(c, http.StatusServiceUnavailable, pageUnavailable)
+		return
+	}
+
+	var buf bytes.Buffer
+
+	err = bioTemplate.Execute(&buf, page)
+	if err != nil {
+		log.Println(err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+var bioTemplate = template.Must(template.New("bio").Funcs(template.FuncMap{
+	"dark": func(theme domain.Theme) bool { return theme == domain.ThemeDark },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.Title}}</title>
+    <meta property="og:title" content="{{.Title}}">
+    {{- with .AvatarURL}}
+    <meta property="og:image" content="{{.}}">
+    {{- end}}
+    <style>
+        body { font-family: system-ui, sans-serif; margin: 0; min-height: 100vh; {{if dark .Theme}}background: #16181d; color: #eee;{{else}}background: #f6f7f9; color: #222;{{end}} }
+        main { max-width: 32rem; margin: 0 auto; padding: 3rem 1.5rem; text-align: center; }
+        h1 { font-size: 1.5rem; }
+        .avatar { width: 6rem; height: 6rem; border-radius: 50%; object-fit: cover; }
+        ul { list-style: none; padding: 0; }
+        li a { display: block; margin: .75rem 0; padding: 1rem; border-radius: .5rem; text-decoration: none; {{if dark .Theme}}background: #262a33; color: #eee;{{else}}background: #fff; color: #222; box-shadow: 0 1px 3px rgba(0, 0, 0, .1);{{end}} }
+    </style>
+</head>
+<body>
+<main>
+    {{- with .AvatarURL}}
+    <img class="avatar" src="{{.}}" alt="">
+    {{- end}}
+    <h1>{{.Title}}</h1>
+    <ul>
+        {{- range .Links}}
+        <li><a href="{{.URL}}" rel="noopener">{{or .Title .Key}}</a></li>
+        {{- end}}
+    </ul>
+</main>
+</body>
+</html>
+`))
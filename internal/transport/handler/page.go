@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"bytes"
+	"cc/internal/domain"
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <meta name="robots" content="noindex">
+    <title>{{.Title}}</title>
+    <style>
+        body { font-family: system-ui, sans-serif; display: flex; min-height: 100vh; margin: 0; align-items: center; justify-content: center; background: #f6f7f9; color: #222; }
+        main { max-width: 32rem; padding: 2rem; text-align: center; }
+        h1 { font-size: 1.5rem; }
+        a { color: {{or .Branding.Color "#1a73e8"}}; }
+        .logo { max-height: 3rem; max-width: 12rem; margin-bottom: 1rem; }
+        footer { margin-top: 2rem; font-size: .875rem; color: #666; }
+    </style>
+</head>
+<body>
+<main>
+    {{- with .Branding.LogoURL}}
+    <img class="logo" src="{{.}}" alt="{{$.Branding.Name}}">
+    {{- end}}
+    <h1>{{.Title}}</h1>
+    <p>{{.Message}}</p>
+    {{- if .Link}}
+    <p><a href="{{.Link}}" rel="noopener noreferrer nofollow">{{.LinkText}}</a></p>
+    {{- end}}
+    {{- if .Branding.HomeURL}}
+    <footer><a href="{{.Branding.HomeURL}}">{{or .Branding.Name .Branding.HomeURL}}</a></footer>
+    {{- else if .Branding.Name}}
+    <footer>{{.Branding.Name}}</footer>
+    {{- end}}
+</main>
+</body>
+</html>
+`))
+
+type page struct {
+	Title    string
+	Message  string
+	Link     string
+	LinkText string
+	Branding domain.Branding
+}
+
+// renderPage writes a minimal HTML page for visitors of a short link.
+func renderPage(c *gin.Context, status int, p page) {
+	var buf bytes.Buffer
+
+	err := pageTemplate.Execute(&buf, p)
+	if err != nil {
+		log.Println(err)
+		c.AbortWithStatus(status)
+		return
+	}
+
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+var cardTemplate = template.Must(template.New("card").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="robots" content="noindex">
+    <meta http-equiv="refresh" content="0; url={{.URL}}">
+    <link rel="canonical" href="{{.URL}}">
+    <title>{{.Title}}</title>
+    <meta property="og:type" content="website">
+    {{- with .Title}}
+    <meta property="og:title" content="{{.}}">
+    <meta name="twitter:title" content="{{.}}">
+    {{- end}}
+    {{- with .Description}}
+    <meta name="description" content="{{.}}">
+    <meta property="og:description" content="{{.}}">
+    <meta name="twitter:description" content="{{.}}">
+    {{- end}}
+    {{- with .Image}}
+    <meta property="og:image" content="{{.}}">
+    <meta name="twitter:image" content="{{.}}">
+    <meta name="twitter:card" content="summary_large_image">
+    {{- else}}
+    <meta name="twitter:card" content="summary">
+    {{- end}}
+</head>
+<body>
+<a href="{{.URL}}">{{.URL}}</a>
+</body>
+</html>
+`))
+
+type card struct {
+	URL         string
+	Title       string
+	Description string
+	Image       string
+}
+
+// renderCard writes the page served to link preview bots in place of the
+// redirect, so the shared card shows the link's own Open Graph tags.
+func renderCard(c *gin.Context, cd card) {
+	var buf bytes.Buffer
+
+	err := cardTemplate.Execute(&buf, cd)
+	if err != nil {
+		log.Println(err)
+		c.Redirect(http.StatusSeeOther, cd.URL)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+var cloakTemplate = template.Must(template.New("cloak").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <meta name="robots" content="noindex">
+    <title>{{.Title}}</title>
+    <style>
+        html, body, iframe { width: 100%; height: 100%; margin: 0; border: 0; overflow: hidden; }
+    </style>
+</head>
+<body>
+<iframe src="{{.URL}}" title="{{.Title}}" allowfullscreen></iframe>
+<noscript><a href="{{.URL}}">{{.URL}}</a></noscript>
+</body>
+</html>
+`))
+
+var refreshTemplate = template.Must(template.New("refresh").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="robots" content="noindex">
+    <meta http-equiv="refresh" content="1; url={{.URL}}">
+    <title>{{.Title}}</title>
+</head>
+<body>
+{{- with .Pixel}}
+<noscript><img src="{{.}}" width="1" height="1" alt=""></noscript>
+{{- end}}
+<a href="{{.URL}}">{{.URL}}</a>
+<script>
+    (function () {
+        var done = false;
+        var go = function () {
+            if (!done) {
+                done = true;
+                location.replace({{.URL}});
+            }
+        };
+        {{- if .Pixel}}
+        var pixel = new Image();
+        pixel.onload = pixel.onerror = go;
+        pixel.src = {{.Pixel}};
+        setTimeout(go, 500);
+        {{- else}}
+        go();
+        {{- end}}
+    })();
+</script>
+</body>
+</html>
+`))
+
+type destination struct {
+	URL   string
+	Title string
+	Pixel string
+}
+
+// renderDestination serves a client-side redirect: the destination in a frame
+// for cloaked links, or a page that fires the pixel before navigating.
+func renderDestination(c *gin.Context, tmpl *template.Template, d destination) {
+	var buf bytes.Buffer
+
+	err := tmpl.Execute(&buf, d)
+	if err != nil {
+		log.Println(err)
+		c.Redirect(http.StatusFound, d.URL)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+var appTemplate = template.Must(template.New("app").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <meta name="robots" content="noindex">
+    <title>{{or .Title "Opening the app"}}</title>
+    <style>
+        body { font-family: system-ui, sans-serif; display: flex; min-height: 100vh; margin: 0; align-items: center; justify-content: center; background: #f6f7f9; color: #222; }
+        main { max-width: 32rem; padding: 2rem; text-align: center; }
+        a { color: #1a73e8; }
+    </style>
+</head>
+<body>
+<main>
+    <p><a href="{{.App}}">Open in the app</a></p>
+    <p><a href="{{.Fallback}}" rel="noopener">Continue without the app</a></p>
+</main>
+<script>
+    (function () {
+        var done = false;
+        document.addEventListener("visibilitychange", function () {
+            if (document.hidden && !done) {
+                done = true;
+                fetch({{.Opened}}, {keepalive: true});
+            }
+        });
+        setTimeout(function () {
+            if (!done) {
+                done = true;
+                location.replace({{.Fallback}});
+            }
+        }, 1500);
+        location.href = {{.App}};
+    })();
+</script>
+</body>
+</html>
+`))
+
+type appLink struct {
+	Title    string
+	App      template.URL
+	Fallback string
+	Opened   string
+}
+
+// renderAppLink serves the page that tries to open the app and otherwise
+// moves on to the fallback. Whichever happens calls back to record the click.
+func renderAppLink(c *gin.Context, a appLink) {
+	var buf bytes.Buffer
+
+	err := appTemplate.Execute(&buf, a)
+	if err != nil {
+		log.Println(err)
+		c.Redirect(http.StatusFound, a.Fallback)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"cc/pkg/probe"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type ProbeHandler struct {
+	checks  []probe.Check
+	timeout time.Duration
+}
+
+func NewProbeHandler(timeout time.Duration, checks ...probe.Check) *ProbeHandler {
+	return &ProbeHandler{checks: checks, timeout: timeout}
+}
+
+func (handler *ProbeHandler) Register(group *gin.RouterGroup) {
+	group.GET("/healthz", handler.Healthz)
+	group.GET("/readyz", handler.Readyz)
+}
+
+// Healthz answers as long as the process serves requests; the report is only
+// informational.
+func (handler *ProbeHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, probe.Run(c, handler.timeout, handler.checks...))
+}
+
+// Readyz fails while a required dependency is down, so load balancers stop
+// sending traffic to the instance.
+func (handler *ProbeHandler) Readyz(c *gin.Context) {
+	report := probe.Run(c, handler.timeout, handler.checks...)
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
@@ -1,85 +1,395 @@
 package handler
 
 import (
+	"cc/internal/config"
+	"cc/internal/domain"
+	"cc/internal/dto"
 	"cc/internal/service"
 	"cc/pkg/apperror"
 	"cc/pkg/base62"
+	"cc/pkg/geo"
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v9"
-	"github.com/pkg/errors"
+	"github.com/google/uuid"
+	"html/template"
 	"log"
+	"math/rand"
 	"net/http"
+	neturl "net/url"
+	"strings"
 	"time"
 )
 
+var (
+	pageNotFound = page{
+		Title:   "Link not found",
+		Message: "There is no link at this address. Check it for typos, or ask whoever shared it for a new one.",
+	}
+	pageUnavailable = page{
+		Title:   "Temporarily unavailable",
+		Message: "We could not look this link up right now. Please try again in a moment.",
+	}
+)
+
+// The app link page calls back with these query parameters to record how the
+// link was opened, along with the referer of the original request.
+const (
+	openQuery    = "dl"
+	refererQuery = "ref"
+)
+
+const (
+	variantCookie    = "variant"
+	variantCookieAge = 90 * 24 * time.Hour
+)
+
 type RedirectHandler struct {
-	shortenService service.ShortenService
-	statsService   service.StatsService
-	cache          *redis.Client
-	defaultURL     string
+	shortenService   service.ShortenService
+	statsService     service.StatsService
+	notFoundService  service.NotFoundService
+	bioService       service.BioService
+	geo              geo.Resolver
+	deepLink         config.DeepLink
+	domainURL        string
+	defaultURL       string
+	redirectNotFound bool
 }
 
 func NewRedirectHandler(
 	shortenService service.ShortenService,
 	statsService service.StatsService,
-	cache *redis.Client,
+	notFoundService service.NotFoundService,
+	bioService service.BioService,
+	geo geo.Resolver,
+	deepLink config.DeepLink,
+	domainURL string,
 	defaultURL string,
+	redirectNotFound bool,
 ) *RedirectHandler {
 	return &RedirectHandler{
-		shortenService: shortenService,
-		statsService:   statsService,
-		cache:          cache,
-		defaultURL:     defaultURL,
+		shortenService:   shortenService,
+		statsService:     statsService,
+		notFoundService:  notFoundService,
+		bioService:       bioService,
+		geo:              geo,
+		deepLink:         deepLink,
+		domainURL:        domainURL,
+		defaultURL:       defaultURL,
+		redirectNotFound: redirectNotFound,
 	}
 }
 
 func (handler *RedirectHandler) Register(group *gin.RouterGroup) {
 	group.GET("/:key", handler.Redirect)
+	group.GET("/@:name/:key", handler.RedirectFromBio)
+	group.GET("/.well-known/apple-app-site-association", handler.AppleAppSiteAssociation)
+	group.GET("/apple-app-site-association", handler.AppleAppSiteAssociation)
+	group.GET("/.well-known/assetlinks.json", handler.AssetLinks)
 }
 
 func (handler *RedirectHandler) Redirect(c *gin.Context) {
 	shortenKey := c.Param("key")
 	shortenID, err := base62.Decode(shortenKey)
 	if err != nil {
-		log.Println(err)
-		c.Redirect(http.StatusSeeOther, handler.defaultURL)
+		handler.notFound(c, shortenKey)
 		return
 	}
 
-	var url string
-	url, err = handler.cache.Get(c, "shorten:"+shortenKey).Result()
-	if err != nil && errors.Is(err, redis.Nil) == false {
+	handler.redirect(c, shortenKey, shortenID, nil)
+}
+
+// RedirectFromBio follows a link listed on a bio page and attributes the
+// click to the page. Links that are not on the page are not found.
+func (handler *RedirectHandler) RedirectFromBio(c *gin.Context) {
+	shortenKey := c.Param("key")
+
+	pageID, shortenID, err := handler.bioService.GetLink(c, c.Param("name"), shortenKey)
+	if err != nil {
+		if _, ok := apperror.Is(err, apperror.NotFound); ok {
+			renderPage(c, http.StatusNotFound, pageNotFound)
+			return
+		}
+
 		log.Println(err)
-		c.Redirect(http.StatusSeeOther, handler.defaultURL)
+		c.Header("Retry-After", "30")
+		renderPage(c, http.StatusServiceUnavailable, pageUnavailable)
 		return
 	}
 
-	if url == "" {
-		url, err = handler.shortenService.GetURL(c, shortenID)
-		if err != nil {
-			if _, ok := apperror.Is(err, apperror.NotFound); ok {
-				c.Redirect(http.StatusSeeOther, handler.defaultURL)
-				return
-			}
+	handler.redirect(c, shortenKey, shortenID, &pageID)
+}
 
-			c.AbortWithStatus(http.StatusNotFound)
+func (handler *RedirectHandler) redirect(c *gin.Context, shortenKey string, shortenID uint64, bioPageID *uuid.UUID) {
+	redirect, err := handler.shortenService.GetRedirect(c, shortenID)
+	if err != nil {
+		if _, ok := apperror.Is(err, apperror.NotFound); ok {
+			handler.notFound(c, shortenKey)
 			return
 		}
 
-		handler.cache.Set(c, "shorten:"+shortenKey, url, 1*time.Hour)
+		log.Println(err)
+		c.Header("Retry-After", "30")
+		renderPage(c, http.StatusServiceUnavailable, pageUnavailable)
+		return
+	}
+
+	// Moderation pages never carry the account's branding, so a warning
+	// cannot be dressed up as the destination.
+	if redirect.Disabled {
+		renderPage(c, http.StatusGone, page{
+			Title:   "Link disabled",
+			Message: "This link has been disabled for violating our terms of service.",
+		})
+		return
 	}
 
 	now := time.Now()
-	err = handler.statsService.CreateClickByUserAgent(c,
-		now,
-		shortenID,
-		c.Request.Header.Get("User-Agent"),
-		c.Request.Referer(),
-		c.ClientIP(),
-	)
+
+	if redirect.Expired(now) {
+		renderPage(c, http.StatusGone, page{
+			Title:    "Link expired",
+			Message:  "This link is no longer active.",
+			Branding: redirect.Branding,
+		})
+		return
+	}
+
+	// Continuing from the warning goes straight to the destination, so a
+	// crafted short URL cannot skip it and the click is not recorded.
+	if redirect.Quarantined {
+		renderPage(c, http.StatusOK, page{
+			Title:    "Suspicious link",
+			Message:  "This link leads to " + redirect.URL + ", which has been reported as unsafe. It may try to steal your information or install harmful software.",
+			Link:     redirect.URL,
+			LinkText: "Continue anyway",
+		})
+		return
+	}
+
+	userAgent := c.Request.Header.Get("User-Agent")
+
+	var country string
+	if redirect.Rules.NeedsCountry() {
+		country = handler.geo.Country(c.Request, c.ClientIP())
+	}
+
+	visitor := service.NewVisitor(userAgent, country, now)
+
+	// Preview bots are not counted as clicks, so nothing is recorded here.
+	if !redirect.OpenGraph.Empty() && visitor.Bot {
+		renderCard(c, card{
+			URL:         redirect.URL,
+			Title:       redirect.OpenGraph.Title,
+			Description: redirect.OpenGraph.Description,
+			Image:       redirect.OpenGraph.Image,
+		})
+		return
+	}
+
+	url, rule := redirect.Resolve(visitor)
+
+	var variant string
+	if rule == "" && len(redirect.Variants) > 0 {
+		picked := handler.pickVariant(c, redirect.Variants)
+		url, variant = picked.URL, picked.Name
+	}
+
+	referer, marker := c.Request.Referer(), c.Request.URL.RawQuery
+
+	// Deep links are resolved in two steps: the app link page first, then
+	// its call back, which is the request that is recorded.
+	var open string
+	if target := redirect.DeepLink.Target(visitor.OS); target != "" && !visitor.Bot {
+		query := c.Request.URL.Query()
+		open = query.Get(openQuery)
+		if open != domain.OpenApp && open != domain.OpenFallback {
+			handler.renderAppLink(c, redirect, target, visitor.OS)
+			return
+		}
+
+		referer = query.Get(refererQuery)
+		query.Del(openQuery)
+		query.Del(refererQuery)
+		marker = query.Encode()
+	}
+
+	err = handler.statsService.CreateClickByUserAgent(c, dto.Visit{
+		ShortenID: shortenID,
+		Timestamp: now,
+		UserAgent: userAgent,
+		Referer:   referer,
+		IP:        c.ClientIP(),
+		Marker:    marker,
+		Rule:      rule,
+		Variant:   variant,
+		Open:      open,
+		BioPageID: bioPageID,
+	})
 	if err != nil {
 		log.Println(err)
 	}
 
-	c.Redirect(http.StatusSeeOther, url)
+	if open == domain.OpenApp {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	if store := handler.storeURL(redirect.DeepLink, visitor.OS); open == domain.OpenFallback && store != "" {
+		c.Redirect(http.StatusFound, store)
+		return
+	}
+
+	switch redirect.Type {
+	case domain.RedirectCloaked:
+		renderDestination(c, cloakTemplate, destination{URL: absoluteURL(url), Title: redirect.Title})
+	case domain.RedirectRefresh:
+		renderDestination(c, refreshTemplate, destination{URL: absoluteURL(url), Title: redirect.Title, Pixel: redirect.PixelURL})
+	default:
+		c.Redirect(redirect.Type.Status(), url)
+	}
+}
+
+// absoluteURL adds a scheme to destinations stored without one, which
+// browsers would otherwise resolve against the short link.
+func absoluteURL(link string) string {
+	if strings.Contains(link, "://") {
+		return link
+	}
+
+	return "http://" + link
+}
+
+// notFound records the miss and answers with the not found page, or with the
+// old redirect to the default URL when configured.
+func (handler *RedirectHandler) notFound(c *gin.Context, shortenKey string) {
+	handler.notFoundService.Record(dto.NotFoundHit{
+		Key:       shortenKey,
+		Timestamp: time.Now(),
+		UserAgent: c.Request.Header.Get("User-Agent"),
+		Referer:   c.Request.Referer(),
+	})
+
+	if handler.redirectNotFound {
+		c.Redirect(http.StatusSeeOther, handler.defaultURL)
+		return
+	}
+
+	renderPage(c, http.StatusNotFound, pageNotFound)
+}
+
+// pickVariant keeps returning visitors on the variant they were first shown,
+// as long as it is still part of the test, so results are not diluted.
+func (handler *RedirectHandler) pickVariant(c *gin.Context, variants domain.Variants) domain.Variant {
+	// gin unescapes cookie values read with c.Cookie.
+	if name, err := c.Cookie(variantCookie); err == nil {
+		if variant, ok := variants.Get(name); ok {
+			return variant
+		}
+	}
+
+	variant := variants.Pick(rand.Intn(variants.TotalWeight()))
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     variantCookie,
+		Value:    neturl.QueryEscape(variant.Name),
+		Path:     c.Request.URL.Path,
+		MaxAge:   int(variantCookieAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return variant
+}
+
+// renderAppLink serves the page that opens target in the app. Its call backs
+// go to the same short link, so rules and variants resolve the same way.
+func (handler *RedirectHandler) renderAppLink(c *gin.Context, redirect domain.Redirect, target, os string) {
+	query := c.Request.URL.Query()
+	query.Set(refererQuery, c.Request.Referer())
+
+	query.Set(openQuery, domain.OpenFallback)
+	fallback := handler.domainURL + c.Request.URL.Path + "?" + query.Encode()
+
+	query.Set(openQuery, domain.OpenApp)
+	opened := handler.domainURL + c.Request.URL.Path + "?" + query.Encode()
+
+	app := target
+	if os == "Android" {
+		app = domain.Intent(target, handler.deepLink.AndroidPackage, fallback)
+	}
+
+	renderAppLink(c, appLink{
+		Title:    redirect.Title,
+		App:      template.URL(app),
+		Fallback: fallback,
+		Opened:   opened,
+	})
+}
+
+// storeURL is where visitors without the app go when the link falls back to
+// the store, or empty when it falls back to the web.
+func (handler *RedirectHandler) storeURL(deepLink domain.DeepLink, os string) string {
+	if deepLink.Fallback != domain.FallbackStore {
+		return ""
+	}
+
+	switch os {
+	case "iOS":
+		return handler.deepLink.AppleStoreURL
+	case "Android":
+		return handler.deepLink.AndroidStoreURL
+	default:
+		return ""
+	}
+}
+
+// appleAppPaths claims single-segment paths, i.e. short links, and leaves bio
+// pages, the API and probes to the browser. The file cannot tell which links
+// have deep links, so the app has to hand links it does not know back to
+// Safari.
+var appleAppPaths = []string{
+	"NOT /api/*",
+	"NOT /.well-known/*",
+	"NOT /@*",
+	"NOT /*/*",
+	"NOT /healthz",
+	"NOT /readyz",
+	"/*",
+}
+
+// AppleAppSiteAssociation lets iOS open short links in the configured app
+// when it is installed.
+func (handler *RedirectHandler) AppleAppSiteAssociation(c *gin.Context) {
+	if handler.deepLink.AppleAppID == "" {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, gin.H{
+		"applinks": gin.H{
+			"apps": []string{},
+			"details": []gin.H{{
+				"appID": handler.deepLink.AppleAppID,
+				"paths": appleAppPaths,
+			}},
+		},
+	})
+}
+
+// AssetLinks verifies the configured Android app for short links.
+func (handler *RedirectHandler) AssetLinks(c *gin.Context) {
+	if handler.deepLink.AndroidPackage == "" {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, []gin.H{{
+		"relation": []string{"delegate_permission/common.handle_all_urls"},
+		"target": gin.H{
+			"namespace":                "android_app",
+			"package_name":             handler.deepLink.AndroidPackage,
+			"sha256_cert_fingerprints": handler.deepLink.AndroidFingerprints,
+		},
+	}})
 }
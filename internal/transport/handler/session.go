@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"cc/internal/service"
+	"cc/pkg/ginutils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type SessionHandler struct {
+	authService service.AuthService
+}
+
+func NewSessionHandler(authService service.AuthService) *SessionHandler {
+	return &SessionHandler{authService: authService}
+}
+
+func (handler *SessionHandler) Register(group *gin.RouterGroup) {
+	group.GET("", handler.SelectDevices)
+	group.DELETE("", handler.RevokeSessions)
+	group.DELETE("/:id", handler.RevokeSession)
+}
+
+func (handler *SessionHandler) SelectDevices(c *gin.Context) {
+	userID := ginutils.GetUUID(c, "user_id")
+	sessionID := ginutils.GetUUID(c, "session_id")
+
+	devices, err := handler.authService.SelectDevices(c,
+		userID,
+		sessionID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": devices,
+	})
+}
+
+func (handler *SessionHandler) RevokeSessions(c *gin.Context) {
+	userID := ginutils.GetUUID(c, "user_id")
+
+	err := handler.authService.RevokeSessions(c,
+		userID,
+		uuid.Nil,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *SessionHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	err = handler.authService.RevokeSessionByID(c,
+		userID,
+		sessionID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
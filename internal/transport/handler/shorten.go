@@ -11,10 +11,13 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 type ShortenHandler struct {
 	shortenService service.ShortenService
+	previewService service.PreviewService
+	qrService      service.QRService
 	authService    service.AuthService
 	tagService     service.TagService
 	statsService   service.StatsService
@@ -22,12 +25,16 @@ type ShortenHandler struct {
 
 func NewShortenHandler(
 	shortenService service.ShortenService,
+	previewService service.PreviewService,
+	qrService service.QRService,
 	authService service.AuthService,
 	tagService service.TagService,
 	statsService service.StatsService,
 ) *ShortenHandler {
 	return &ShortenHandler{
 		shortenService: shortenService,
+		previewService: previewService,
+		qrService:      qrService,
 		authService:    authService,
 		tagService:     tagService,
 		statsService:   statsService,
@@ -41,6 +48,13 @@ func (handler *ShortenHandler) Register(group *gin.RouterGroup) {
 	group.GET("/:key", handler.GetShorten)
 	group.PATCH("/:key", handler.UpdateShorten)
 	group.DELETE("/:key", handler.DeleteShorten)
+	group.POST("/:key/restore", handler.RestoreShorten)
+	group.POST("/:key/preview", handler.RefreshPreview)
+	group.GET("/:key/qr", handler.GetQR)
+	group.PUT("/:key/rules", handler.SetRules)
+	group.PUT("/:key/variants", handler.SetVariants)
+	group.GET("/:key/history", handler.GetHistory)
+	group.POST("/:key/history/:revision_id/rollback", handler.Rollback)
 }
 
 func (handler *ShortenHandler) GetShorten(c *gin.Context) {
@@ -51,8 +65,10 @@ func (handler *ShortenHandler) GetShorten(c *gin.Context) {
 		return
 	}
 
+	userID := ginutils.GetUUID(c, "user_id")
+
 	var shorten domain.Shorten
-	shorten, err = handler.shortenService.GetByID(c, shortenID)
+	shorten, err = handler.shortenService.GetByID(c, userID, shortenID)
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -158,6 +174,204 @@ func (handler *ShortenHandler) DeleteShorten(c *gin.Context) {
 	})
 }
 
+func (handler *ShortenHandler) RefreshPreview(c *gin.Context) {
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var shorten domain.Shorten
+	shorten, err = handler.previewService.Refresh(c,
+		userID,
+		shortenID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": shorten,
+	})
+}
+
+func (handler *ShortenHandler) SetRules(c *gin.Context) {
+	var request dto.SetRules
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var shorten domain.Shorten
+	shorten, err = handler.shortenService.SetRules(c,
+		userID,
+		shortenID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": shorten,
+	})
+}
+
+func (handler *ShortenHandler) SetVariants(c *gin.Context) {
+	var request dto.SetVariants
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var shorten domain.Shorten
+	shorten, err = handler.shortenService.SetVariants(c,
+		userID,
+		shortenID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": shorten,
+	})
+}
+
+func (handler *ShortenHandler) RestoreShorten(c *gin.Context) {
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var shorten domain.Shorten
+	shorten, err = handler.shortenService.Restore(c, userID, shortenID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": shorten,
+	})
+}
+
+func (handler *ShortenHandler) GetHistory(c *gin.Context) {
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var revisions domain.Revisions
+	revisions, err = handler.shortenService.History(c, userID, shortenID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": revisions,
+	})
+}
+
+func (handler *ShortenHandler) Rollback(c *gin.Context) {
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	revisionID, err := strconv.ParseInt(c.Param("revision_id"), 10, 64)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var shorten domain.Shorten
+	shorten, err = handler.shortenService.Rollback(c, userID, shortenID, revisionID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": shorten,
+	})
+}
+
+func (handler *ShortenHandler) GetQR(c *gin.Context) {
+	var request dto.GetQR
+	if err := c.BindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	shortenID, err := base62.Decode(c.Param("key"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	data, contentType, err := handler.qrService.Generate(c,
+		userID,
+		shortenID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
+
 func (handler *ShortenHandler) GetShortenStats(c *gin.Context) {
 	var request dto.GetShortenStats
 	if err := c.BindQuery(&request); err != nil {
@@ -177,8 +391,11 @@ func (handler *ShortenHandler) GetShortenStats(c *gin.Context) {
 		return
 	}
 
+	userID := ginutils.GetUUID(c, "user_id")
+
 	var stats domain.Stats
 	stats, err = handler.statsService.GetStats(c,
+		userID,
 		shortenID,
 		request,
 	)
@@ -211,7 +428,9 @@ func (handler *ShortenHandler) ExportShortenStats(c *gin.Context) {
 		return
 	}
 
-	shorten, err := handler.shortenService.GetByID(c, shortenID)
+	userID := ginutils.GetUUID(c, "user_id")
+
+	shorten, err := handler.shortenService.GetByID(c, userID, shortenID)
 	if err != nil {
 		_ = c.Error(err)
 		return
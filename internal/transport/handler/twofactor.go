@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"cc/internal/dto"
+	"cc/internal/service"
+	"cc/pkg/ginutils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+func (handler *TwoFactorHandler) Register(group *gin.RouterGroup) {
+	group.GET("", handler.Status)
+	group.POST("", handler.Enroll)
+	group.POST("/confirm", handler.Confirm)
+	group.POST("/recovery-codes", handler.RegenerateRecoveryCodes)
+	group.DELETE("", handler.Disable)
+}
+
+func (handler *TwoFactorHandler) Status(c *gin.Context) {
+	userID := ginutils.GetUUID(c, "user_id")
+
+	status, err := handler.twoFactorService.Status(c, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": status,
+	})
+}
+
+func (handler *TwoFactorHandler) Enroll(c *gin.Context) {
+	userID := ginutils.GetUUID(c, "user_id")
+
+	enrollment, err := handler.twoFactorService.Enroll(c, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": enrollment,
+	})
+}
+
+func (handler *TwoFactorHandler) Confirm(c *gin.Context) {
+	var request dto.TwoFactorCode
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	codes, err := handler.twoFactorService.Confirm(c,
+		userID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": codes,
+	})
+}
+
+func (handler *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var request dto.TwoFactorCode
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	codes, err := handler.twoFactorService.RegenerateRecoveryCodes(c,
+		userID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": codes,
+	})
+}
+
+func (handler *TwoFactorHandler) Disable(c *gin.Context) {
+	var request dto.TwoFactorCode
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	err := handler.twoFactorService.Disable(c,
+		userID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
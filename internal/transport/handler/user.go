@@ -4,24 +4,32 @@ import (
 	"cc/internal/domain"
 	"cc/internal/dto"
 	"cc/internal/service"
+	"cc/pkg/apperror"
+	"cc/pkg/ginutils"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"io"
 	"net/http"
 )
 
 type UserHandler struct {
-	userService    service.UserService
-	authService    service.AuthService
-	shortenService service.ShortenService
-	tagService     service.TagService
+	userService      service.UserService
+	shortenService   service.ShortenService
+	tagService       service.TagService
+	workspaceService service.WorkspaceService
+	cache            service.RedirectCache
 }
 
-func NewUserHandler(userService service.UserService, authService service.AuthService, shortenService service.ShortenService, tagService service.TagService) *UserHandler {
-	return &UserHandler{userService: userService, authService: authService, shortenService: shortenService, tagService: tagService}
+func NewUserHandler(userService service.UserService, shortenService service.ShortenService, tagService service.TagService, workspaceService service.WorkspaceService, cache service.RedirectCache) *UserHandler {
+	return &UserHandler{userService: userService, shortenService: shortenService, tagService: tagService, workspaceService: workspaceService, cache: cache}
 }
 
 func (handler *UserHandler) Register(group *gin.RouterGroup) {
 	group.GET("/:id", handler.GetUser)
+	group.PATCH("/:id", handler.ChangeName)
+	group.PUT("/:id/password", handler.ChangePassword)
+	group.DELETE("/:id", handler.DeleteUser)
 	group.GET("/:id/shortens", handler.SelectUserShortens)
 	group.GET("/:id/tags", handler.SelectUserTags)
 }
@@ -54,14 +62,29 @@ func (handler *UserHandler) SelectUserShortens(c *gin.Context) {
 		return
 	}
 
-	userID, err := uuid.Parse(c.Param("id"))
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID, err := handler.self(c)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
 	var shortens []domain.Shorten
-	if len(request.Tags) > 0 {
+	if request.Health != "" {
+		shortens, err = handler.shortenService.SelectByHealth(c,
+			userID,
+			request.Health,
+			request.Tags,
+		)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+	} else if len(request.Tags) > 0 {
 		shortens, err = handler.shortenService.SelectByTags(c,
 			userID,
 			request.Tags,
@@ -86,7 +109,7 @@ func (handler *UserHandler) SelectUserShortens(c *gin.Context) {
 }
 
 func (handler *UserHandler) SelectUserTags(c *gin.Context) {
-	userID, err := uuid.Parse(c.Param("id"))
+	userID, err := handler.self(c)
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -103,3 +126,126 @@ func (handler *UserHandler) SelectUserTags(c *gin.Context) {
 		"response": tags,
 	})
 }
+
+func (handler *UserHandler) ChangeName(c *gin.Context) {
+	var request dto.ChangeName
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID, err := handler.self(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var user domain.User
+	user, err = handler.userService.ChangeName(c,
+		userID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": user,
+	})
+}
+
+func (handler *UserHandler) ChangePassword(c *gin.Context) {
+	var request dto.ChangePassword
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID, err := handler.self(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = handler.userService.ChangePassword(c,
+		userID,
+		ginutils.GetUUID(c, "session_id"),
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *UserHandler) DeleteUser(c *gin.Context) {
+	// Users signing in only with single sign-on have no password to send, so
+	// the body may be empty.
+	var request dto.DeleteUser
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		_ = c.Error(apperror.BadRequest.WithError(err).WithMessage("request is invalid"))
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID, err := handler.self(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = handler.workspaceService.EnsureCanLeave(c, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var shortenIDs []uint64
+	shortenIDs, err = handler.userService.Delete(c,
+		userID,
+		ginutils.GetUUID(c, "session_id"),
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	handler.cache.Invalidate(c, shortenIDs...)
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *UserHandler) self(c *gin.Context) (uuid.UUID, error) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return userID, apperror.BadRequest.WithError(err).WithMessage("id is invalid")
+	}
+
+	if userID != ginutils.GetUUID(c, "user_id") {
+		return userID, apperror.Forbidden.WithMessage("you can only manage your own account")
+	}
+
+	return userID, nil
+}
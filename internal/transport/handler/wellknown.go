@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"cc/internal/service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type WellKnownHandler struct {
+	authService service.AuthService
+}
+
+func NewWellKnownHandler(authService service.AuthService) *WellKnownHandler {
+	return &WellKnownHandler{authService: authService}
+}
+
+func (handler *WellKnownHandler) Register(group *gin.RouterGroup) {
+	group.GET("/jwks.json", handler.JWKS)
+}
+
+func (handler *WellKnownHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, handler.authService.JWKS())
+}
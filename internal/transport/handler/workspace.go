@@ -0,0 +1,460 @@
+package handler
+
+import (
+	"cc/internal/domain"
+	"cc/internal/dto"
+	"cc/internal/service"
+	"cc/pkg/apperror"
+	"cc/pkg/base62"
+	"cc/pkg/ginutils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type WorkspaceHandler struct {
+	workspaceService service.WorkspaceService
+	shortenService   service.ShortenService
+	cache            service.RedirectCache
+}
+
+func NewWorkspaceHandler(workspaceService service.WorkspaceService, shortenService service.ShortenService, cache service.RedirectCache) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService, shortenService: shortenService, cache: cache}
+}
+
+func (handler *WorkspaceHandler) Register(group *gin.RouterGroup) {
+	group.POST("", handler.CreateWorkspace)
+	group.GET("", handler.SelectWorkspaces)
+	group.POST("/join", handler.AcceptInvitation)
+	group.GET("/:id", handler.GetWorkspace)
+	group.PATCH("/:id", handler.UpdateWorkspace)
+	group.DELETE("/:id", handler.DeleteWorkspace)
+	group.GET("/:id/shortens", handler.SelectWorkspaceShortens)
+	group.GET("/:id/trash", handler.SelectTrash)
+	group.GET("/:id/members", handler.SelectMembers)
+	group.PATCH("/:id/members/:user_id", handler.UpdateMember)
+	group.DELETE("/:id/members/:user_id", handler.RemoveMember)
+	group.POST("/:id/invitations", handler.CreateInvitation)
+	group.GET("/:id/invitations", handler.SelectInvitations)
+	group.DELETE("/:id/invitations/:invitation_id", handler.DeleteInvitation)
+}
+
+func (handler *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var request dto.CreateWorkspace
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	workspace, err := handler.workspaceService.Create(c,
+		userID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": workspace,
+	})
+}
+
+func (handler *WorkspaceHandler) SelectWorkspaces(c *gin.Context) {
+	userID := ginutils.GetUUID(c, "user_id")
+
+	workspaces, err := handler.workspaceService.SelectByUser(c, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": workspaces,
+	})
+}
+
+func (handler *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var workspace domain.Workspace
+	workspace, err = handler.workspaceService.GetByID(c,
+		userID,
+		workspaceID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": workspace,
+	})
+}
+
+func (handler *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	var request dto.UpdateWorkspace
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var workspace domain.Workspace
+	workspace, err = handler.workspaceService.Update(c,
+		userID,
+		workspaceID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// Cached redirects carry the branding of their expired page.
+	if request.Branding != nil {
+		var shortens domain.Shortens
+		shortens, err = handler.shortenService.SelectByWorkspace(c, userID, workspaceID, nil)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		shortenIDs := make([]uint64, 0, len(shortens))
+		for _, shorten := range shortens {
+			if shortenID, err := base62.Decode(shorten.ID); err == nil {
+				shortenIDs = append(shortenIDs, shortenID)
+			}
+		}
+		handler.cache.Invalidate(c, shortenIDs...)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": workspace,
+	})
+}
+
+func (handler *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	shortenIDs, err := handler.workspaceService.Delete(c,
+		userID,
+		workspaceID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	handler.cache.Invalidate(c, shortenIDs...)
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *WorkspaceHandler) SelectWorkspaceShortens(c *gin.Context) {
+	var request dto.SelectShortens
+	if err := c.BindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var shortens domain.Shortens
+	shortens, err = handler.shortenService.SelectByWorkspace(c,
+		userID,
+		workspaceID,
+		request.Tags,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": shortens,
+	})
+}
+
+func (handler *WorkspaceHandler) SelectTrash(c *gin.Context) {
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var shortens domain.Shortens
+	shortens, err = handler.shortenService.Trash(c, userID, workspaceID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": shortens,
+	})
+}
+
+func (handler *WorkspaceHandler) SelectMembers(c *gin.Context) {
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var members domain.Members
+	members, err = handler.workspaceService.SelectMembers(c,
+		userID,
+		workspaceID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": members,
+	})
+}
+
+func (handler *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	var request dto.UpdateMember
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	memberID, err := paramUUID(c, "user_id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	err = handler.workspaceService.UpdateMember(c,
+		userID,
+		workspaceID,
+		memberID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	memberID, err := paramUUID(c, "user_id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	err = handler.workspaceService.RemoveMember(c,
+		userID,
+		workspaceID,
+		memberID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *WorkspaceHandler) CreateInvitation(c *gin.Context) {
+	var request dto.CreateInvitation
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var invitation domain.Invitation
+	invitation, err = handler.workspaceService.CreateInvitation(c,
+		userID,
+		workspaceID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": invitation,
+	})
+}
+
+func (handler *WorkspaceHandler) SelectInvitations(c *gin.Context) {
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	var invitations domain.Invitations
+	invitations, err = handler.workspaceService.SelectInvitations(c,
+		userID,
+		workspaceID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": invitations,
+	})
+}
+
+func (handler *WorkspaceHandler) DeleteInvitation(c *gin.Context) {
+	workspaceID, err := paramUUID(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	invitationID, err := paramUUID(c, "invitation_id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	err = handler.workspaceService.DeleteInvitation(c,
+		userID,
+		workspaceID,
+		invitationID,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": 1,
+	})
+}
+
+func (handler *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	var request dto.AcceptInvitation
+	if err := c.BindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userID := ginutils.GetUUID(c, "user_id")
+
+	workspace, err := handler.workspaceService.AcceptInvitation(c,
+		userID,
+		request,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": workspace,
+	})
+}
+
+func paramUUID(c *gin.Context, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return id, apperror.BadRequest.WithError(err).WithMessage(name + " is invalid")
+	}
+
+	return id, nil
+}
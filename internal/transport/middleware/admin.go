@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"cc/internal/service"
+	"cc/pkg/ginutils"
+	"github.com/gin-gonic/gin"
+)
+
+// Admin must run after Auth.
+func Admin(adminService service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := adminService.Authorize(c, ginutils.GetUUID(c, "user_id"))
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package middleware_test
+
+import (
+	"cc/internal/domain"
+	"cc/internal/model"
+	"cc/internal/service"
+	st "cc/internal/storage"
+	"cc/internal/transport/middleware"
+	"cc/pkg/apperror"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type userStorage struct {
+	st.UserStorage
+	users map[uuid.UUID]model.User
+}
+
+func (storage userStorage) GetByID(_ context.Context, id uuid.UUID) (model.User, error) {
+	usr, ok := storage.users[id]
+	if !ok {
+		return model.User{}, apperror.NotFound
+	}
+
+	return usr, nil
+}
+
+func TestAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bannedAt := time.Now()
+	admin := model.User{ID: uuid.New(), Role: string(domain.UserRoleAdmin)}
+	bannedAdmin := model.User{ID: uuid.New(), Role: string(domain.UserRoleAdmin), BannedAt: &bannedAt}
+	user := model.User{ID: uuid.New(), Role: string(domain.UserRoleUser)}
+
+	users := userStorage{users: map[uuid.UUID]model.User{admin.ID: admin, bannedAdmin.ID: bannedAdmin, user.ID: user}}
+	adminService := service.NewAdminService(users, nil, nil, nil, nil, nil, "")
+
+	tests := []struct {
+		name   string
+		userID any
+		status int
+	}{
+		{name: "admin", userID: admin.ID, status: http.StatusOK},
+		{name: "user", userID: user.ID, status: http.StatusForbidden},
+		{name: "banned admin", userID: bannedAdmin.ID, status: http.StatusForbidden},
+		{name: "unknown user", userID: uuid.New(), status: http.StatusForbidden},
+		{name: "anonymous", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+
+			router := gin.New()
+			router.Use(middleware.Error(), func(c *gin.Context) {
+				if tt.userID != nil {
+					c.Set("user_id", tt.userID)
+				}
+			})
+			router.GET("/admin", middleware.Admin(adminService), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.status == http.StatusOK, reached)
+		})
+	}
+}
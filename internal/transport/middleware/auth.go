@@ -42,7 +42,15 @@ func Auth(authService service.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		err = authService.Authenticate(c, claims.UserID, claims.SessionID)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
@@ -5,7 +5,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 func Error() gin.HandlerFunc {
@@ -26,6 +28,14 @@ func Error() gin.HandlerFunc {
 				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Err})
 			case errors.Is(err.Err, apperror.Unauthorized):
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Err})
+			case errors.Is(err.Err, apperror.Forbidden):
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Err})
+			case errors.Is(err.Err, apperror.TooManyRequests):
+				if apperr, ok := apperror.Is(err.Err, apperror.TooManyRequests); ok && apperr.RetryAfter > 0 {
+					c.Header("Retry-After", strconv.Itoa(int(math.Ceil(apperr.RetryAfter.Seconds()))))
+				}
+
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Err})
 			}
 		}
 	}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"cc/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Origin keeps where the request came from for the audit log.
+func Origin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(service.OriginKey, service.Origin{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+
+		c.Next()
+	}
+}
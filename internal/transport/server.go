@@ -46,12 +46,23 @@ func (server *Server) Handle(
 	shortenHandler *handler.ShortenHandler,
 	userHandler *handler.UserHandler,
 	authHandler *handler.AuthHandler,
+	sessionHandler *handler.SessionHandler,
+	workspaceHandler *handler.WorkspaceHandler,
+	twoFactorHandler *handler.TwoFactorHandler,
+	adminHandler *handler.AdminHandler,
+	bioHandler *handler.BioHandler,
 	redirectHandler *handler.RedirectHandler,
+	wellKnownHandler *handler.WellKnownHandler,
+	probeHandler *handler.ProbeHandler,
 	authService service.AuthService,
+	adminService service.AdminService,
 ) *Server {
+	probeHandler.Register(server.router.Group("/"))
 	redirectHandler.Register(server.router.Group("/"))
+	bioHandler.RegisterPublic(server.router.Group("/"))
+	wellKnownHandler.Register(server.router.Group("/.well-known"))
 
-	api := server.router.Group("/api", middleware.Error())
+	api := server.router.Group("/api", middleware.Error(), middleware.Origin())
 	{
 		authHandler.Register(api.Group("/auth"))
 
@@ -59,6 +70,11 @@ func (server *Server) Handle(
 		{
 			shortenHandler.Register(authorized.Group("/shortens"))
 			userHandler.Register(authorized.Group("/users"))
+			sessionHandler.Register(authorized.Group("/sessions"))
+			workspaceHandler.Register(authorized.Group("/workspaces"))
+			twoFactorHandler.Register(authorized.Group("/2fa"))
+			bioHandler.Register(authorized.Group("/bio"))
+			adminHandler.Register(authorized.Group("/admin", middleware.Admin(adminService)))
 		}
 
 	}
@@ -66,6 +82,6 @@ func (server *Server) Handle(
 	return server
 }
 
-func (server *Server) Run(addr string) error {
-	return server.router.Run(addr)
+func (server *Server) Handler() http.Handler {
+	return server.router
 }
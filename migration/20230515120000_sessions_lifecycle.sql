@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS user_agent TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ NOT NULL DEFAULT NOW() + INTERVAL '30 days',
+    ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_refresh_token_idx ON sessions (refresh_token);
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS session_refresh_tokens
+(
+    refresh_token UUID PRIMARY KEY,
+    session_id    UUID        NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    used_at       TIMESTAMPTZ NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS session_refresh_tokens CASCADE;
+
+DROP INDEX IF EXISTS sessions_user_id_idx;
+DROP INDEX IF EXISTS sessions_refresh_token_idx;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS revoked_at;
+-- +goose StatementEnd
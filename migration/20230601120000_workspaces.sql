@@ -0,0 +1,78 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS workspaces
+(
+    id         UUID PRIMARY KEY DEFAULT GEN_RANDOM_UUID(),
+    name       TEXT        NOT NULL,
+    personal   BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members
+(
+    workspace_id UUID        NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role         TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS workspace_members_user_id_idx ON workspace_members (user_id);
+
+CREATE TABLE IF NOT EXISTS workspace_invitations
+(
+    id           UUID PRIMARY KEY,
+    workspace_id UUID         NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    token_hash   BYTEA UNIQUE NOT NULL,
+    role         TEXT         NOT NULL,
+    created_by   UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ  NOT NULL,
+    expires_at   TIMESTAMPTZ  NOT NULL,
+    accepted_by  UUID REFERENCES users (id) ON DELETE SET NULL,
+    accepted_at  TIMESTAMPTZ
+);
+
+-- Every user gets a personal workspace sharing the user's id.
+INSERT INTO workspaces (id, name, personal, created_at, updated_at)
+SELECT id, name, TRUE, NOW(), NOW()
+FROM users
+ON CONFLICT DO NOTHING;
+
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+SELECT id, id, 'owner', NOW()
+FROM users
+ON CONFLICT DO NOTHING;
+
+-- Deleting a workspace moves its shortens to the trash and leaves them to the
+-- purge job, so the reference must not take them (and their clicks) along.
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces (id) ON DELETE SET NULL;
+
+UPDATE shortens
+SET workspace_id = user_id
+WHERE workspace_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS shortens_workspace_id_idx ON shortens (workspace_id);
+
+-- Shortens now belong to the workspace, the author leaving must not take them along.
+ALTER TABLE shortens
+    DROP CONSTRAINT IF EXISTS shortens_user_id_fkey,
+    ADD CONSTRAINT shortens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortens
+    DROP CONSTRAINT IF EXISTS shortens_user_id_fkey,
+    ADD CONSTRAINT shortens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS shortens_workspace_id_idx;
+
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_invitations CASCADE;
+DROP TABLE IF EXISTS workspace_members CASCADE;
+DROP TABLE IF EXISTS workspaces CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    issuer     TEXT        NOT NULL,
+    subject    TEXT        NOT NULL,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email      TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_identities CASCADE;
+-- +goose StatementEnd
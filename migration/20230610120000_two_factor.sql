@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_totp
+(
+    user_id      UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret       TEXT        NOT NULL,
+    last_counter BIGINT      NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL,
+    confirmed_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes
+(
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  BYTEA       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    PRIMARY KEY (user_id, code_hash)
+);
+
+ALTER TABLE workspaces
+    ADD COLUMN IF NOT EXISTS require_two_factor BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE workspaces
+    DROP COLUMN IF EXISTS require_two_factor;
+
+DROP TABLE IF EXISTS user_recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_totp CASCADE;
+-- +goose StatementEnd
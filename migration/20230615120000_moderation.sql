@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role       TEXT NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS banned_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS ban_reason TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS disabled_at     TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS disabled_reason TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS disabled_reason,
+    DROP COLUMN IF EXISTS disabled_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS ban_reason,
+    DROP COLUMN IF EXISTS banned_at,
+    DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS quarantined_at    TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS quarantine_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS screened_at       TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS shortens_screened_at_idx ON shortens (screened_at NULLS FIRST);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS shortens_screened_at_idx;
+
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS screened_at,
+    DROP COLUMN IF EXISTS quarantine_reason,
+    DROP COLUMN IF EXISTS quarantined_at;
+-- +goose StatementEnd
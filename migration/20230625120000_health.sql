@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS health_status      TEXT    NOT NULL DEFAULT 'unknown',
+    ADD COLUMN IF NOT EXISTS health_status_code INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS health_latency_ms  BIGINT  NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS health_final_url   TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS health_error       TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS health_failures    INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS health_checked_at  TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS shortens_health_checked_at_idx ON shortens (health_checked_at NULLS FIRST);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS shortens_health_checked_at_idx;
+
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS health_checked_at,
+    DROP COLUMN IF EXISTS health_failures,
+    DROP COLUMN IF EXISTS health_error,
+    DROP COLUMN IF EXISTS health_final_url,
+    DROP COLUMN IF EXISTS health_latency_ms,
+    DROP COLUMN IF EXISTS health_status_code,
+    DROP COLUMN IF EXISTS health_status;
+-- +goose StatementEnd
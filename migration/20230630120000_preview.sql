@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS preview_title       TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS preview_description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS preview_og_title    TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS preview_og_image    TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS preview_favicon     TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS preview_error       TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS preview_fetched_at  TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS preview_fetched_at,
+    DROP COLUMN IF EXISTS preview_error,
+    DROP COLUMN IF EXISTS preview_favicon,
+    DROP COLUMN IF EXISTS preview_og_image,
+    DROP COLUMN IF EXISTS preview_og_title,
+    DROP COLUMN IF EXISTS preview_description,
+    DROP COLUMN IF EXISTS preview_title;
+-- +goose StatementEnd
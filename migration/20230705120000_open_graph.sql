@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS og_title       TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS og_description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS og_image       TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS og_image,
+    DROP COLUMN IF EXISTS og_description,
+    DROP COLUMN IF EXISTS og_title;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE clicks
+    ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'Direct';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE clicks
+    DROP COLUMN IF EXISTS source;
+-- +goose StatementEnd
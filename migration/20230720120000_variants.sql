@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE clicks
+    ADD COLUMN IF NOT EXISTS variant TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE clicks
+    DROP COLUMN IF EXISTS variant;
+
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS variants;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS redirect_type TEXT NOT NULL DEFAULT '302',
+    ADD COLUMN IF NOT EXISTS pixel_url     TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS pixel_url,
+    DROP COLUMN IF EXISTS redirect_type;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE workspaces
+    ADD COLUMN IF NOT EXISTS branding JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS not_found_counts
+(
+    key          TEXT        NOT NULL,
+    referer      TEXT        NOT NULL,
+    day          DATE        NOT NULL,
+    hits         BIGINT      NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, referer, day)
+);
+
+CREATE INDEX IF NOT EXISTS not_found_counts_day_idx ON not_found_counts (day);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS not_found_counts;
+
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE workspaces
+    DROP COLUMN IF EXISTS branding;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bio_pages
+(
+    id          UUID PRIMARY KEY,
+    user_id     UUID UNIQUE NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name        TEXT UNIQUE NOT NULL,
+    title       TEXT        NOT NULL,
+    avatar_url  TEXT        NOT NULL DEFAULT '',
+    theme       TEXT        NOT NULL DEFAULT 'light',
+    shorten_ids BIGINT[]    NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE clicks
+    ADD COLUMN IF NOT EXISTS bio_page_id UUID REFERENCES bio_pages (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS clicks_bio_page_id_idx ON clicks (bio_page_id) WHERE bio_page_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE clicks
+    DROP COLUMN IF EXISTS bio_page_id;
+
+DROP TABLE IF EXISTS bio_pages;
+-- +goose StatementEnd
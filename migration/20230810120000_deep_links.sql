@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS deep_link JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE clicks
+    ADD COLUMN IF NOT EXISTS open TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE clicks
+    DROP COLUMN IF EXISTS open;
+
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS deep_link;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS shorten_revisions
+(
+    id         BIGSERIAL PRIMARY KEY,
+    shorten_id BIGINT      NOT NULL REFERENCES shortens (id) ON DELETE CASCADE,
+    user_id    UUID REFERENCES users (id) ON DELETE SET NULL,
+    action     TEXT        NOT NULL,
+    changes    JSONB       NOT NULL DEFAULT '{}',
+    state      JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS shorten_revisions_shorten_id_idx ON shorten_revisions (shorten_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS shorten_revisions;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortens
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS shortens_deleted_at_idx ON shortens (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Deleted shortens keep their row until purged, so only live ones may not
+-- share a destination.
+ALTER TABLE shortens
+    DROP CONSTRAINT IF EXISTS shortens_url_user_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS shortens_url_user_id_idx ON shortens (url, user_id) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM shortens WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS shortens_url_user_id_idx;
+
+ALTER TABLE shortens
+    ADD CONSTRAINT shortens_url_user_id_key UNIQUE (url, user_id);
+
+ALTER TABLE shortens
+    DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Records outlive the users and shortens they mention, so there are no
+-- foreign keys.
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id          BIGSERIAL PRIMARY KEY,
+    actor_id    UUID,
+    ip          TEXT        NOT NULL DEFAULT '',
+    user_agent  TEXT        NOT NULL DEFAULT '',
+    action      TEXT        NOT NULL,
+    target_type TEXT        NOT NULL,
+    target_id   TEXT        NOT NULL,
+    before      JSONB,
+    after       JSONB,
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
+CREATE INDEX IF NOT EXISTS audit_log_actor_id_idx ON audit_log (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON audit_log (target_type, target_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_log;
+-- +goose StatementEnd
@@ -4,11 +4,13 @@
 package storage
 
 import (
+	"cc/internal/domain"
 	"cc/internal/model"
 	"cc/internal/storage"
 	"context"
 	"github.com/google/uuid"
 	"sync"
+	"time"
 )
 
 // Ensure, that ShortenStorageMock does implement ShortenStorage.
@@ -21,13 +23,19 @@ var _ storage.ShortenStorage = &ShortenStorageMock{}
 //
 //		// make and configure a mocked ShortenStorage
 //		mockedShortenStorage := &ShortenStorageMock{
+//			CountFunc: func(ctx context.Context) (int64, int64, error) {
+//				panic("mock out the Count method")
+//			},
 //			CreateFunc: func(ctx context.Context, shorten model.Shorten) error {
 //				panic("mock out the Create method")
 //			},
-//			DeleteFunc: func(ctx context.Context, userID uuid.UUID, shortenID uint64) error {
+//			DeleteFunc: func(ctx context.Context, shortenID uint64, deletedAt time.Time) error {
 //				panic("mock out the Delete method")
 //			},
-//			ExistsByIDFunc: func(ctx context.Context, userID uuid.UUID, id uint64) (bool, error) {
+//			DisableByUserFunc: func(ctx context.Context, userID uuid.UUID, reason string, now time.Time) ([]uint64, error) {
+//				panic("mock out the DisableByUser method")
+//			},
+//			ExistsByIDFunc: func(ctx context.Context, id uint64) (bool, error) {
 //				panic("mock out the ExistsByID method")
 //			},
 //			ExistsByURLFunc: func(ctx context.Context, userID uuid.UUID, url string) (bool, error) {
@@ -39,8 +47,23 @@ var _ storage.ShortenStorage = &ShortenStorageMock{}
 //			GetByURLFunc: func(ctx context.Context, url string) (model.Shorten, error) {
 //				panic("mock out the GetByURL method")
 //			},
-//			GetURLFunc: func(ctx context.Context, shortenID uint64) (string, error) {
-//				panic("mock out the GetURL method")
+//			GetRedirectFunc: func(ctx context.Context, shortenID uint64) (model.Redirect, error) {
+//				panic("mock out the GetRedirect method")
+//			},
+//			PurgeFunc: func(ctx context.Context, before time.Time) ([]uint64, error) {
+//				panic("mock out the Purge method")
+//			},
+//			RestoreFunc: func(ctx context.Context, shortenID uint64) error {
+//				panic("mock out the Restore method")
+//			},
+//			SearchFunc: func(ctx context.Context, url string, domain string, limit int, offset int) (model.Shortens, error) {
+//				panic("mock out the Search method")
+//			},
+//			SelectByHealthFunc: func(ctx context.Context, userID uuid.UUID, status string, tags []string) (model.Shortens, error) {
+//				panic("mock out the SelectByHealth method")
+//			},
+//			SelectByIDsFunc: func(ctx context.Context, ids []uint64) (model.Shortens, error) {
+//				panic("mock out the SelectByIDs method")
 //			},
 //			SelectByTagsFunc: func(ctx context.Context, userID uuid.UUID, tags []string) (model.Shortens, error) {
 //				panic("mock out the SelectByTags method")
@@ -48,6 +71,36 @@ var _ storage.ShortenStorage = &ShortenStorageMock{}
 //			SelectByUserFunc: func(ctx context.Context, userID uuid.UUID) (model.Shortens, error) {
 //				panic("mock out the SelectByUser method")
 //			},
+//			SelectByWorkspaceFunc: func(ctx context.Context, workspaceID uuid.UUID, tags []string) (model.Shortens, error) {
+//				panic("mock out the SelectByWorkspace method")
+//			},
+//			SelectDeletedFunc: func(ctx context.Context, workspaceID uuid.UUID) (model.Shortens, error) {
+//				panic("mock out the SelectDeleted method")
+//			},
+//			SelectForHealthCheckFunc: func(ctx context.Context, before time.Time, limit int) (model.Shortens, error) {
+//				panic("mock out the SelectForHealthCheck method")
+//			},
+//			SelectForScreeningFunc: func(ctx context.Context, before time.Time, limit int) (model.Shortens, error) {
+//				panic("mock out the SelectForScreening method")
+//			},
+//			SetDisabledFunc: func(ctx context.Context, shortenID uint64, reason string, disabledAt *time.Time) error {
+//				panic("mock out the SetDisabled method")
+//			},
+//			SetHealthFunc: func(ctx context.Context, shortenID uint64, health model.Health) error {
+//				panic("mock out the SetHealth method")
+//			},
+//			SetPreviewFunc: func(ctx context.Context, shortenID uint64, preview model.Preview, title string, placeholder string) error {
+//				panic("mock out the SetPreview method")
+//			},
+//			SetRulesFunc: func(ctx context.Context, shortenID uint64, rules domain.Rules, updatedAt time.Time) error {
+//				panic("mock out the SetRules method")
+//			},
+//			SetScreeningFunc: func(ctx context.Context, shortenID uint64, quarantinedAt *time.Time, reason string, screenedAt time.Time) error {
+//				panic("mock out the SetScreening method")
+//			},
+//			SetVariantsFunc: func(ctx context.Context, shortenID uint64, variants domain.Variants, updatedAt time.Time) error {
+//				panic("mock out the SetVariants method")
+//			},
 //			UpdateFunc: func(ctx context.Context, shorten model.Shorten) error {
 //				panic("mock out the Update method")
 //			},
@@ -58,14 +111,20 @@ var _ storage.ShortenStorage = &ShortenStorageMock{}
 //
 //	}
 type ShortenStorageMock struct {
+	// CountFunc mocks the Count method.
+	CountFunc func(ctx context.Context) (int64, int64, error)
+
 	// CreateFunc mocks the Create method.
 	CreateFunc func(ctx context.Context, shorten model.Shorten) error
 
 	// DeleteFunc mocks the Delete method.
-	DeleteFunc func(ctx context.Context, userID uuid.UUID, shortenID uint64) error
+	DeleteFunc func(ctx context.Context, shortenID uint64, deletedAt time.Time) error
+
+	// DisableByUserFunc mocks the DisableByUser method.
+	DisableByUserFunc func(ctx context.Context, userID uuid.UUID, reason string, now time.Time) ([]uint64, error)
 
 	// ExistsByIDFunc mocks the ExistsByID method.
-	ExistsByIDFunc func(ctx context.Context, userID uuid.UUID, id uint64) (bool, error)
+	ExistsByIDFunc func(ctx context.Context, id uint64) (bool, error)
 
 	// ExistsByURLFunc mocks the ExistsByURL method.
 	ExistsByURLFunc func(ctx context.Context, userID uuid.UUID, url string) (bool, error)
@@ -76,8 +135,23 @@ type ShortenStorageMock struct {
 	// GetByURLFunc mocks the GetByURL method.
 	GetByURLFunc func(ctx context.Context, url string) (model.Shorten, error)
 
-	// GetURLFunc mocks the GetURL method.
-	GetURLFunc func(ctx context.Context, shortenID uint64) (string, error)
+	// GetRedirectFunc mocks the GetRedirect method.
+	GetRedirectFunc func(ctx context.Context, shortenID uint64) (model.Redirect, error)
+
+	// PurgeFunc mocks the Purge method.
+	PurgeFunc func(ctx context.Context, before time.Time) ([]uint64, error)
+
+	// RestoreFunc mocks the Restore method.
+	RestoreFunc func(ctx context.Context, shortenID uint64) error
+
+	// SearchFunc mocks the Search method.
+	SearchFunc func(ctx context.Context, url string, domain string, limit int, offset int) (model.Shortens, error)
+
+	// SelectByHealthFunc mocks the SelectByHealth method.
+	SelectByHealthFunc func(ctx context.Context, userID uuid.UUID, status string, tags []string) (model.Shortens, error)
+
+	// SelectByIDsFunc mocks the SelectByIDs method.
+	SelectByIDsFunc func(ctx context.Context, ids []uint64) (model.Shortens, error)
 
 	// SelectByTagsFunc mocks the SelectByTags method.
 	SelectByTagsFunc func(ctx context.Context, userID uuid.UUID, tags []string) (model.Shortens, error)
@@ -85,11 +159,46 @@ type ShortenStorageMock struct {
 	// SelectByUserFunc mocks the SelectByUser method.
 	SelectByUserFunc func(ctx context.Context, userID uuid.UUID) (model.Shortens, error)
 
+	// SelectByWorkspaceFunc mocks the SelectByWorkspace method.
+	SelectByWorkspaceFunc func(ctx context.Context, workspaceID uuid.UUID, tags []string) (model.Shortens, error)
+
+	// SelectDeletedFunc mocks the SelectDeleted method.
+	SelectDeletedFunc func(ctx context.Context, workspaceID uuid.UUID) (model.Shortens, error)
+
+	// SelectForHealthCheckFunc mocks the SelectForHealthCheck method.
+	SelectForHealthCheckFunc func(ctx context.Context, before time.Time, limit int) (model.Shortens, error)
+
+	// SelectForScreeningFunc mocks the SelectForScreening method.
+	SelectForScreeningFunc func(ctx context.Context, before time.Time, limit int) (model.Shortens, error)
+
+	// SetDisabledFunc mocks the SetDisabled method.
+	SetDisabledFunc func(ctx context.Context, shortenID uint64, reason string, disabledAt *time.Time) error
+
+	// SetHealthFunc mocks the SetHealth method.
+	SetHealthFunc func(ctx context.Context, shortenID uint64, health model.Health) error
+
+	// SetPreviewFunc mocks the SetPreview method.
+	SetPreviewFunc func(ctx context.Context, shortenID uint64, preview model.Preview, title string, placeholder string) error
+
+	// SetRulesFunc mocks the SetRules method.
+	SetRulesFunc func(ctx context.Context, shortenID uint64, rules domain.Rules, updatedAt time.Time) error
+
+	// SetScreeningFunc mocks the SetScreening method.
+	SetScreeningFunc func(ctx context.Context, shortenID uint64, quarantinedAt *time.Time, reason string, screenedAt time.Time) error
+
+	// SetVariantsFunc mocks the SetVariants method.
+	SetVariantsFunc func(ctx context.Context, shortenID uint64, variants domain.Variants, updatedAt time.Time) error
+
 	// UpdateFunc mocks the Update method.
 	UpdateFunc func(ctx context.Context, shorten model.Shorten) error
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// Count holds details about calls to the Count method.
+		Count []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
 		// Create holds details about calls to the Create method.
 		Create []struct {
 			// Ctx is the ctx argument value.
@@ -101,17 +210,26 @@ type ShortenStorageMock struct {
 		Delete []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
-			// UserID is the userID argument value.
-			UserID uuid.UUID
 			// ShortenID is the shortenID argument value.
 			ShortenID uint64
+			// DeletedAt is the deletedAt argument value.
+			DeletedAt time.Time
 		}
-		// ExistsByID holds details about calls to the ExistsByID method.
-		ExistsByID []struct {
+		// DisableByUser holds details about calls to the DisableByUser method.
+		DisableByUser []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 			// UserID is the userID argument value.
 			UserID uuid.UUID
+			// Reason is the reason argument value.
+			Reason string
+			// Now is the now argument value.
+			Now time.Time
+		}
+		// ExistsByID holds details about calls to the ExistsByID method.
+		ExistsByID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the id argument value.
 			ID uint64
 		}
@@ -138,13 +256,58 @@ type ShortenStorageMock struct {
 			// URL is the url argument value.
 			URL string
 		}
-		// GetURL holds details about calls to the GetURL method.
-		GetURL []struct {
+		// GetRedirect holds details about calls to the GetRedirect method.
+		GetRedirect []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ShortenID is the shortenID argument value.
+			ShortenID uint64
+		}
+		// Purge holds details about calls to the Purge method.
+		Purge []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before time.Time
+		}
+		// Restore holds details about calls to the Restore method.
+		Restore []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 			// ShortenID is the shortenID argument value.
 			ShortenID uint64
 		}
+		// Search holds details about calls to the Search method.
+		Search []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// URL is the url argument value.
+			URL string
+			// Domain is the domain argument value.
+			Domain string
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+		// SelectByHealth holds details about calls to the SelectByHealth method.
+		SelectByHealth []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserID is the userID argument value.
+			UserID uuid.UUID
+			// Status is the status argument value.
+			Status string
+			// Tags is the tags argument value.
+			Tags []string
+		}
+		// SelectByIDs holds details about calls to the SelectByIDs method.
+		SelectByIDs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ids is the ids argument value.
+			Ids []uint64
+		}
 		// SelectByTags holds details about calls to the SelectByTags method.
 		SelectByTags []struct {
 			// Ctx is the ctx argument value.
@@ -161,6 +324,108 @@ type ShortenStorageMock struct {
 			// UserID is the userID argument value.
 			UserID uuid.UUID
 		}
+		// SelectByWorkspace holds details about calls to the SelectByWorkspace method.
+		SelectByWorkspace []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// WorkspaceID is the workspaceID argument value.
+			WorkspaceID uuid.UUID
+			// Tags is the tags argument value.
+			Tags []string
+		}
+		// SelectDeleted holds details about calls to the SelectDeleted method.
+		SelectDeleted []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// WorkspaceID is the workspaceID argument value.
+			WorkspaceID uuid.UUID
+		}
+		// SelectForHealthCheck holds details about calls to the SelectForHealthCheck method.
+		SelectForHealthCheck []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before time.Time
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// SelectForScreening holds details about calls to the SelectForScreening method.
+		SelectForScreening []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before time.Time
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// SetDisabled holds details about calls to the SetDisabled method.
+		SetDisabled []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ShortenID is the shortenID argument value.
+			ShortenID uint64
+			// Reason is the reason argument value.
+			Reason string
+			// DisabledAt is the disabledAt argument value.
+			DisabledAt *time.Time
+		}
+		// SetHealth holds details about calls to the SetHealth method.
+		SetHealth []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ShortenID is the shortenID argument value.
+			ShortenID uint64
+			// Health is the health argument value.
+			Health model.Health
+		}
+		// SetPreview holds details about calls to the SetPreview method.
+		SetPreview []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ShortenID is the shortenID argument value.
+			ShortenID uint64
+			// Preview is the preview argument value.
+			Preview model.Preview
+			// Title is the title argument value.
+			Title string
+			// Placeholder is the placeholder argument value.
+			Placeholder string
+		}
+		// SetRules holds details about calls to the SetRules method.
+		SetRules []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ShortenID is the shortenID argument value.
+			ShortenID uint64
+			// Rules is the rules argument value.
+			Rules domain.Rules
+			// UpdatedAt is the updatedAt argument value.
+			UpdatedAt time.Time
+		}
+		// SetScreening holds details about calls to the SetScreening method.
+		SetScreening []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ShortenID is the shortenID argument value.
+			ShortenID uint64
+			// QuarantinedAt is the quarantinedAt argument value.
+			QuarantinedAt *time.Time
+			// Reason is the reason argument value.
+			Reason string
+			// ScreenedAt is the screenedAt argument value.
+			ScreenedAt time.Time
+		}
+		// SetVariants holds details about calls to the SetVariants method.
+		SetVariants []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ShortenID is the shortenID argument value.
+			ShortenID uint64
+			// Variants is the variants argument value.
+			Variants domain.Variants
+			// UpdatedAt is the updatedAt argument value.
+			UpdatedAt time.Time
+		}
 		// Update holds details about calls to the Update method.
 		Update []struct {
 			// Ctx is the ctx argument value.
@@ -169,16 +434,65 @@ type ShortenStorageMock struct {
 			Shorten model.Shorten
 		}
 	}
-	lockCreate       sync.RWMutex
-	lockDelete       sync.RWMutex
-	lockExistsByID   sync.RWMutex
-	lockExistsByURL  sync.RWMutex
-	lockGetByID      sync.RWMutex
-	lockGetByURL     sync.RWMutex
-	lockGetURL       sync.RWMutex
-	lockSelectByTags sync.RWMutex
-	lockSelectByUser sync.RWMutex
-	lockUpdate       sync.RWMutex
+	lockCount                sync.RWMutex
+	lockCreate               sync.RWMutex
+	lockDelete               sync.RWMutex
+	lockDisableByUser        sync.RWMutex
+	lockExistsByID           sync.RWMutex
+	lockExistsByURL          sync.RWMutex
+	lockGetByID              sync.RWMutex
+	lockGetByURL             sync.RWMutex
+	lockGetRedirect          sync.RWMutex
+	lockPurge                sync.RWMutex
+	lockRestore              sync.RWMutex
+	lockSearch               sync.RWMutex
+	lockSelectByHealth       sync.RWMutex
+	lockSelectByIDs          sync.RWMutex
+	lockSelectByTags         sync.RWMutex
+	lockSelectByUser         sync.RWMutex
+	lockSelectByWorkspace    sync.RWMutex
+	lockSelectDeleted        sync.RWMutex
+	lockSelectForHealthCheck sync.RWMutex
+	lockSelectForScreening   sync.RWMutex
+	lockSetDisabled          sync.RWMutex
+	lockSetHealth            sync.RWMutex
+	lockSetPreview           sync.RWMutex
+	lockSetRules             sync.RWMutex
+	lockSetScreening         sync.RWMutex
+	lockSetVariants          sync.RWMutex
+	lockUpdate               sync.RWMutex
+}
+
+// Count calls CountFunc.
+func (mock *ShortenStorageMock) Count(ctx context.Context) (int64, int64, error) {
+	if mock.CountFunc == nil {
+		panic("ShortenStorageMock.CountFunc: method is nil but ShortenStorage.Count was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockCount.Lock()
+	mock.calls.Count = append(mock.calls.Count, callInfo)
+	mock.lockCount.Unlock()
+	return mock.CountFunc(ctx)
+}
+
+// CountCalls gets all the calls that were made to Count.
+// Check the length with:
+//
+//	len(mockedShortenStorage.CountCalls())
+func (mock *ShortenStorageMock) CountCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockCount.RLock()
+	calls = mock.calls.Count
+	mock.lockCount.RUnlock()
+	return calls
 }
 
 // Create calls CreateFunc.
@@ -218,23 +532,23 @@ func (mock *ShortenStorageMock) CreateCalls() []struct {
 }
 
 // Delete calls DeleteFunc.
-func (mock *ShortenStorageMock) Delete(ctx context.Context, userID uuid.UUID, shortenID uint64) error {
+func (mock *ShortenStorageMock) Delete(ctx context.Context, shortenID uint64, deletedAt time.Time) error {
 	if mock.DeleteFunc == nil {
 		panic("ShortenStorageMock.DeleteFunc: method is nil but ShortenStorage.Delete was just called")
 	}
 	callInfo := struct {
 		Ctx       context.Context
-		UserID    uuid.UUID
 		ShortenID uint64
+		DeletedAt time.Time
 	}{
 		Ctx:       ctx,
-		UserID:    userID,
 		ShortenID: shortenID,
+		DeletedAt: deletedAt,
 	}
 	mock.lockDelete.Lock()
 	mock.calls.Delete = append(mock.calls.Delete, callInfo)
 	mock.lockDelete.Unlock()
-	return mock.DeleteFunc(ctx, userID, shortenID)
+	return mock.DeleteFunc(ctx, shortenID, deletedAt)
 }
 
 // DeleteCalls gets all the calls that were made to Delete.
@@ -243,13 +557,13 @@ func (mock *ShortenStorageMock) Delete(ctx context.Context, userID uuid.UUID, sh
 //	len(mockedShortenStorage.DeleteCalls())
 func (mock *ShortenStorageMock) DeleteCalls() []struct {
 	Ctx       context.Context
-	UserID    uuid.UUID
 	ShortenID uint64
+	DeletedAt time.Time
 } {
 	var calls []struct {
 		Ctx       context.Context
-		UserID    uuid.UUID
 		ShortenID uint64
+		DeletedAt time.Time
 	}
 	mock.lockDelete.RLock()
 	calls = mock.calls.Delete
@@ -257,24 +571,66 @@ func (mock *ShortenStorageMock) DeleteCalls() []struct {
 	return calls
 }
 
-// ExistsByID calls ExistsByIDFunc.
-func (mock *ShortenStorageMock) ExistsByID(ctx context.Context, userID uuid.UUID, id uint64) (bool, error) {
-	if mock.ExistsByIDFunc == nil {
-		panic("ShortenStorageMock.ExistsByIDFunc: method is nil but ShortenStorage.ExistsByID was just called")
+// DisableByUser calls DisableByUserFunc.
+func (mock *ShortenStorageMock) DisableByUser(ctx context.Context, userID uuid.UUID, reason string, now time.Time) ([]uint64, error) {
+	if mock.DisableByUserFunc == nil {
+		panic("ShortenStorageMock.DisableByUserFunc: method is nil but ShortenStorage.DisableByUser was just called")
 	}
 	callInfo := struct {
 		Ctx    context.Context
 		UserID uuid.UUID
-		ID     uint64
+		Reason string
+		Now    time.Time
 	}{
 		Ctx:    ctx,
 		UserID: userID,
-		ID:     id,
+		Reason: reason,
+		Now:    now,
+	}
+	mock.lockDisableByUser.Lock()
+	mock.calls.DisableByUser = append(mock.calls.DisableByUser, callInfo)
+	mock.lockDisableByUser.Unlock()
+	return mock.DisableByUserFunc(ctx, userID, reason, now)
+}
+
+// DisableByUserCalls gets all the calls that were made to DisableByUser.
+// Check the length with:
+//
+//	len(mockedShortenStorage.DisableByUserCalls())
+func (mock *ShortenStorageMock) DisableByUserCalls() []struct {
+	Ctx    context.Context
+	UserID uuid.UUID
+	Reason string
+	Now    time.Time
+} {
+	var calls []struct {
+		Ctx    context.Context
+		UserID uuid.UUID
+		Reason string
+		Now    time.Time
+	}
+	mock.lockDisableByUser.RLock()
+	calls = mock.calls.DisableByUser
+	mock.lockDisableByUser.RUnlock()
+	return calls
+}
+
+// ExistsByID calls ExistsByIDFunc.
+func (mock *ShortenStorageMock) ExistsByID(ctx context.Context, id uint64) (bool, error) {
+	if mock.ExistsByIDFunc == nil {
+		panic("ShortenStorageMock.ExistsByIDFunc: method is nil but ShortenStorage.ExistsByID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  uint64
+	}{
+		Ctx: ctx,
+		ID:  id,
 	}
 	mock.lockExistsByID.Lock()
 	mock.calls.ExistsByID = append(mock.calls.ExistsByID, callInfo)
 	mock.lockExistsByID.Unlock()
-	return mock.ExistsByIDFunc(ctx, userID, id)
+	return mock.ExistsByIDFunc(ctx, id)
 }
 
 // ExistsByIDCalls gets all the calls that were made to ExistsByID.
@@ -282,14 +638,12 @@ func (mock *ShortenStorageMock) ExistsByID(ctx context.Context, userID uuid.UUID
 //
 //	len(mockedShortenStorage.ExistsByIDCalls())
 func (mock *ShortenStorageMock) ExistsByIDCalls() []struct {
-	Ctx    context.Context
-	UserID uuid.UUID
-	ID     uint64
+	Ctx context.Context
+	ID  uint64
 } {
 	var calls []struct {
-		Ctx    context.Context
-		UserID uuid.UUID
-		ID     uint64
+		Ctx context.Context
+		ID  uint64
 	}
 	mock.lockExistsByID.RLock()
 	calls = mock.calls.ExistsByID
@@ -409,10 +763,10 @@ func (mock *ShortenStorageMock) GetByURLCalls() []struct {
 	return calls
 }
 
-// GetURL calls GetURLFunc.
-func (mock *ShortenStorageMock) GetURL(ctx context.Context, shortenID uint64) (string, error) {
-	if mock.GetURLFunc == nil {
-		panic("ShortenStorageMock.GetURLFunc: method is nil but ShortenStorage.GetURL was just called")
+// GetRedirect calls GetRedirectFunc.
+func (mock *ShortenStorageMock) GetRedirect(ctx context.Context, shortenID uint64) (model.Redirect, error) {
+	if mock.GetRedirectFunc == nil {
+		panic("ShortenStorageMock.GetRedirectFunc: method is nil but ShortenStorage.GetRedirect was just called")
 	}
 	callInfo := struct {
 		Ctx       context.Context
@@ -421,17 +775,17 @@ func (mock *ShortenStorageMock) GetURL(ctx context.Context, shortenID uint64) (s
 		Ctx:       ctx,
 		ShortenID: shortenID,
 	}
-	mock.lockGetURL.Lock()
-	mock.calls.GetURL = append(mock.calls.GetURL, callInfo)
-	mock.lockGetURL.Unlock()
-	return mock.GetURLFunc(ctx, shortenID)
+	mock.lockGetRedirect.Lock()
+	mock.calls.GetRedirect = append(mock.calls.GetRedirect, callInfo)
+	mock.lockGetRedirect.Unlock()
+	return mock.GetRedirectFunc(ctx, shortenID)
 }
 
-// GetURLCalls gets all the calls that were made to GetURL.
+// GetRedirectCalls gets all the calls that were made to GetRedirect.
 // Check the length with:
 //
-//	len(mockedShortenStorage.GetURLCalls())
-func (mock *ShortenStorageMock) GetURLCalls() []struct {
+//	len(mockedShortenStorage.GetRedirectCalls())
+func (mock *ShortenStorageMock) GetRedirectCalls() []struct {
 	Ctx       context.Context
 	ShortenID uint64
 } {
@@ -439,9 +793,209 @@ func (mock *ShortenStorageMock) GetURLCalls() []struct {
 		Ctx       context.Context
 		ShortenID uint64
 	}
-	mock.lockGetURL.RLock()
-	calls = mock.calls.GetURL
-	mock.lockGetURL.RUnlock()
+	mock.lockGetRedirect.RLock()
+	calls = mock.calls.GetRedirect
+	mock.lockGetRedirect.RUnlock()
+	return calls
+}
+
+// Purge calls PurgeFunc.
+func (mock *ShortenStorageMock) Purge(ctx context.Context, before time.Time) ([]uint64, error) {
+	if mock.PurgeFunc == nil {
+		panic("ShortenStorageMock.PurgeFunc: method is nil but ShortenStorage.Purge was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before time.Time
+	}{
+		Ctx:    ctx,
+		Before: before,
+	}
+	mock.lockPurge.Lock()
+	mock.calls.Purge = append(mock.calls.Purge, callInfo)
+	mock.lockPurge.Unlock()
+	return mock.PurgeFunc(ctx, before)
+}
+
+// PurgeCalls gets all the calls that were made to Purge.
+// Check the length with:
+//
+//	len(mockedShortenStorage.PurgeCalls())
+func (mock *ShortenStorageMock) PurgeCalls() []struct {
+	Ctx    context.Context
+	Before time.Time
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before time.Time
+	}
+	mock.lockPurge.RLock()
+	calls = mock.calls.Purge
+	mock.lockPurge.RUnlock()
+	return calls
+}
+
+// Restore calls RestoreFunc.
+func (mock *ShortenStorageMock) Restore(ctx context.Context, shortenID uint64) error {
+	if mock.RestoreFunc == nil {
+		panic("ShortenStorageMock.RestoreFunc: method is nil but ShortenStorage.Restore was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ShortenID uint64
+	}{
+		Ctx:       ctx,
+		ShortenID: shortenID,
+	}
+	mock.lockRestore.Lock()
+	mock.calls.Restore = append(mock.calls.Restore, callInfo)
+	mock.lockRestore.Unlock()
+	return mock.RestoreFunc(ctx, shortenID)
+}
+
+// RestoreCalls gets all the calls that were made to Restore.
+// Check the length with:
+//
+//	len(mockedShortenStorage.RestoreCalls())
+func (mock *ShortenStorageMock) RestoreCalls() []struct {
+	Ctx       context.Context
+	ShortenID uint64
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ShortenID uint64
+	}
+	mock.lockRestore.RLock()
+	calls = mock.calls.Restore
+	mock.lockRestore.RUnlock()
+	return calls
+}
+
+// Search calls SearchFunc.
+func (mock *ShortenStorageMock) Search(ctx context.Context, url string, domain string, limit int, offset int) (model.Shortens, error) {
+	if mock.SearchFunc == nil {
+		panic("ShortenStorageMock.SearchFunc: method is nil but ShortenStorage.Search was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		URL    string
+		Domain string
+		Limit  int
+		Offset int
+	}{
+		Ctx:    ctx,
+		URL:    url,
+		Domain: domain,
+		Limit:  limit,
+		Offset: offset,
+	}
+	mock.lockSearch.Lock()
+	mock.calls.Search = append(mock.calls.Search, callInfo)
+	mock.lockSearch.Unlock()
+	return mock.SearchFunc(ctx, url, domain, limit, offset)
+}
+
+// SearchCalls gets all the calls that were made to Search.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SearchCalls())
+func (mock *ShortenStorageMock) SearchCalls() []struct {
+	Ctx    context.Context
+	URL    string
+	Domain string
+	Limit  int
+	Offset int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		URL    string
+		Domain string
+		Limit  int
+		Offset int
+	}
+	mock.lockSearch.RLock()
+	calls = mock.calls.Search
+	mock.lockSearch.RUnlock()
+	return calls
+}
+
+// SelectByHealth calls SelectByHealthFunc.
+func (mock *ShortenStorageMock) SelectByHealth(ctx context.Context, userID uuid.UUID, status string, tags []string) (model.Shortens, error) {
+	if mock.SelectByHealthFunc == nil {
+		panic("ShortenStorageMock.SelectByHealthFunc: method is nil but ShortenStorage.SelectByHealth was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		UserID uuid.UUID
+		Status string
+		Tags   []string
+	}{
+		Ctx:    ctx,
+		UserID: userID,
+		Status: status,
+		Tags:   tags,
+	}
+	mock.lockSelectByHealth.Lock()
+	mock.calls.SelectByHealth = append(mock.calls.SelectByHealth, callInfo)
+	mock.lockSelectByHealth.Unlock()
+	return mock.SelectByHealthFunc(ctx, userID, status, tags)
+}
+
+// SelectByHealthCalls gets all the calls that were made to SelectByHealth.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SelectByHealthCalls())
+func (mock *ShortenStorageMock) SelectByHealthCalls() []struct {
+	Ctx    context.Context
+	UserID uuid.UUID
+	Status string
+	Tags   []string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		UserID uuid.UUID
+		Status string
+		Tags   []string
+	}
+	mock.lockSelectByHealth.RLock()
+	calls = mock.calls.SelectByHealth
+	mock.lockSelectByHealth.RUnlock()
+	return calls
+}
+
+// SelectByIDs calls SelectByIDsFunc.
+func (mock *ShortenStorageMock) SelectByIDs(ctx context.Context, ids []uint64) (model.Shortens, error) {
+	if mock.SelectByIDsFunc == nil {
+		panic("ShortenStorageMock.SelectByIDsFunc: method is nil but ShortenStorage.SelectByIDs was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ids []uint64
+	}{
+		Ctx: ctx,
+		Ids: ids,
+	}
+	mock.lockSelectByIDs.Lock()
+	mock.calls.SelectByIDs = append(mock.calls.SelectByIDs, callInfo)
+	mock.lockSelectByIDs.Unlock()
+	return mock.SelectByIDsFunc(ctx, ids)
+}
+
+// SelectByIDsCalls gets all the calls that were made to SelectByIDs.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SelectByIDsCalls())
+func (mock *ShortenStorageMock) SelectByIDsCalls() []struct {
+	Ctx context.Context
+	Ids []uint64
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ids []uint64
+	}
+	mock.lockSelectByIDs.RLock()
+	calls = mock.calls.SelectByIDs
+	mock.lockSelectByIDs.RUnlock()
 	return calls
 }
 
@@ -521,6 +1075,430 @@ func (mock *ShortenStorageMock) SelectByUserCalls() []struct {
 	return calls
 }
 
+// SelectByWorkspace calls SelectByWorkspaceFunc.
+func (mock *ShortenStorageMock) SelectByWorkspace(ctx context.Context, workspaceID uuid.UUID, tags []string) (model.Shortens, error) {
+	if mock.SelectByWorkspaceFunc == nil {
+		panic("ShortenStorageMock.SelectByWorkspaceFunc: method is nil but ShortenStorage.SelectByWorkspace was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		WorkspaceID uuid.UUID
+		Tags        []string
+	}{
+		Ctx:         ctx,
+		WorkspaceID: workspaceID,
+		Tags:        tags,
+	}
+	mock.lockSelectByWorkspace.Lock()
+	mock.calls.SelectByWorkspace = append(mock.calls.SelectByWorkspace, callInfo)
+	mock.lockSelectByWorkspace.Unlock()
+	return mock.SelectByWorkspaceFunc(ctx, workspaceID, tags)
+}
+
+// SelectByWorkspaceCalls gets all the calls that were made to SelectByWorkspace.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SelectByWorkspaceCalls())
+func (mock *ShortenStorageMock) SelectByWorkspaceCalls() []struct {
+	Ctx         context.Context
+	WorkspaceID uuid.UUID
+	Tags        []string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		WorkspaceID uuid.UUID
+		Tags        []string
+	}
+	mock.lockSelectByWorkspace.RLock()
+	calls = mock.calls.SelectByWorkspace
+	mock.lockSelectByWorkspace.RUnlock()
+	return calls
+}
+
+// SelectDeleted calls SelectDeletedFunc.
+func (mock *ShortenStorageMock) SelectDeleted(ctx context.Context, workspaceID uuid.UUID) (model.Shortens, error) {
+	if mock.SelectDeletedFunc == nil {
+		panic("ShortenStorageMock.SelectDeletedFunc: method is nil but ShortenStorage.SelectDeleted was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		WorkspaceID uuid.UUID
+	}{
+		Ctx:         ctx,
+		WorkspaceID: workspaceID,
+	}
+	mock.lockSelectDeleted.Lock()
+	mock.calls.SelectDeleted = append(mock.calls.SelectDeleted, callInfo)
+	mock.lockSelectDeleted.Unlock()
+	return mock.SelectDeletedFunc(ctx, workspaceID)
+}
+
+// SelectDeletedCalls gets all the calls that were made to SelectDeleted.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SelectDeletedCalls())
+func (mock *ShortenStorageMock) SelectDeletedCalls() []struct {
+	Ctx         context.Context
+	WorkspaceID uuid.UUID
+} {
+	var calls []struct {
+		Ctx         context.Context
+		WorkspaceID uuid.UUID
+	}
+	mock.lockSelectDeleted.RLock()
+	calls = mock.calls.SelectDeleted
+	mock.lockSelectDeleted.RUnlock()
+	return calls
+}
+
+// SelectForHealthCheck calls SelectForHealthCheckFunc.
+func (mock *ShortenStorageMock) SelectForHealthCheck(ctx context.Context, before time.Time, limit int) (model.Shortens, error) {
+	if mock.SelectForHealthCheckFunc == nil {
+		panic("ShortenStorageMock.SelectForHealthCheckFunc: method is nil but ShortenStorage.SelectForHealthCheck was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before time.Time
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Before: before,
+		Limit:  limit,
+	}
+	mock.lockSelectForHealthCheck.Lock()
+	mock.calls.SelectForHealthCheck = append(mock.calls.SelectForHealthCheck, callInfo)
+	mock.lockSelectForHealthCheck.Unlock()
+	return mock.SelectForHealthCheckFunc(ctx, before, limit)
+}
+
+// SelectForHealthCheckCalls gets all the calls that were made to SelectForHealthCheck.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SelectForHealthCheckCalls())
+func (mock *ShortenStorageMock) SelectForHealthCheckCalls() []struct {
+	Ctx    context.Context
+	Before time.Time
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before time.Time
+		Limit  int
+	}
+	mock.lockSelectForHealthCheck.RLock()
+	calls = mock.calls.SelectForHealthCheck
+	mock.lockSelectForHealthCheck.RUnlock()
+	return calls
+}
+
+// SelectForScreening calls SelectForScreeningFunc.
+func (mock *ShortenStorageMock) SelectForScreening(ctx context.Context, before time.Time, limit int) (model.Shortens, error) {
+	if mock.SelectForScreeningFunc == nil {
+		panic("ShortenStorageMock.SelectForScreeningFunc: method is nil but ShortenStorage.SelectForScreening was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before time.Time
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Before: before,
+		Limit:  limit,
+	}
+	mock.lockSelectForScreening.Lock()
+	mock.calls.SelectForScreening = append(mock.calls.SelectForScreening, callInfo)
+	mock.lockSelectForScreening.Unlock()
+	return mock.SelectForScreeningFunc(ctx, before, limit)
+}
+
+// SelectForScreeningCalls gets all the calls that were made to SelectForScreening.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SelectForScreeningCalls())
+func (mock *ShortenStorageMock) SelectForScreeningCalls() []struct {
+	Ctx    context.Context
+	Before time.Time
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before time.Time
+		Limit  int
+	}
+	mock.lockSelectForScreening.RLock()
+	calls = mock.calls.SelectForScreening
+	mock.lockSelectForScreening.RUnlock()
+	return calls
+}
+
+// SetDisabled calls SetDisabledFunc.
+func (mock *ShortenStorageMock) SetDisabled(ctx context.Context, shortenID uint64, reason string, disabledAt *time.Time) error {
+	if mock.SetDisabledFunc == nil {
+		panic("ShortenStorageMock.SetDisabledFunc: method is nil but ShortenStorage.SetDisabled was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		ShortenID  uint64
+		Reason     string
+		DisabledAt *time.Time
+	}{
+		Ctx:        ctx,
+		ShortenID:  shortenID,
+		Reason:     reason,
+		DisabledAt: disabledAt,
+	}
+	mock.lockSetDisabled.Lock()
+	mock.calls.SetDisabled = append(mock.calls.SetDisabled, callInfo)
+	mock.lockSetDisabled.Unlock()
+	return mock.SetDisabledFunc(ctx, shortenID, reason, disabledAt)
+}
+
+// SetDisabledCalls gets all the calls that were made to SetDisabled.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SetDisabledCalls())
+func (mock *ShortenStorageMock) SetDisabledCalls() []struct {
+	Ctx        context.Context
+	ShortenID  uint64
+	Reason     string
+	DisabledAt *time.Time
+} {
+	var calls []struct {
+		Ctx        context.Context
+		ShortenID  uint64
+		Reason     string
+		DisabledAt *time.Time
+	}
+	mock.lockSetDisabled.RLock()
+	calls = mock.calls.SetDisabled
+	mock.lockSetDisabled.RUnlock()
+	return calls
+}
+
+// SetHealth calls SetHealthFunc.
+func (mock *ShortenStorageMock) SetHealth(ctx context.Context, shortenID uint64, health model.Health) error {
+	if mock.SetHealthFunc == nil {
+		panic("ShortenStorageMock.SetHealthFunc: method is nil but ShortenStorage.SetHealth was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ShortenID uint64
+		Health    model.Health
+	}{
+		Ctx:       ctx,
+		ShortenID: shortenID,
+		Health:    health,
+	}
+	mock.lockSetHealth.Lock()
+	mock.calls.SetHealth = append(mock.calls.SetHealth, callInfo)
+	mock.lockSetHealth.Unlock()
+	return mock.SetHealthFunc(ctx, shortenID, health)
+}
+
+// SetHealthCalls gets all the calls that were made to SetHealth.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SetHealthCalls())
+func (mock *ShortenStorageMock) SetHealthCalls() []struct {
+	Ctx       context.Context
+	ShortenID uint64
+	Health    model.Health
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ShortenID uint64
+		Health    model.Health
+	}
+	mock.lockSetHealth.RLock()
+	calls = mock.calls.SetHealth
+	mock.lockSetHealth.RUnlock()
+	return calls
+}
+
+// SetPreview calls SetPreviewFunc.
+func (mock *ShortenStorageMock) SetPreview(ctx context.Context, shortenID uint64, preview model.Preview, title string, placeholder string) error {
+	if mock.SetPreviewFunc == nil {
+		panic("ShortenStorageMock.SetPreviewFunc: method is nil but ShortenStorage.SetPreview was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		ShortenID   uint64
+		Preview     model.Preview
+		Title       string
+		Placeholder string
+	}{
+		Ctx:         ctx,
+		ShortenID:   shortenID,
+		Preview:     preview,
+		Title:       title,
+		Placeholder: placeholder,
+	}
+	mock.lockSetPreview.Lock()
+	mock.calls.SetPreview = append(mock.calls.SetPreview, callInfo)
+	mock.lockSetPreview.Unlock()
+	return mock.SetPreviewFunc(ctx, shortenID, preview, title, placeholder)
+}
+
+// SetPreviewCalls gets all the calls that were made to SetPreview.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SetPreviewCalls())
+func (mock *ShortenStorageMock) SetPreviewCalls() []struct {
+	Ctx         context.Context
+	ShortenID   uint64
+	Preview     model.Preview
+	Title       string
+	Placeholder string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		ShortenID   uint64
+		Preview     model.Preview
+		Title       string
+		Placeholder string
+	}
+	mock.lockSetPreview.RLock()
+	calls = mock.calls.SetPreview
+	mock.lockSetPreview.RUnlock()
+	return calls
+}
+
+// SetRules calls SetRulesFunc.
+func (mock *ShortenStorageMock) SetRules(ctx context.Context, shortenID uint64, rules domain.Rules, updatedAt time.Time) error {
+	if mock.SetRulesFunc == nil {
+		panic("ShortenStorageMock.SetRulesFunc: method is nil but ShortenStorage.SetRules was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ShortenID uint64
+		Rules     domain.Rules
+		UpdatedAt time.Time
+	}{
+		Ctx:       ctx,
+		ShortenID: shortenID,
+		Rules:     rules,
+		UpdatedAt: updatedAt,
+	}
+	mock.lockSetRules.Lock()
+	mock.calls.SetRules = append(mock.calls.SetRules, callInfo)
+	mock.lockSetRules.Unlock()
+	return mock.SetRulesFunc(ctx, shortenID, rules, updatedAt)
+}
+
+// SetRulesCalls gets all the calls that were made to SetRules.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SetRulesCalls())
+func (mock *ShortenStorageMock) SetRulesCalls() []struct {
+	Ctx       context.Context
+	ShortenID uint64
+	Rules     domain.Rules
+	UpdatedAt time.Time
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ShortenID uint64
+		Rules     domain.Rules
+		UpdatedAt time.Time
+	}
+	mock.lockSetRules.RLock()
+	calls = mock.calls.SetRules
+	mock.lockSetRules.RUnlock()
+	return calls
+}
+
+// SetScreening calls SetScreeningFunc.
+func (mock *ShortenStorageMock) SetScreening(ctx context.Context, shortenID uint64, quarantinedAt *time.Time, reason string, screenedAt time.Time) error {
+	if mock.SetScreeningFunc == nil {
+		panic("ShortenStorageMock.SetScreeningFunc: method is nil but ShortenStorage.SetScreening was just called")
+	}
+	callInfo := struct {
+		Ctx           context.Context
+		ShortenID     uint64
+		QuarantinedAt *time.Time
+		Reason        string
+		ScreenedAt    time.Time
+	}{
+		Ctx:           ctx,
+		ShortenID:     shortenID,
+		QuarantinedAt: quarantinedAt,
+		Reason:        reason,
+		ScreenedAt:    screenedAt,
+	}
+	mock.lockSetScreening.Lock()
+	mock.calls.SetScreening = append(mock.calls.SetScreening, callInfo)
+	mock.lockSetScreening.Unlock()
+	return mock.SetScreeningFunc(ctx, shortenID, quarantinedAt, reason, screenedAt)
+}
+
+// SetScreeningCalls gets all the calls that were made to SetScreening.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SetScreeningCalls())
+func (mock *ShortenStorageMock) SetScreeningCalls() []struct {
+	Ctx           context.Context
+	ShortenID     uint64
+	QuarantinedAt *time.Time
+	Reason        string
+	ScreenedAt    time.Time
+} {
+	var calls []struct {
+		Ctx           context.Context
+		ShortenID     uint64
+		QuarantinedAt *time.Time
+		Reason        string
+		ScreenedAt    time.Time
+	}
+	mock.lockSetScreening.RLock()
+	calls = mock.calls.SetScreening
+	mock.lockSetScreening.RUnlock()
+	return calls
+}
+
+// SetVariants calls SetVariantsFunc.
+func (mock *ShortenStorageMock) SetVariants(ctx context.Context, shortenID uint64, variants domain.Variants, updatedAt time.Time) error {
+	if mock.SetVariantsFunc == nil {
+		panic("ShortenStorageMock.SetVariantsFunc: method is nil but ShortenStorage.SetVariants was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ShortenID uint64
+		Variants  domain.Variants
+		UpdatedAt time.Time
+	}{
+		Ctx:       ctx,
+		ShortenID: shortenID,
+		Variants:  variants,
+		UpdatedAt: updatedAt,
+	}
+	mock.lockSetVariants.Lock()
+	mock.calls.SetVariants = append(mock.calls.SetVariants, callInfo)
+	mock.lockSetVariants.Unlock()
+	return mock.SetVariantsFunc(ctx, shortenID, variants, updatedAt)
+}
+
+// SetVariantsCalls gets all the calls that were made to SetVariants.
+// Check the length with:
+//
+//	len(mockedShortenStorage.SetVariantsCalls())
+func (mock *ShortenStorageMock) SetVariantsCalls() []struct {
+	Ctx       context.Context
+	ShortenID uint64
+	Variants  domain.Variants
+	UpdatedAt time.Time
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ShortenID uint64
+		Variants  domain.Variants
+		UpdatedAt time.Time
+	}
+	mock.lockSetVariants.RLock()
+	calls = mock.calls.SetVariants
+	mock.lockSetVariants.RUnlock()
+	return calls
+}
+
 // Update calls UpdateFunc.
 func (mock *ShortenStorageMock) Update(ctx context.Context, shorten model.Shorten) error {
 	if mock.UpdateFunc == nil {
@@ -3,6 +3,7 @@ package apperror
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 var lastCode Code
@@ -10,11 +11,12 @@ var lastCode Code
 type Code uint64
 
 type Error struct {
-	Code    Code   `json:"code"`
-	Status  string `json:"status"`
-	Message string `json:"message"`
-	Scope   string `json:"-"`
-	Err     error  `json:"-"`
+	Code       Code          `json:"code"`
+	Status     string        `json:"status"`
+	Message    string        `json:"message"`
+	Scope      string        `json:"-"`
+	Err        error         `json:"-"`
+	RetryAfter time.Duration `json:"-"`
 }
 
 func New(status string) Error {
@@ -82,3 +84,9 @@ func (error Error) WithScope(scope string) Error {
 
 	return error
 }
+
+func (error Error) WithRetryAfter(retryAfter time.Duration) Error {
+	error.RetryAfter = retryAfter
+
+	return error
+}
@@ -0,0 +1,73 @@
+package geo
+
+import (
+	"github.com/oschwald/geoip2-golang"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Locator maps an IP address to an ISO 3166-1 alpha-2 country code.
+type Locator interface {
+	Country(ip net.IP) (string, error)
+}
+
+type MaxMind struct {
+	reader *geoip2.Reader
+}
+
+// OpenMaxMind opens a GeoIP2 or GeoLite2 Country or City database.
+func OpenMaxMind(path string) (*MaxMind, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MaxMind{reader: reader}, nil
+}
+
+func (maxMind *MaxMind) Country(ip net.IP) (string, error) {
+	record, err := maxMind.reader.Country(ip)
+	if err != nil {
+		return "", err
+	}
+
+	return record.Country.IsoCode, nil
+}
+
+func (maxMind *MaxMind) Close() error {
+	return maxMind.reader.Close()
+}
+
+// Resolver prefers a country header set by a CDN or proxy in front of the
+// application, such as CF-IPCountry, and falls back to the Locator.
+type Resolver struct {
+	Header  string
+	Locator Locator
+}
+
+// Country returns the upper-cased country code or an empty string when it is
+// unknown.
+func (resolver Resolver) Country(r *http.Request, clientIP string) string {
+	if resolver.Header != "" {
+		if country := strings.TrimSpace(r.Header.Get(resolver.Header)); len(country) == 2 {
+			return strings.ToUpper(country)
+		}
+	}
+
+	if resolver.Locator == nil {
+		return ""
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return ""
+	}
+
+	country, err := resolver.Locator.Country(ip)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToUpper(country)
+}
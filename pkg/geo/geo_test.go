@@ -0,0 +1,36 @@
+package geo_test
+
+import (
+	"cc/pkg/geo"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+type locator map[string]string
+
+func (l locator) Country(ip net.IP) (string, error) {
+	if country, ok := l[ip.String()]; ok {
+		return country, nil
+	}
+
+	return "", errors.New("not found")
+}
+
+func TestResolver_Country(t *testing.T) {
+	resolver := geo.Resolver{Header: "CF-IPCountry", Locator: locator{"203.0.113.7": "de"}}
+
+	r := httptest.NewRequest("GET", "/abc", nil)
+	assert.Equal(t, "DE", resolver.Country(r, "203.0.113.7"))
+	assert.Equal(t, "", resolver.Country(r, "198.51.100.1"))
+
+	r.Header.Set("CF-IPCountry", "fr")
+	assert.Equal(t, "FR", resolver.Country(r, "203.0.113.7"))
+
+	r.Header.Set("CF-IPCountry", "XXX")
+	assert.Equal(t, "DE", resolver.Country(r, "203.0.113.7"))
+
+	assert.Equal(t, "", geo.Resolver{}.Country(r, "203.0.113.7"))
+}
@@ -0,0 +1,167 @@
+package healthcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bodyLimit caps how much of a GET response is read before the connection is
+// dropped; only the status matters.
+const bodyLimit = 64 << 10
+
+type Result struct {
+	StatusCode int
+	Latency    time.Duration
+	FinalURL   string
+	Error      string
+}
+
+func (result Result) Healthy() bool {
+	return result.Error == "" && result.StatusCode < http.StatusBadRequest
+}
+
+type Config struct {
+	// Concurrency is the number of hosts checked at the same time.
+	Concurrency int
+	// HostDelay is the pause between two requests to the same host.
+	HostDelay time.Duration
+	UserAgent string
+}
+
+type Checker struct {
+	client *http.Client
+	config Config
+}
+
+func New(client *http.Client, config Config) *Checker {
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+
+	return &Checker{client: client, config: config}
+}
+
+// Check requests rawURL with HEAD and falls back to GET when the server
+// rejects it, since many servers answer HEAD with 403, 404 or 405 even for
+// pages that exist. Redirects are followed by the client.
+func (checker *Checker) Check(ctx context.Context, rawURL string) Result {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	start := time.Now()
+
+	resp, err := checker.do(ctx, http.MethodHead, rawURL)
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		resp, err = checker.do(ctx, http.MethodGet, rawURL)
+	}
+
+	result := Result{Latency: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.StatusCode = resp.StatusCode
+	result.FinalURL = resp.Request.URL.String()
+
+	return result
+}
+
+// CheckAll checks every URL and returns the results in the same order. Up to
+// Concurrency hosts are checked in parallel while the URLs of a single host
+// are checked one after another, HostDelay apart.
+func (checker *Checker) CheckAll(ctx context.Context, urls []string) []Result {
+	results := make([]Result, len(urls))
+
+	var hosts []string
+	byHost := make(map[string][]int)
+	for i, rawURL := range urls {
+		host := hostOf(rawURL)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], i)
+	}
+
+	sem := make(chan struct{}, checker.config.Concurrency)
+
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		indexes := byHost[host]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for n, i := range indexes {
+				if n > 0 && !sleep(ctx, checker.config.HostDelay) {
+					results[i] = Result{Error: ctx.Err().Error()}
+					continue
+				}
+
+				results[i] = checker.Check(ctx, urls[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (checker *Checker) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if checker.config.UserAgent != "" {
+		req.Header.Set("User-Agent", checker.config.UserAgent)
+	}
+
+	resp, err := checker.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, bodyLimit))
+	_ = resp.Body.Close()
+
+	return resp, nil
+}
+
+func hostOf(rawURL string) string {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,95 @@
+package healthcheck_test
+
+import (
+	"cc/pkg/healthcheck"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChecker_Check(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checker := healthcheck.New(server.Client(), healthcheck.Config{Concurrency: 2})
+
+	result := checker.Check(context.Background(), server.URL+"/no-head")
+	assert.True(t, result.Healthy())
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+
+	result = checker.Check(context.Background(), server.URL+"/moved")
+	assert.True(t, result.Healthy())
+	assert.Equal(t, server.URL+"/ok", result.FinalURL)
+
+	result = checker.Check(context.Background(), server.URL+"/gone")
+	assert.False(t, result.Healthy())
+	assert.Equal(t, http.StatusGone, result.StatusCode)
+
+	result = checker.Check(context.Background(), "http://127.0.0.1:1/unreachable")
+	assert.False(t, result.Healthy())
+	assert.NotEmpty(t, result.Error)
+}
+
+func TestChecker_CheckAllHostDelay(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := healthcheck.New(server.Client(), healthcheck.Config{Concurrency: 4, HostDelay: 20 * time.Millisecond})
+
+	start := time.Now()
+	results := checker.CheckAll(context.Background(), []string{server.URL + "/a", server.URL + "/b", server.URL + "/c"})
+
+	assert.Len(t, results, 3)
+	for _, result := range results {
+		assert.True(t, result.Healthy())
+	}
+	assert.EqualValues(t, 1, maxInFlight)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result := healthcheck.New(healthcheck.NewClient(time.Second), healthcheck.Config{}).Check(context.Background(), server.URL)
+	assert.False(t, result.Healthy())
+	assert.Contains(t, result.Error, healthcheck.ErrForbiddenAddress.Error())
+}
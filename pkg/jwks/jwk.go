@@ -0,0 +1,97 @@
+package jwks
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type Set struct {
+	Keys []JWK `json:"keys"`
+}
+
+func NewJWK(key Key) (JWK, error) {
+	jwk := JWK{
+		Use: "sig",
+		Alg: key.Method.Alg(),
+		Kid: key.ID,
+	}
+
+	switch public := key.PublicKey().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = encodeInt(int64(public.E))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return jwk, ErrUnsupportedKeyType
+	}
+
+	return jwk, nil
+}
+
+func (jwk JWK) PublicKey() (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, ErrUnsupportedKeyType
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKeyType
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+}
+
+func (set Set) Find(kid string) (JWK, bool) {
+	for _, jwk := range set.Keys {
+		if jwk.Kid == kid {
+			return jwk, true
+		}
+	}
+
+	return JWK{}, false
+}
+
+func encodeInt(n int64) string {
+	return base64.RawURLEncoding.EncodeToString(big.NewInt(n).Bytes())
+}
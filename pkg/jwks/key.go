@@ -0,0 +1,179 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrInvalidPEM         = errors.New("jwks: invalid pem block")
+	ErrUnsupportedKeyType = errors.New("jwks: unsupported key type")
+)
+
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	signKey   any
+	verifyKey any
+}
+
+func NewHMACKey(secret []byte) Key {
+	sum := sha256.Sum256(secret)
+
+	return Key{
+		ID:        "hs-" + hex.EncodeToString(sum[:8]),
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+func NewPrivateKey(data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, ErrInvalidPEM
+	}
+
+	var private any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("%w: %s", ErrUnsupportedKeyType, block.Type)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return Key{}, ErrUnsupportedKeyType
+	}
+
+	key, err := newPublicKey(signer.Public())
+	if err != nil {
+		return Key{}, err
+	}
+
+	key.signKey = private
+
+	return key, nil
+}
+
+// NewPublicKey accepts a public key, a certificate or a private key, so the
+// previous signing key file can be reused as-is for verification.
+func NewPublicKey(data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, ErrInvalidPEM
+	}
+
+	var public any
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var certificate *x509.Certificate
+		certificate, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			public = certificate.PublicKey
+		}
+	case "RSA PRIVATE KEY", "PRIVATE KEY":
+		var key Key
+		key, err = NewPrivateKey(data)
+		if err != nil {
+			return Key{}, err
+		}
+
+		key.signKey = nil
+
+		return key, nil
+	default:
+		return Key{}, fmt.Errorf("%w: %s", ErrUnsupportedKeyType, block.Type)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	return newPublicKey(public)
+}
+
+func newPublicKey(public any) (Key, error) {
+	var method jwt.SigningMethod
+	switch public.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return Key{}, ErrUnsupportedKeyType
+	}
+
+	id, err := Thumbprint(public)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{
+		ID:        id,
+		Method:    method,
+		verifyKey: public,
+	}, nil
+}
+
+func (key Key) CanSign() bool {
+	return key.signKey != nil
+}
+
+func (key Key) Symmetric() bool {
+	_, ok := key.verifyKey.([]byte)
+
+	return ok
+}
+
+func (key Key) PublicKey() any {
+	if key.Symmetric() {
+		return nil
+	}
+
+	return key.verifyKey
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of the public key, which is used
+// as its kid.
+func Thumbprint(public any) (string, error) {
+	var canonical string
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`,
+			encodeInt(int64(public.E)),
+			base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+		)
+	case ed25519.PublicKey:
+		canonical = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`,
+			base64.RawURLEncoding.EncodeToString(public),
+		)
+	default:
+		return "", ErrUnsupportedKeyType
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
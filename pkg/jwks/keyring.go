@@ -0,0 +1,188 @@
+package jwks
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"os"
+)
+
+var (
+	ErrUnknownKey    = errors.New("jwks: unknown key id")
+	ErrMethodInvalid = errors.New("jwks: signing method does not match key")
+)
+
+type Keyring struct {
+	signing Key
+	keys    map[string]Key
+	order   []string
+}
+
+func NewKeyring(signing Key, verification ...Key) (*Keyring, error) {
+	if !signing.CanSign() {
+		return nil, errors.New("jwks: signing key has no private part")
+	}
+
+	keyring := &Keyring{
+		signing: signing,
+		keys:    make(map[string]Key),
+	}
+
+	for _, key := range append([]Key{signing}, verification...) {
+		if _, ok := keyring.keys[key.ID]; ok {
+			continue
+		}
+
+		keyring.keys[key.ID] = key
+		keyring.order = append(keyring.order, key.ID)
+	}
+
+	return keyring, nil
+}
+
+// Load builds a keyring from the auth configuration. With HS256 the secret
+// signs; otherwise the private key signs and the secret, if still set, is kept
+// for verification only so that tokens issued before the switch stay valid.
+func Load(method, secret, privateKeyFile string, publicKeyFiles []string) (*Keyring, error) {
+	var signing Key
+	var verification []Key
+
+	switch method {
+	case "", jwt.SigningMethodHS256.Alg():
+		if secret == "" {
+			return nil, errors.New("jwks: signing key is required for HS256")
+		}
+
+		signing = NewHMACKey([]byte(secret))
+	case jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg():
+		if privateKeyFile == "" {
+			return nil, fmt.Errorf("jwks: private key file is required for %s", method)
+		}
+
+		data, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		signing, err = NewPrivateKey(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if signing.Method.Alg() != method {
+			return nil, fmt.Errorf("%w: %s key for %s", ErrMethodInvalid, signing.Method.Alg(), method)
+		}
+
+		if secret != "" {
+			verification = append(verification, withoutPrivate(NewHMACKey([]byte(secret))))
+		}
+	default:
+		return nil, fmt.Errorf("jwks: unsupported signing method %s", method)
+	}
+
+	for _, file := range publicKeyFiles {
+		if file == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := NewPublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		verification = append(verification, key)
+	}
+
+	return NewKeyring(signing, verification...)
+}
+
+func withoutPrivate(key Key) Key {
+	key.signKey = nil
+
+	return key
+}
+
+func (keyring *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(keyring.signing.Method, claims)
+	token.Header["kid"] = keyring.signing.ID
+
+	return token.SignedString(keyring.signing.signKey)
+}
+
+func (keyring *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	var key Key
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		// Tokens issued before key ids were introduced are HMAC-signed.
+		var ok bool
+		key, ok = keyring.legacy()
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+	} else {
+		var ok bool
+		key, ok = keyring.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrMethodInvalid
+	}
+
+	return key.verifyKey, nil
+}
+
+func (keyring *Keyring) legacy() (Key, bool) {
+	for _, id := range keyring.order {
+		if key := keyring.keys[id]; key.Symmetric() {
+			return key, true
+		}
+	}
+
+	return Key{}, false
+}
+
+func (keyring *Keyring) Methods() []string {
+	seen := make(map[string]bool)
+
+	var methods []string
+	for _, id := range keyring.order {
+		alg := keyring.keys[id].Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+
+	return methods
+}
+
+// Set returns the public verification keys. Symmetric keys are never
+// published.
+func (keyring *Keyring) Set() Set {
+	set := Set{Keys: []JWK{}}
+
+	for _, id := range keyring.order {
+		key := keyring.keys[id]
+		if key.Symmetric() {
+			continue
+		}
+
+		jwk, err := NewJWK(key)
+		if err != nil {
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -0,0 +1,142 @@
+package jwks_test
+
+import (
+	"cc/pkg/jwks"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func rsaPEM(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func ed25519PEM(t *testing.T) []byte {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func claims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "user",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+
+func parse(keyring *jwks.Keyring, payload string) error {
+	_, err := jwt.ParseWithClaims(payload, &jwt.RegisteredClaims{}, keyring.Keyfunc,
+		jwt.WithValidMethods(keyring.Methods()),
+	)
+
+	return err
+}
+
+func TestKeyring_SignAndVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		pem    func(t *testing.T) []byte
+		method string
+		kty    string
+	}{
+		{name: "rsa", pem: rsaPEM, method: "RS256", kty: "RSA"},
+		{name: "ed25519", pem: ed25519PEM, method: "EdDSA", kty: "OKP"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := jwks.NewPrivateKey(test.pem(t))
+			require.NoError(t, err)
+			assert.Equal(t, test.method, key.Method.Alg())
+
+			keyring, err := jwks.NewKeyring(key)
+			require.NoError(t, err)
+
+			payload, err := keyring.Sign(claims())
+			require.NoError(t, err)
+			assert.NoError(t, parse(keyring, payload))
+
+			set := keyring.Set()
+			require.Len(t, set.Keys, 1)
+			assert.Equal(t, key.ID, set.Keys[0].Kid)
+			assert.Equal(t, test.kty, set.Keys[0].Kty)
+
+			public, err := set.Keys[0].PublicKey()
+			require.NoError(t, err)
+
+			thumbprint, err := jwks.Thumbprint(public)
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, thumbprint)
+		})
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	previousPEM := rsaPEM(t)
+
+	previous, err := jwks.NewPrivateKey(previousPEM)
+	require.NoError(t, err)
+
+	oldKeyring, err := jwks.NewKeyring(previous)
+	require.NoError(t, err)
+
+	payload, err := oldKeyring.Sign(claims())
+	require.NoError(t, err)
+
+	current, err := jwks.NewPrivateKey(ed25519PEM(t))
+	require.NoError(t, err)
+
+	verification, err := jwks.NewPublicKey(previousPEM)
+	require.NoError(t, err)
+	assert.False(t, verification.CanSign())
+
+	rotated, err := jwks.NewKeyring(current, verification)
+	require.NoError(t, err)
+	assert.NoError(t, parse(rotated, payload))
+	assert.Len(t, rotated.Set().Keys, 2)
+
+	withoutPrevious, err := jwks.NewKeyring(current)
+	require.NoError(t, err)
+	assert.Error(t, parse(withoutPrevious, payload))
+}
+
+func TestKeyring_LegacyHMAC(t *testing.T) {
+	secret := []byte("SIGNING_KEY")
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims()).SignedString(secret)
+	require.NoError(t, err)
+
+	keyring, err := jwks.NewKeyring(jwks.NewHMACKey(secret))
+	require.NoError(t, err)
+	assert.NoError(t, parse(keyring, legacy))
+	assert.Empty(t, keyring.Set().Keys)
+}
+
+func TestKeyring_RejectsAlgorithmConfusion(t *testing.T) {
+	key, err := jwks.NewPrivateKey(rsaPEM(t))
+	require.NoError(t, err)
+
+	keyring, err := jwks.NewKeyring(key)
+	require.NoError(t, err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	token.Header["kid"] = key.ID
+
+	forged, err := token.SignedString([]byte("guess"))
+	require.NoError(t, err)
+	assert.Error(t, parse(keyring, forged))
+}
@@ -0,0 +1,120 @@
+// Package lru implements a size-bounded in-memory cache whose entries also
+// expire after a fixed time. Expired entries stay available as stale values
+// until they are evicted.
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is safe for concurrent use. A zero size disables it: Get always misses
+// and Set does nothing.
+type Cache[K comparable, V any] struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List
+	items map[K]*list.Element
+	now   func() time.Time
+}
+
+func New[K comparable, V any](size int, ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		items: make(map[K]*list.Element),
+		now:   time.Now,
+	}
+}
+
+func (cache *Cache[K, V]) Get(key K) (value V, ok bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.items[key]
+	if !ok {
+		return value, false
+	}
+
+	// Expired entries are kept until evicted so that GetStale can still
+	// return them.
+	e := element.Value.(*entry[K, V])
+	if cache.now().After(e.expiresAt) {
+		return value, false
+	}
+
+	cache.order.MoveToFront(element)
+
+	return e.value, true
+}
+
+// GetStale returns the value for key even if it has expired.
+func (cache *Cache[K, V]) GetStale(key K) (value V, ok bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.items[key]
+	if !ok {
+		return value, false
+	}
+
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// Set stores value for key, evicting the least recently used entry when the
+// cache is full.
+func (cache *Cache[K, V]) Set(key K, value V) {
+	if cache.size <= 0 {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	expiresAt := cache.now().Add(cache.ttl)
+
+	if element, ok := cache.items[key]; ok {
+		e := element.Value.(*entry[K, V])
+		e.value, e.expiresAt = value, expiresAt
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	cache.items[key] = cache.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+
+	if cache.order.Len() > cache.size {
+		cache.remove(cache.order.Back())
+	}
+}
+
+func (cache *Cache[K, V]) Delete(keys ...K) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := cache.items[key]; ok {
+			cache.remove(element)
+		}
+	}
+}
+
+func (cache *Cache[K, V]) Len() int {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.order.Len()
+}
+
+func (cache *Cache[K, V]) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.items, element.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,71 @@
+package lru
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCache_Evicts(t *testing.T) {
+	cache := New[string, int](2, time.Minute)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	cache.Set("c", 3)
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok, "least recently used entry is evicted")
+
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestCache_Expires(t *testing.T) {
+	now := time.Date(2023, 7, 30, 0, 0, 0, 0, time.UTC)
+
+	cache := New[string, int](10, time.Second)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", 1)
+
+	now = now.Add(time.Second)
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Millisecond)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+
+	value, ok := cache.GetStale("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+}
+
+func TestCache_Delete(t *testing.T) {
+	cache := New[string, int](10, time.Minute)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Delete("a", "c")
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+
+	_, ok = cache.Get("b")
+	assert.True(t, ok)
+}
+
+func TestCache_Disabled(t *testing.T) {
+	cache := New[string, int](0, time.Minute)
+
+	cache.Set("a", 1)
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,217 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrDiscovery = errors.New("oidc: discovery failed")
+	ErrExchange  = errors.New("oidc: code exchange failed")
+	ErrIDToken   = errors.New("oidc: invalid id token")
+)
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Metadata is the subset of the discovery document the client relies on.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Provider talks to a single OpenID Connect issuer. The discovery document and
+// signing keys are fetched lazily on first use, so an unreachable identity
+// provider never prevents the application from starting.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     *keySet
+}
+
+func NewProvider(config Config, client *http.Client) *Provider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid"}
+	}
+
+	return &Provider{config: config, client: client}
+}
+
+func (provider *Provider) Metadata(ctx context.Context) (Metadata, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.metadata != nil {
+		return *provider.metadata, nil
+	}
+
+	var metadata Metadata
+	err := provider.getJSON(ctx, strings.TrimSuffix(provider.config.Issuer, "/")+"/.well-known/openid-configuration", &metadata)
+	if err != nil {
+		return metadata, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	if metadata.Issuer != provider.config.Issuer {
+		return metadata, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscovery, metadata.Issuer, provider.config.Issuer)
+	}
+
+	provider.metadata = &metadata
+	provider.keys = newKeySet(provider, metadata.JWKSURI)
+
+	return metadata, nil
+}
+
+// AuthCodeURL returns the authorization endpoint URL for the code flow with a
+// S256 PKCE challenge derived from verifier.
+func (provider *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	metadata, err := provider.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.config.ClientID},
+		"redirect_uri":          {provider.config.RedirectURL},
+		"scope":                 {strings.Join(provider.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {Challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and verifies the returned ID token
+// against nonce.
+func (provider *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (Claims, error) {
+	metadata, err := provider.Metadata(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {provider.config.RedirectURL},
+		"code_verifier": {verifier},
+	}
+
+	if provider.config.ClientSecret == "" {
+		form.Set("client_id", provider.config.ClientID)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	if provider.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(provider.config.ClientID), url.QueryEscape(provider.config.ClientSecret))
+	}
+
+	response, err := provider.client.Do(request)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return Claims{}, fmt.Errorf("%w: %s: %s", ErrExchange, response.Status, body)
+	}
+
+	var token Token
+	if err = json.Unmarshal(body, &token); err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	if token.IDToken == "" {
+		return Claims{}, fmt.Errorf("%w: response has no id_token", ErrExchange)
+	}
+
+	return provider.Verify(ctx, token.IDToken, nonce)
+}
+
+func (provider *Provider) getJSON(ctx context.Context, endpoint string, v any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := provider.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", endpoint, response.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(v)
+}
+
+// RandomString returns a URL-safe random string suitable for state, nonce
+// and PKCE verifier values.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Challenge derives the S256 PKCE code challenge from verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,84 @@
+package oidc_test
+
+import (
+	"cc/pkg/oidc"
+	"cc/pkg/oidc/oidctest"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func provider(idp *oidctest.Server) *oidc.Provider {
+	return oidc.NewProvider(oidc.Config{
+		Issuer:       idp.Issuer(),
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://localhost/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+	}, idp.Client())
+}
+
+func authorize(t *testing.T, idp *oidctest.Server, p *oidc.Provider, verifier, nonce string) string {
+	authCodeURL, err := p.AuthCodeURL(context.Background(), "state", nonce, verifier)
+	require.NoError(t, err)
+
+	code, state, err := idp.Authorize(authCodeURL)
+	require.NoError(t, err)
+	require.Equal(t, "state", state)
+
+	return code
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	idp := oidctest.NewServer("cc", "secret")
+	defer idp.Close()
+
+	idp.SetUser(oidctest.User{Subject: "42", Email: "alice@example.com", PreferredUsername: "alice"})
+
+	p := provider(idp)
+	code := authorize(t, idp, p, "verifier", "nonce")
+
+	claims, err := p.Exchange(context.Background(), code, "verifier", "nonce")
+	require.NoError(t, err)
+	assert.Equal(t, "42", claims.Subject)
+	assert.Equal(t, idp.Issuer(), claims.Issuer)
+	assert.Equal(t, "alice@example.com", claims.Email)
+	assert.Equal(t, "alice", claims.PreferredUsername)
+}
+
+func TestProvider_ExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := oidctest.NewServer("cc", "secret")
+	defer idp.Close()
+
+	p := provider(idp)
+	code := authorize(t, idp, p, "verifier", "nonce")
+
+	_, err := p.Exchange(context.Background(), code, "other", "nonce")
+	assert.ErrorIs(t, err, oidc.ErrExchange)
+}
+
+func TestProvider_ExchangeRejectsWrongNonce(t *testing.T) {
+	idp := oidctest.NewServer("cc", "secret")
+	defer idp.Close()
+
+	p := provider(idp)
+	code := authorize(t, idp, p, "verifier", "nonce")
+
+	_, err := p.Exchange(context.Background(), code, "verifier", "other")
+	assert.ErrorIs(t, err, oidc.ErrIDToken)
+}
+
+func TestProvider_ExchangeRejectsReusedCode(t *testing.T) {
+	idp := oidctest.NewServer("cc", "secret")
+	defer idp.Close()
+
+	p := provider(idp)
+	code := authorize(t, idp, p, "verifier", "nonce")
+
+	_, err := p.Exchange(context.Background(), code, "verifier", "nonce")
+	require.NoError(t, err)
+
+	_, err = p.Exchange(context.Background(), code, "verifier", "nonce")
+	assert.ErrorIs(t, err, oidc.ErrExchange)
+}
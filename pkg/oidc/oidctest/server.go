@@ -0,0 +1,252 @@
+// Package oidctest provides an in-process OpenID Connect identity provider
+// for tests. It implements discovery, the authorization code flow with PKCE,
+// and publishes its signing key as a JWKS.
+package oidctest
+
+import (
+	"cc/pkg/jwks"
+	"cc/pkg/oidc"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type User struct {
+	Subject           string
+	Email             string
+	Name              string
+	PreferredUsername string
+}
+
+type grant struct {
+	challenge   string
+	nonce       string
+	redirectURI string
+	user        User
+}
+
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+
+	keyring *jwks.Keyring
+
+	mu     sync.Mutex
+	user   User
+	grants map[string]grant
+}
+
+// NewServer starts an identity provider that accepts a single client. Every
+// authorization request is approved for the current user without a login
+// page.
+func NewServer(clientID, clientSecret string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	signing, err := jwks.NewPrivateKey(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+	if err != nil {
+		panic(err)
+	}
+
+	keyring, err := jwks.NewKeyring(signing)
+	if err != nil {
+		panic(err)
+	}
+
+	server := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		keyring:      keyring,
+		user:         User{Subject: "subject", Email: "user@example.com", Name: "User", PreferredUsername: "user"},
+		grants:       make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", server.discovery)
+	mux.HandleFunc("/authorize", server.authorize)
+	mux.HandleFunc("/token", server.token)
+	mux.HandleFunc("/jwks", server.jwks)
+
+	server.Server = httptest.NewServer(mux)
+
+	return server
+}
+
+// Issuer is the issuer identifier to configure the client with.
+func (server *Server) Issuer() string {
+	return server.URL
+}
+
+// SetUser changes who the next authorization requests are approved for.
+func (server *Server) SetUser(user User) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.user = user
+}
+
+// Authorize follows an authorization URL produced by oidc.Provider and returns
+// the code and state the provider would redirect the browser with.
+func (server *Server) Authorize(authCodeURL string) (code, state string, err error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Get(authCodeURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer response.Body.Close()
+
+	location, err := response.Location()
+	if err != nil {
+		return "", "", err
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (server *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.Metadata{
+		Issuer:                server.URL,
+		AuthorizationEndpoint: server.URL + "/authorize",
+		TokenEndpoint:         server.URL + "/token",
+		JWKSURI:               server.URL + "/jwks",
+	})
+}
+
+func (server *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, server.keyring.Set())
+}
+
+func (server *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("client_id") != server.ClientID || query.Get("response_type") != "code" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "pkce is required", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code, err := oidc.RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.mu.Lock()
+	server.grants[code] = grant{
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		redirectURI: query.Get("redirect_uri"),
+		user:        server.user,
+	}
+	server.mu.Unlock()
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirectURI.RawQuery = values.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (server *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+	}
+
+	if clientID != server.ClientID || clientSecret != server.ClientSecret {
+		tokenError(w, "invalid_client")
+		return
+	}
+
+	server.mu.Lock()
+	grnt, ok := server.grants[r.PostForm.Get("code")]
+	delete(server.grants, r.PostForm.Get("code"))
+	server.mu.Unlock()
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != grnt.redirectURI {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	if oidc.Challenge(r.PostForm.Get("code_verifier")) != grnt.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+
+	idToken, err := server.keyring.Sign(oidc.Claims{
+		Nonce:             grnt.nonce,
+		Email:             grnt.user.Email,
+		EmailVerified:     grnt.user.Email != "",
+		Name:              grnt.user.Name,
+		PreferredUsername: grnt.user.PreferredUsername,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    server.URL,
+			Subject:   grnt.user.Subject,
+			Audience:  jwt.ClaimStrings{server.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	})
+	if err != nil {
+		tokenError(w, "server_error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, oidc.Token{
+		AccessToken: "access-token",
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		ExpiresIn:   300,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,104 @@
+package oidc
+
+import (
+	"cc/pkg/jwks"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"sync"
+)
+
+type Claims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+// Verify checks the signature, issuer, audience, expiry and nonce of an ID
+// token.
+func (provider *Provider) Verify(ctx context.Context, idToken, nonce string) (Claims, error) {
+	var claims Claims
+
+	_, err := provider.Metadata(ctx)
+	if err != nil {
+		return claims, err
+	}
+
+	_, err = jwt.ParseWithClaims(idToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return provider.keys.find(ctx, kid)
+	}, jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}))
+	if err != nil {
+		return claims, fmt.Errorf("%w: %v", ErrIDToken, err)
+	}
+
+	if claims.Issuer != provider.config.Issuer {
+		return claims, fmt.Errorf("%w: unexpected issuer %q", ErrIDToken, claims.Issuer)
+	}
+
+	if !claims.VerifyAudience(provider.config.ClientID, true) {
+		return claims, fmt.Errorf("%w: client is not in audience", ErrIDToken)
+	}
+
+	if claims.Subject == "" {
+		return claims, fmt.Errorf("%w: missing subject", ErrIDToken)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return claims, fmt.Errorf("%w: nonce mismatch", ErrIDToken)
+	}
+
+	return claims, nil
+}
+
+type keySet struct {
+	provider *Provider
+	uri      string
+
+	mu  sync.Mutex
+	set jwks.Set
+}
+
+func newKeySet(provider *Provider, uri string) *keySet {
+	return &keySet{provider: provider, uri: uri}
+}
+
+// find looks kid up in the cached key set and refetches it once when the key
+// is unknown, which is how providers roll their signing keys.
+func (keys *keySet) find(ctx context.Context, kid string) (any, error) {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+
+	if jwk, ok := keys.lookup(kid); ok {
+		return jwk.PublicKey()
+	}
+
+	var set jwks.Set
+	if err := keys.provider.getJSON(ctx, keys.uri, &set); err != nil {
+		return nil, err
+	}
+
+	keys.set = set
+
+	if jwk, ok := keys.lookup(kid); ok {
+		return jwk.PublicKey()
+	}
+
+	return nil, jwks.ErrUnknownKey
+}
+
+func (keys *keySet) lookup(kid string) (jwks.JWK, bool) {
+	if kid == "" && len(keys.set.Keys) == 1 {
+		return keys.set.Keys[0], true
+	}
+
+	return keys.set.Find(kid)
+}
@@ -29,12 +29,37 @@ type Client interface {
 	QueryRow(ctx context.Context, query string, args ...any) pgx.Row
 	Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error
 	Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Ping(ctx context.Context) error
+	// InTx runs fn in a transaction that is committed when fn succeeds. Calls
+	// made with the context passed to fn take part in it, and nested calls
+	// join the outer transaction.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// Close waits for acquired connections to be released and closes the pool.
+	Close()
 }
 
 type client struct {
 	pool *pgxpool.Pool
 }
 
+type txKey struct{}
+
+// querier is what both the pool and a transaction can run queries with.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// conn returns the transaction of ctx, if any, or the pool.
+func (c *client) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return c.pool
+}
+
 func NewClient(ctx context.Context, cfg Config) (Client, error) {
 	config, err := pgxpool.ParseConfig(cfg.String())
 	if err != nil {
@@ -59,21 +84,47 @@ func NewClient(ctx context.Context, cfg Config) (Client, error) {
 }
 
 func (c *client) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
-	return c.pool.Exec(ctx, query, args...)
+	return c.conn(ctx).Exec(ctx, query, args...)
 }
 
 func (c *client) Get(ctx context.Context, dest interface{}, query string, args ...any) error {
-	return pgxscan.Get(ctx, c.pool, dest, query, args...)
+	return pgxscan.Get(ctx, c.conn(ctx), dest, query, args...)
 }
 
 func (c *client) Select(ctx context.Context, dest interface{}, query string, args ...any) error {
-	return pgxscan.Select(ctx, c.pool, dest, query, args...)
+	return pgxscan.Select(ctx, c.conn(ctx), dest, query, args...)
 }
 
 func (c *client) Query(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
-	return c.pool.Query(ctx, query, args...)
+	return c.conn(ctx).Query(ctx, query, args...)
+}
+
+func (c *client) Ping(ctx context.Context) error {
+	return c.pool.Ping(ctx)
+}
+
+func (c *client) Close() {
+	c.pool.Close()
 }
 
 func (c *client) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
-	return c.pool.QueryRow(ctx, query, args...)
+	return c.conn(ctx).QueryRow(ctx, query, args...)
+}
+
+func (c *client) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
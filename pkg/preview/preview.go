@@ -0,0 +1,227 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrNotHTML = errors.New("destination is not an html page")
+
+const (
+	maxTextLength = 300
+	maxURLLength  = 2048
+)
+
+type Metadata struct {
+	Title       string
+	Description string
+	OGTitle     string
+	OGImage     string
+	Favicon     string
+}
+
+// PageTitle returns the title a person would expect for the page.
+func (metadata Metadata) PageTitle() string {
+	if metadata.OGTitle != "" {
+		return metadata.OGTitle
+	}
+
+	return metadata.Title
+}
+
+type Config struct {
+	// MaxBytes is how much of the page is read; metadata lives in <head>, so
+	// the rest is never needed.
+	MaxBytes  int64
+	UserAgent string
+}
+
+type Fetcher struct {
+	client *http.Client
+	config Config
+}
+
+// New creates a Fetcher. The time budget is the client's timeout and any
+// deadline on the context passed to Fetch.
+func New(client *http.Client, config Config) *Fetcher {
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 512 << 10
+	}
+
+	return &Fetcher{client: client, config: config}
+}
+
+func (fetcher *Fetcher) Fetch(ctx context.Context, rawURL string) (metadata Metadata, err error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	if fetcher.config.UserAgent != "" {
+		req.Header.Set("User-Agent", fetcher.config.UserAgent)
+	}
+
+	resp, err := fetcher.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return metadata, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return metadata, ErrNotHTML
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, fetcher.config.MaxBytes), contentType)
+	if err != nil {
+		return
+	}
+
+	return Parse(body, resp.Request.URL), nil
+}
+
+// Parse extracts metadata from the <head> of an HTML document. Relative image
+// and icon URLs are resolved against base.
+func Parse(r io.Reader, base *url.URL) Metadata {
+	var metadata Metadata
+
+	tokenizer := html.NewTokenizer(r)
+
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return metadata.finish(base)
+		case html.TextToken:
+			if inTitle && metadata.Title == "" {
+				metadata.Title = string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return metadata.finish(base)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = true
+			case "body":
+				return metadata.finish(base)
+			case "meta", "link":
+				attrs := make(map[string]string)
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = tokenizer.TagAttr()
+					attrs[string(key)] = string(val)
+				}
+
+				metadata.apply(string(name), attrs)
+			}
+		}
+	}
+}
+
+func (metadata *Metadata) apply(tag string, attrs map[string]string) {
+	if tag == "link" {
+		for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+			if rel == "icon" && metadata.Favicon == "" {
+				metadata.Favicon = attrs["href"]
+			}
+		}
+
+		return
+	}
+
+	content := attrs["content"]
+	switch strings.ToLower(attrs["name"]) {
+	case "description":
+		if metadata.Description == "" {
+			metadata.Description = content
+		}
+	}
+
+	switch strings.ToLower(attrs["property"]) {
+	case "og:title":
+		if metadata.OGTitle == "" {
+			metadata.OGTitle = content
+		}
+	case "og:image", "og:image:url", "og:image:secure_url":
+		if metadata.OGImage == "" {
+			metadata.OGImage = content
+		}
+	case "og:description":
+		if metadata.Description == "" {
+			metadata.Description = content
+		}
+	}
+}
+
+func (metadata Metadata) finish(base *url.URL) Metadata {
+	metadata.Title = clean(metadata.Title, maxTextLength)
+	metadata.Description = clean(metadata.Description, maxTextLength)
+	metadata.OGTitle = clean(metadata.OGTitle, maxTextLength)
+
+	if metadata.Favicon == "" {
+		metadata.Favicon = "/favicon.ico"
+	}
+
+	metadata.OGImage = resolve(base, metadata.OGImage)
+	metadata.Favicon = resolve(base, metadata.Favicon)
+
+	return metadata
+}
+
+func clean(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+
+	if utf8.RuneCountInString(s) > limit {
+		s = string([]rune(s)[:limit])
+	}
+
+	return s
+}
+
+// resolve returns ref as an absolute http(s) URL or an empty string.
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || base == nil {
+		return ""
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	resolved := u.String()
+	if len(resolved) > maxURLLength {
+		return ""
+	}
+
+	return resolved
+}
@@ -0,0 +1,78 @@
+package preview_test
+
+import (
+	"cc/pkg/preview"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>
+    Example   Domain
+  </title>
+  <meta name="description" content="An example page.">
+  <meta property="og:title" content="Example">
+  <meta property="og:image" content="/images/card.png">
+  <link rel="shortcut icon" href="https://cdn.example.com/icon.png">
+</head>
+<body>
+  <meta name="description" content="ignored">
+</body>
+</html>`
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/articles/1")
+
+	metadata := preview.Parse(strings.NewReader(page), base)
+
+	assert.Equal(t, preview.Metadata{
+		Title:       "Example Domain",
+		Description: "An example page.",
+		OGTitle:     "Example",
+		OGImage:     "https://example.com/images/card.png",
+		Favicon:     "https://cdn.example.com/icon.png",
+	}, metadata)
+	assert.Equal(t, "Example", metadata.PageTitle())
+}
+
+func TestParseDefaults(t *testing.T) {
+	base, _ := url.Parse("https://example.com/a")
+
+	metadata := preview.Parse(strings.NewReader(`<html><head><meta property="og:image" content="javascript:alert(1)"></head></html>`), base)
+
+	assert.Empty(t, metadata.OGImage)
+	assert.Equal(t, "https://example.com/favicon.ico", metadata.Favicon)
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(page))
+		case "/file":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF"))
+		}
+	}))
+	defer server.Close()
+
+	fetcher := preview.New(server.Client(), preview.Config{MaxBytes: 1 << 10})
+
+	metadata, err := fetcher.Fetch(context.Background(), server.URL+"/page")
+	require.NoError(t, err)
+	assert.Equal(t, "Example Domain", metadata.Title)
+	assert.Equal(t, server.URL+"/images/card.png", metadata.OGImage)
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/file")
+	assert.ErrorIs(t, err, preview.ErrNotHTML)
+}
@@ -0,0 +1,97 @@
+// Package probe reports the state of the services an instance depends on for
+// liveness and readiness endpoints.
+package probe
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFailing  = "failing"
+)
+
+// Check pings one dependency. The instance cannot serve without a Required
+// dependency; losing any other one only degrades it.
+type Check struct {
+	Name     string
+	Required bool
+	Ping     func(ctx context.Context) error
+	// Fallback, if set, reports whether the instance can still serve while
+	// the dependency is down, e.g. from a warm cache. Losing a Required
+	// dependency then only degrades it.
+	Fallback func() bool
+}
+
+type Dependency struct {
+	Status    string `json:"status"`
+	Required  bool   `json:"required"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status       string                `json:"status"`
+	Dependencies map[string]Dependency `json:"dependencies"`
+}
+
+// Ready reports whether every required dependency is up.
+func (report Report) Ready() bool {
+	return report.Status != StatusFailing
+}
+
+// Run pings all dependencies in parallel, giving each at most timeout.
+func Run(ctx context.Context, timeout time.Duration, checks ...Check) Report {
+	report := Report{Status: StatusOK, Dependencies: make(map[string]Dependency, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			dependency := ping(ctx, timeout, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Dependencies[check.Name] = dependency
+			if dependency.Status == StatusDown {
+				if check.Required && (check.Fallback == nil || !check.Fallback()) {
+					report.Status = StatusFailing
+				} else if report.Status == StatusOK {
+					report.Status = StatusDegraded
+				}
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func ping(ctx context.Context, timeout time.Duration, check Check) Dependency {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Ping(ctx)
+
+	dependency := Dependency{
+		Status:    StatusUp,
+		Required:  check.Required,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		dependency.Status = StatusDown
+		dependency.Error = err.Error()
+	}
+
+	return dependency
+}
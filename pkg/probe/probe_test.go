@@ -0,0 +1,70 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		postgres func(context.Context) error
+		redis    func(context.Context) error
+		status   string
+		ready    bool
+	}{
+		{"all up", up, up, StatusOK, true},
+		{"optional down", up, down, StatusDegraded, true},
+		{"required down", down, up, StatusFailing, false},
+		{"all down", down, down, StatusFailing, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Run(context.Background(), time.Second,
+				Check{Name: "postgres", Required: true, Ping: test.postgres},
+				Check{Name: "redis", Ping: test.redis},
+			)
+
+			assert.Equal(t, test.status, report.Status)
+			assert.Equal(t, test.ready, report.Ready())
+			assert.Len(t, report.Dependencies, 2)
+			assert.True(t, report.Dependencies["postgres"].Required)
+		})
+	}
+}
+
+func TestRun_Fallback(t *testing.T) {
+	warm := false
+	check := Check{Name: "postgres", Required: true, Ping: down, Fallback: func() bool { return warm }}
+
+	report := Run(context.Background(), time.Second, check)
+	assert.Equal(t, StatusFailing, report.Status)
+	assert.False(t, report.Ready())
+
+	warm = true
+	report = Run(context.Background(), time.Second, check)
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.True(t, report.Ready())
+	assert.Equal(t, StatusDown, report.Dependencies["postgres"].Status)
+}
+
+func TestRun_Timeout(t *testing.T) {
+	report := Run(context.Background(), 10*time.Millisecond, Check{
+		Name: "slow",
+		Ping: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	assert.Equal(t, StatusDown, report.Dependencies["slow"].Status)
+	assert.Equal(t, StatusDegraded, report.Status)
+}
@@ -0,0 +1,218 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+var ErrColor = errors.New("color must be a hex value such as 1a2b3c")
+
+// logoRatio is the share of the code's width covered by a logo. Level H
+// restores up to 30% of the modules, so a centered square of this size keeps
+// the code readable.
+const logoRatio = 0.22
+
+type Level string
+
+const (
+	LevelL Level = "L"
+	LevelM Level = "M"
+	LevelQ Level = "Q"
+	LevelH Level = "H"
+)
+
+func (level Level) recovery() (qrcode.RecoveryLevel, bool) {
+	switch level {
+	case LevelL:
+		return qrcode.Low, true
+	case LevelM:
+		return qrcode.Medium, true
+	case LevelQ:
+		return qrcode.High, true
+	case LevelH:
+		return qrcode.Highest, true
+	default:
+		return 0, false
+	}
+}
+
+func (level Level) Valid() bool {
+	_, ok := level.recovery()
+	return ok
+}
+
+type Options struct {
+	// Size is the width and height of the image in pixels.
+	Size int
+	// Margin is the quiet zone around the code in modules.
+	Margin     int
+	Level      Level
+	Foreground color.RGBA
+	Background color.RGBA
+	// Logo is drawn in the center when set.
+	Logo image.Image
+}
+
+type code struct {
+	modules [][]bool
+	total   int
+	margin  int
+}
+
+func encode(content string, options Options) (code, error) {
+	level, ok := options.Level.recovery()
+	if !ok {
+		return code{}, fmt.Errorf("unknown error correction level %q", options.Level)
+	}
+
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		return code{}, err
+	}
+	q.DisableBorder = true
+
+	modules := q.Bitmap()
+
+	return code{modules: modules, total: len(modules) + 2*options.Margin, margin: options.Margin}, nil
+}
+
+func (c code) dark(x, y int) bool {
+	x, y = x-c.margin, y-c.margin
+	if x < 0 || y < 0 || y >= len(c.modules) || x >= len(c.modules) {
+		return false
+	}
+
+	return c.modules[y][x]
+}
+
+func PNG(content string, options Options) ([]byte, error) {
+	c, err := encode(content, options)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, options.Size, options.Size))
+	for py := 0; py < options.Size; py++ {
+		y := py * c.total / options.Size
+		for px := 0; px < options.Size; px++ {
+			if c.dark(px*c.total/options.Size, y) {
+				img.SetRGBA(px, py, options.Foreground)
+			} else {
+				img.SetRGBA(px, py, options.Background)
+			}
+		}
+	}
+
+	if options.Logo != nil {
+		side := int(float64(options.Size) * logoRatio)
+		offset := (options.Size - side) / 2
+		pad := side / 10
+
+		draw.Draw(img, image.Rect(offset-pad, offset-pad, offset+side+pad, offset+side+pad), image.NewUniform(options.Background), image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(img, fit(options.Logo.Bounds(), image.Rect(offset, offset, offset+side, offset+side)), options.Logo, options.Logo.Bounds(), draw.Over, nil)
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func SVG(content string, options Options) ([]byte, error) {
+	c, err := encode(content, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		options.Size, options.Size, c.total, c.total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, c.total, c.total, hex(options.Background))
+
+	buf.WriteString(`<path fill="` + hex(options.Foreground) + `" d="`)
+	for y := 0; y < c.total; y++ {
+		for x := 0; x < c.total; x++ {
+			if c.dark(x, y) {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if options.Logo != nil {
+		var logo bytes.Buffer
+		if err = png.Encode(&logo, options.Logo); err != nil {
+			return nil, err
+		}
+
+		side := float64(c.total) * logoRatio
+		offset := (float64(c.total) - side) / 2
+		pad := side / 10
+
+		fmt.Fprintf(&buf, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`,
+			offset-pad, offset-pad, side+2*pad, side+2*pad, hex(options.Background))
+		fmt.Fprintf(&buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`,
+			offset, offset, side, side, base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	buf.WriteString(`</svg>`)
+
+	return buf.Bytes(), nil
+}
+
+// ParseColor parses a 3 or 6 digit hex color with an optional leading #.
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+
+	if len(s) != 6 {
+		return color.RGBA{}, ErrColor
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, ErrColor
+	}
+
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// fit returns the largest rectangle with the aspect ratio of src centered in
+// dst.
+func fit(src, dst image.Rectangle) image.Rectangle {
+	sw, sh := src.Dx(), src.Dy()
+	dw, dh := dst.Dx(), dst.Dy()
+	if sw == 0 || sh == 0 {
+		return dst
+	}
+
+	w, h := dw, dh
+	if sw*dh > sh*dw {
+		h = sh * dw / sw
+	} else {
+		w = sw * dh / sh
+	}
+
+	x := dst.Min.X + (dw-w)/2
+	y := dst.Min.Y + (dh-h)/2
+
+	return image.Rect(x, y, x+w, y+h)
+}
@@ -0,0 +1,66 @@
+package qr_test
+
+import (
+	"bytes"
+	"cc/pkg/qr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+var (
+	black = color.RGBA{A: 0xff}
+	white = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+func TestPNG(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 10, 10))
+
+	data, err := qr.PNG("https://cc.example/abc?src=qr", qr.Options{
+		Size:       256,
+		Margin:     4,
+		Level:      qr.LevelH,
+		Foreground: black,
+		Background: white,
+		Logo:       logo,
+	})
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, image.Rect(0, 0, 256, 256), img.Bounds())
+	assert.Equal(t, white, color.RGBAModel.Convert(img.At(0, 0)))
+}
+
+func TestSVG(t *testing.T) {
+	data, err := qr.SVG("https://cc.example/abc", qr.Options{
+		Size:       300,
+		Level:      qr.LevelM,
+		Foreground: color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff},
+		Background: white,
+	})
+	require.NoError(t, err)
+
+	svg := string(data)
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300"`))
+	assert.Contains(t, svg, `fill="#112233"`)
+	assert.NotContains(t, svg, "<image")
+}
+
+func TestParseColor(t *testing.T) {
+	c, err := qr.ParseColor("#1a2B3c")
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff}, c)
+
+	c, err = qr.ParseColor("fff")
+	require.NoError(t, err)
+	assert.Equal(t, white, c)
+
+	_, err = qr.ParseColor("red")
+	assert.ErrorIs(t, err, qr.ErrColor)
+}
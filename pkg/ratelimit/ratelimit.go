@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/go-redis/redis/v9"
+	"time"
+)
+
+var hit = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {count, redis.call('PTTL', KEYS[1])}
+`)
+
+type Limiter struct {
+	client *redis.Client
+	prefix string
+}
+
+func New(client *redis.Client, prefix string) *Limiter {
+	return &Limiter{client: client, prefix: prefix}
+}
+
+// Hit increments the fixed-window counter for key and returns the number of
+// hits in the current window together with the time left until it resets.
+func (limiter *Limiter) Hit(ctx context.Context, key string, window time.Duration) (count int64, reset time.Duration, err error) {
+	var result []int64
+	result, err = hit.Run(ctx, limiter.client, []string{limiter.prefix + key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return
+	}
+
+	return result[0], time.Duration(result[1]) * time.Millisecond, nil
+}
+
+func (limiter *Limiter) Lock(ctx context.Context, key string, duration time.Duration) error {
+	return limiter.client.Set(ctx, limiter.prefix+key, 1, duration).Err()
+}
+
+// Locked returns how long key stays locked, or zero if it is not locked.
+func (limiter *Limiter) Locked(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := limiter.client.PTTL(ctx, limiter.prefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (limiter *Limiter) Reset(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = limiter.prefix + key
+	}
+
+	return limiter.client.Del(ctx, prefixed...).Err()
+}
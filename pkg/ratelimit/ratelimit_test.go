@@ -0,0 +1,85 @@
+package ratelimit_test
+
+import (
+	"cc/pkg/ratelimit"
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newLimiter(t *testing.T) (*ratelimit.Limiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return ratelimit.New(client, "test:"), server
+}
+
+func TestLimiter_Hit(t *testing.T) {
+	ctx := context.Background()
+	limiter, server := newLimiter(t)
+
+	for i := int64(1); i <= 3; i++ {
+		count, reset, err := limiter.Hit(ctx, "key", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, i, count)
+		assert.Equal(t, time.Minute, reset)
+	}
+
+	// Later hits must not push the window out.
+	server.FastForward(40 * time.Second)
+	count, reset, err := limiter.Hit(ctx, "key", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+	assert.Equal(t, 20*time.Second, reset)
+
+	server.FastForward(20 * time.Second)
+	count, reset, err = limiter.Hit(ctx, "key", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, time.Minute, reset)
+
+	assert.True(t, server.Exists("test:key"))
+}
+
+func TestLimiter_Lock(t *testing.T) {
+	ctx := context.Background()
+	limiter, server := newLimiter(t)
+
+	locked, err := limiter.Locked(ctx, "key")
+	assert.NoError(t, err)
+	assert.Zero(t, locked)
+
+	assert.NoError(t, limiter.Lock(ctx, "key", time.Minute))
+
+	locked, err = limiter.Locked(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, locked)
+
+	server.FastForward(time.Minute)
+	locked, err = limiter.Locked(ctx, "key")
+	assert.NoError(t, err)
+	assert.Zero(t, locked)
+}
+
+func TestLimiter_Reset(t *testing.T) {
+	ctx := context.Background()
+	limiter, _ := newLimiter(t)
+
+	_, _, err := limiter.Hit(ctx, "a", time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, limiter.Lock(ctx, "b", time.Minute))
+
+	assert.NoError(t, limiter.Reset(ctx, "a", "b"))
+
+	count, _, err := limiter.Hit(ctx, "a", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	locked, err := limiter.Locked(ctx, "b")
+	assert.NoError(t, err)
+	assert.Zero(t, locked)
+}
@@ -0,0 +1,108 @@
+package screening
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Blocklist flags URLs whose host is a listed domain or one of its subdomains.
+type Blocklist struct {
+	path string
+
+	mu      sync.RWMutex
+	domains map[string]struct{}
+}
+
+func NewBlocklist(domains ...string) *Blocklist {
+	blocklist := &Blocklist{}
+	blocklist.set(domains)
+
+	return blocklist
+}
+
+// LoadBlocklist reads one domain per line from path. Blank lines and lines
+// starting with # are ignored.
+func LoadBlocklist(path string) (*Blocklist, error) {
+	blocklist := &Blocklist{path: path}
+
+	return blocklist, blocklist.Reload()
+}
+
+// Reload re-reads the file the blocklist was loaded from, so edits are picked
+// up by the next scan without a restart.
+func (blocklist *Blocklist) Reload() error {
+	if blocklist.path == "" {
+		return nil
+	}
+
+	file, err := os.Open(blocklist.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var domains []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		domains = append(domains, line)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	blocklist.set(domains)
+
+	return nil
+}
+
+func (blocklist *Blocklist) set(domains []string) {
+	set := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		set[strings.TrimSuffix(strings.ToLower(domain), ".")] = struct{}{}
+	}
+
+	blocklist.mu.Lock()
+	blocklist.domains = set
+	blocklist.mu.Unlock()
+}
+
+func (blocklist *Blocklist) Screen(_ context.Context, urls []string) ([]Verdict, error) {
+	blocklist.mu.RLock()
+	defer blocklist.mu.RUnlock()
+
+	verdicts := make([]Verdict, len(urls))
+	for i, url := range urls {
+		if blocklist.blocked(Host(url)) {
+			verdicts[i] = Verdict{Threat: "BLOCKLISTED", Source: "blocklist"}
+		}
+	}
+
+	return verdicts, nil
+}
+
+func (blocklist *Blocklist) blocked(host string) bool {
+	for host != "" {
+		if _, ok := blocklist.domains[host]; ok {
+			return true
+		}
+
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return false
+		}
+
+		host = host[i+1:]
+	}
+
+	return false
+}
@@ -0,0 +1,142 @@
+package screening
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const SafeBrowsingEndpoint = "https://safebrowsing.googleapis.com"
+
+// safeBrowsingBatch is the most entries the Lookup API accepts per request.
+const safeBrowsingBatch = 500
+
+var ErrSafeBrowsing = errors.New("safe browsing lookup failed")
+
+// SafeBrowsing is a client for the Safe Browsing v4 Lookup API. Any server
+// implementing threatMatches:find can be used by changing the endpoint.
+type SafeBrowsing struct {
+	endpoint string
+	key      string
+	clientID string
+	client   *http.Client
+}
+
+func NewSafeBrowsing(endpoint, key, clientID string, client *http.Client) *SafeBrowsing {
+	if endpoint == "" {
+		endpoint = SafeBrowsingEndpoint
+	}
+
+	return &SafeBrowsing{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		key:      key,
+		clientID: clientID,
+		client:   client,
+	}
+}
+
+type threatEntry struct {
+	URL string `json:"url"`
+}
+
+type findRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string      `json:"threatTypes"`
+		PlatformTypes    []string      `json:"platformTypes"`
+		ThreatEntryTypes []string      `json:"threatEntryTypes"`
+		ThreatEntries    []threatEntry `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+type findResponse struct {
+	Matches []struct {
+		ThreatType string      `json:"threatType"`
+		Threat     threatEntry `json:"threat"`
+	} `json:"matches"`
+}
+
+func (safeBrowsing *SafeBrowsing) Screen(ctx context.Context, urls []string) ([]Verdict, error) {
+	verdicts := make([]Verdict, 0, len(urls))
+
+	for start := 0; start < len(urls); start += safeBrowsingBatch {
+		end := start + safeBrowsingBatch
+		if end > len(urls) {
+			end = len(urls)
+		}
+
+		res, err := safeBrowsing.find(ctx, urls[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		verdicts = append(verdicts, res...)
+	}
+
+	return verdicts, nil
+}
+
+func (safeBrowsing *SafeBrowsing) find(ctx context.Context, urls []string) ([]Verdict, error) {
+	var request findRequest
+	request.Client.ClientID = safeBrowsing.clientID
+	request.Client.ClientVersion = "1.0"
+	request.ThreatInfo.ThreatTypes = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"}
+	request.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+	request.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+	request.ThreatInfo.ThreatEntries = make([]threatEntry, len(urls))
+	for i, u := range urls {
+		request.ThreatInfo.ThreatEntries[i] = threatEntry{URL: u}
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := safeBrowsing.endpoint + "/v4/threatMatches:find?key=" + url.QueryEscape(safeBrowsing.key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := safeBrowsing.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSafeBrowsing, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrSafeBrowsing, resp.StatusCode)
+	}
+
+	var response findResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSafeBrowsing, err)
+	}
+
+	threats := make(map[string]string, len(response.Matches))
+	for _, match := range response.Matches {
+		if _, ok := threats[match.Threat.URL]; !ok {
+			threats[match.Threat.URL] = match.ThreatType
+		}
+	}
+
+	verdicts := make([]Verdict, len(urls))
+	for i, u := range urls {
+		if threat, ok := threats[u]; ok {
+			verdicts[i] = Verdict{Threat: threat, Source: "safebrowsing"}
+		}
+	}
+
+	return verdicts, nil
+}
@@ -0,0 +1,87 @@
+package screening
+
+import (
+	"context"
+	"github.com/goware/urlx"
+	"strings"
+)
+
+// Verdict is the outcome of screening a single URL. An empty Threat means
+// nothing was found.
+type Verdict struct {
+	Threat string `json:"threat"`
+	Source string `json:"source"`
+}
+
+func (verdict Verdict) Flagged() bool {
+	return verdict.Threat != ""
+}
+
+// Screener checks URLs against a source of known bad destinations. The
+// returned verdicts are in the same order as urls.
+type Screener interface {
+	Screen(ctx context.Context, urls []string) ([]Verdict, error)
+}
+
+// Reloader is implemented by screeners backed by data that can change while
+// the application runs.
+type Reloader interface {
+	Reload() error
+}
+
+type chain []Screener
+
+// Chain runs every screener and keeps the first flagged verdict for each URL.
+// Nil screeners are skipped.
+func Chain(screeners ...Screener) Screener {
+	var c chain
+	for _, screener := range screeners {
+		if screener != nil {
+			c = append(c, screener)
+		}
+	}
+
+	return c
+}
+
+func (c chain) Screen(ctx context.Context, urls []string) ([]Verdict, error) {
+	verdicts := make([]Verdict, len(urls))
+
+	for _, screener := range c {
+		res, err := screener.Screen(ctx, urls)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, verdict := range res {
+			if !verdicts[i].Flagged() {
+				verdicts[i] = verdict
+			}
+		}
+	}
+
+	return verdicts, nil
+}
+
+// Host returns the lower-cased host name of rawURL, or an empty string if it
+// cannot be parsed.
+func Host(rawURL string) string {
+	u, err := urlx.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+func (c chain) Reload() error {
+	for _, screener := range c {
+		if reloader, ok := screener.(Reloader); ok {
+			if err := reloader.Reload(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package screening_test
+
+import (
+	"cc/pkg/screening"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# phishing\nEvil.example\n\nbad.test\n"), 0o600))
+
+	blocklist, err := screening.LoadBlocklist(path)
+	require.NoError(t, err)
+
+	verdicts, err := blocklist.Screen(context.Background(), []string{
+		"https://evil.example/login",
+		"http://www.evil.example",
+		"https://notevil.example",
+		"bad.test/path",
+		"https://example.com",
+	})
+	require.NoError(t, err)
+
+	flagged := make([]bool, len(verdicts))
+	for i, verdict := range verdicts {
+		flagged[i] = verdict.Flagged()
+	}
+	assert.Equal(t, []bool{true, true, false, true, false}, flagged)
+
+	require.NoError(t, os.WriteFile(path, []byte("example.com\n"), 0o600))
+	require.NoError(t, blocklist.Reload())
+
+	verdicts, err = blocklist.Screen(context.Background(), []string{"https://evil.example", "https://example.com"})
+	require.NoError(t, err)
+	assert.False(t, verdicts[0].Flagged())
+	assert.True(t, verdicts[1].Flagged())
+}
+
+func TestSafeBrowsing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v4/threatMatches:find", r.URL.Path)
+		assert.Equal(t, "key", r.URL.Query().Get("key"))
+
+		var request struct {
+			ThreatInfo struct {
+				ThreatEntries []struct {
+					URL string `json:"url"`
+				} `json:"threatEntries"`
+			} `json:"threatInfo"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		type match struct {
+			ThreatType string            `json:"threatType"`
+			Threat     map[string]string `json:"threat"`
+		}
+
+		var matches []match
+		for _, entry := range request.ThreatInfo.ThreatEntries {
+			if entry.URL == "https://malware.test" {
+				matches = append(matches, match{ThreatType: "MALWARE", Threat: map[string]string{"url": entry.URL}})
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"matches": matches})
+	}))
+	defer server.Close()
+
+	safeBrowsing := screening.NewSafeBrowsing(server.URL, "key", "cc", server.Client())
+
+	verdicts, err := screening.Chain(screening.NewBlocklist("evil.example"), safeBrowsing).Screen(context.Background(), []string{
+		"https://example.com",
+		"https://malware.test",
+		"https://evil.example",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []screening.Verdict{
+		{},
+		{Threat: "MALWARE", Source: "safebrowsing"},
+		{Threat: "BLOCKLISTED", Source: "blocklist"},
+	}, verdicts)
+}
+
+func TestSafeBrowsingError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err := screening.NewSafeBrowsing(server.URL, "key", "cc", server.Client()).Screen(context.Background(), []string{"https://example.com"})
+	assert.ErrorIs(t, err, screening.ErrSafeBrowsing)
+}
@@ -0,0 +1,59 @@
+package totp_test
+
+import (
+	"cc/pkg/totp"
+	"encoding/base32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B, SHA-1 key, truncated to six digits.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, test := range tests {
+		code, err := totp.Code(rfcSecret, totp.Counter(time.Unix(test.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, test.code, code, test.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	counter, ok := totp.Validate(rfcSecret, "081804", now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Counter(now), counter)
+
+	_, ok = totp.Validate(rfcSecret, "081804", now.Add(totp.Period*time.Second), 1)
+	assert.True(t, ok, "previous step is accepted")
+
+	_, ok = totp.Validate(rfcSecret, "081804", now.Add(3*totp.Period*time.Second), 1)
+	assert.False(t, ok, "old step is rejected")
+
+	_, ok = totp.Validate(rfcSecret, "000000", now, 1)
+	assert.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	uri := totp.URI("cc", "alice", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/cc:alice?"))
+	assert.Contains(t, uri, "secret="+secret)
+}